@@ -0,0 +1,154 @@
+package cmd
+
+//spellchecker:words encoding json time github wisski distillery internal component audit models cobra pflag pkglib exit
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/cli"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.tkw01536.de/pkglib/exit"
+)
+
+func NewAuditCommand() *cobra.Command {
+	impl := new(auditCmd)
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "shows the audit log of administrative actions",
+		Long:  "shows the audit log of administrative actions, newest first. Actions are performed from the web interface, the api, or the command line.",
+		Args:  cobra.NoArgs,
+		RunE:  impl.Exec,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&impl.Filter.User, "user", "", "only show actions performed by `USER`")
+	flags.StringVar(&impl.Filter.Action, "action", "", "only show `ACTION`, or all actions starting with it if it ends with a '.'")
+	flags.StringVar(&impl.Filter.Slug, "slug", "", "only show actions targeting the instance `SLUG`")
+	flags.DurationVar(&impl.Since, "since", 0, "only show actions performed within `DURATION`")
+	flags.IntVar(&impl.Filter.Limit, "limit", audit.DefaultLimit, "show at most `N` entries")
+	flags.BoolVar(&impl.Filter.Failed, "failed", false, "only show failed actions")
+	flags.BoolVar(&impl.JSON, "json", false, "print entries as JSON")
+
+	return cmd
+}
+
+type auditCmd struct {
+	Filter audit.Filter
+	Since  time.Duration
+	JSON   bool
+}
+
+var errAuditFailed = exit.NewErrorWithCode("failed to read audit log", cli.ExitGeneric)
+
+func (ac *auditCmd) Exec(cmd *cobra.Command, args []string) error {
+	dis, err := cli.GetDistillery(cmd, cli.Requirements{
+		NeedsDistillery: true,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", errAuditFailed, err)
+	}
+
+	if ac.Since > 0 {
+		ac.Filter.Since = time.Now().Add(-ac.Since)
+	}
+
+	entries, err := dis.Audit().Entries(cmd.Context(), ac.Filter)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errAuditFailed, err)
+	}
+
+	if ac.JSON {
+		if err := json.NewEncoder(cmd.OutOrStdout()).Encode(entries); err != nil {
+			return fmt.Errorf("%w: %w", errAuditFailed, err)
+		}
+		return nil
+	}
+
+	for _, entry := range entries {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), formatAuditEntry(entry))
+	}
+	return nil
+}
+
+// formatAuditEntry formats an entry of the audit log as a single line.
+func formatAuditEntry(entry models.AuditEntry) string {
+	actor := entry.Origin
+	if entry.User != "" {
+		actor += ":" + entry.User
+	}
+	if entry.Token != "" {
+		actor += " (token " + entry.Token + ")"
+	}
+
+	outcome := "ok"
+	if !entry.Success {
+		outcome = "failed: " + entry.Error
+	}
+
+	target := ""
+	if entry.Slug != "" {
+		target = " [" + entry.Slug + "]"
+	}
+
+	return fmt.Sprintf("%s %s %s%s %s %s", entry.Time.Format(time.RFC3339), actor, entry.Action, target, entry.Params, outcome)
+}
+
+// unrecordedCommands are commands that are not recorded in the audit log.
+// They either do not change any state, or run regularly on their own.
+var unrecordedCommands = map[string]struct{}{
+	"audit":        {},
+	"config":       {},
+	"cron":         {},
+	"info":         {},
+	"instance_log": {},
+	"license":      {},
+	"ls":           {},
+	"pathbuilders": {},
+	"prefixes":     {},
+	"server":       {},
+	"ssh":          {},
+	"status":       {},
+	"verify":       {},
+}
+
+// wrapRecord wraps a RunE function to record the command in the audit log.
+// If the first argument is the slug of an instance, it is recorded as the target of the command.
+//
+// Commands are only recorded when the distillery can be loaded.
+// If run is nil, it is returned as-is.
+func wrapRecord(run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	if run == nil {
+		return nil
+	}
+
+	return func(cmd *cobra.Command, args []string) (err error) {
+		err = run(cmd, args)
+
+		dis, derr := cli.GetDistillery(cmd, cli.Requirements{
+			NeedsDistillery: true,
+		})
+		if derr != nil {
+			return err
+		}
+
+		var slug string
+		if len(args) > 0 {
+			if ok, herr := dis.Instances().Has(cmd.Context(), args[0]); herr == nil && ok {
+				slug = args[0]
+			}
+		}
+
+		flags := make(map[string]string)
+		cmd.Flags().Visit(func(flag *pflag.Flag) {
+			flags[flag.Name] = flag.Value.String()
+		})
+
+		dis.Audit().Record(cmd.Context(), "cli."+cmd.Name(), slug, map[string]any{"args": args, "flags": flags}, err)
+		return err
+	}
+}
@@ -64,7 +64,7 @@ func (bk *backup) Exec(cmd *cobra.Command, args []string) error {
 	if bk.Prune {
 		defer func() {
 			err := logging.LogOperation(func() error {
-				return dis.Exporter().PruneExports(cmd.Context(), cmd.ErrOrStderr())
+				return dis.Exporter().PruneExports(cmd.Context(), cmd.ErrOrStderr(), false)
 			}, cmd.ErrOrStderr(), "Pruning old backups")
 			if err != nil {
 				wdlog.Of(cmd.Context()).Error("failed to prune backups", slog.Any("error", err))
@@ -14,15 +14,20 @@ func NewBackupsPruneCommand() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "backups_prune",
-		Short: "prunes old backup archives",
+		Short: "prunes old exports according to their retention policies",
 		Args:  cobra.NoArgs,
 		RunE:  impl.Exec,
 	}
 
+	flags := cmd.Flags()
+	flags.BoolVar(&impl.DryRun, "dry-run", false, "only list which exports would be pruned")
+
 	return cmd
 }
 
-type backupsPrune struct{}
+type backupsPrune struct {
+	DryRun bool
+}
 
 var errPruneFailed = exit.NewErrorWithCode("failed to prune backups", cli.ExitGeneric)
 
@@ -35,7 +40,7 @@ func (bp *backupsPrune) Exec(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("%w: %w", errPruneFailed, err)
 	}
 
-	if err := dis.Exporter().PruneExports(cmd.Context(), cmd.ErrOrStderr()); err != nil {
+	if err := dis.Exporter().PruneExports(cmd.Context(), cmd.ErrOrStderr(), bp.DryRun); err != nil {
 		return fmt.Errorf("%w: %w", errPruneFailed, err)
 	}
 	return nil
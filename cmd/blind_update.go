@@ -27,6 +27,7 @@ func NewBlindUpdateCommand() *cobra.Command {
 	flags := cmd.Flags()
 	flags.IntVar(&impl.Parallel, "parallel", 1, "run on (at most) this many instances in parallel. 0 for no limit")
 	flags.BoolVar(&impl.Force, "force", false, "force running blind-update even if 'AutoBlindUpdate' is set to false")
+	flags.BoolVar(&impl.Guarded, "guarded", false, "take a snapshot before updating, and roll back if the update or a subsequent check of the instance fails")
 
 	return cmd
 }
@@ -34,6 +35,7 @@ func NewBlindUpdateCommand() *cobra.Command {
 type blindUpdate struct {
 	Parallel    int
 	Force       bool
+	Guarded     bool
 	Positionals struct {
 		Slug []string
 	}
@@ -68,6 +70,9 @@ func (bu *blindUpdate) Exec(cmd *cobra.Command, args []string) error {
 
 	// and do the actual blind_update!
 	if err := status.WriterGroup(cmd.ErrOrStderr(), bu.Parallel, func(instance *wisski.WissKI, writer io.Writer) error {
+		if bu.Guarded {
+			return dis.Updater().Update(cmd.Context(), writer, instance)
+		}
 		return instance.Composer().Update(cmd.Context(), writer)
 	}, wissKIs, status.SmartMessage(func(item *wisski.WissKI) string {
 		return fmt.Sprintf("blind_update %q", item.Slug)
@@ -27,7 +27,8 @@ func NewDisGrantCommand() *cobra.Command {
 	flags.BoolVar(&impl.AddAll, "add-all", false, "add grant to all WissKIs")
 	flags.BoolVar(&impl.AddUser, "add", false, "add or update a user to a given wisski")
 	flags.BoolVar(&impl.RemoveUser, "remove", false, "remove a user from a given wisski")
-	flags.BoolVar(&impl.DrupalAdmin, "admin", false, "grant user the admin role")
+	flags.BoolVar(&impl.DrupalAdmin, "admin", false, "grant user the admin role, shorthand for \"--role admin\"")
+	flags.StringVar(&impl.Role, "role", string(models.RoleReviewer), "grant user `ROLE`, one of \"reviewer\", \"editor\", \"operator\" or \"admin\"")
 
 	return cmd
 }
@@ -37,6 +38,7 @@ type disGrant struct {
 	AddUser     bool
 	RemoveUser  bool
 	DrupalAdmin bool
+	Role        string
 	Positionals struct {
 		User       string
 		Slug       string
@@ -73,11 +75,30 @@ func (dg *disGrant) ParseArgs(cmd *cobra.Command, args []string) error {
 		return errNoSlugSelect
 	}
 
+	if dg.DrupalAdmin {
+		dg.Role = string(models.RoleAdmin)
+	}
+	if _, err := models.ParseRole(dg.Role); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidRole, err)
+	}
+
 	return nil
 }
 
+// grant returns the grant to set for the given slug.
+func (dg *disGrant) grant(slug string, drupalUser string) models.Grant {
+	grant := models.Grant{
+		User:           dg.Positionals.User,
+		Slug:           slug,
+		DrupalUsername: drupalUser,
+	}
+	grant.SetRole(models.Role(dg.Role))
+	return grant
+}
+
 var errNoSlugSelect = exit.NewErrorWithCode("slug not provided", cli.ExitCommandArguments)
 var errNoActionSelected = exit.NewErrorWithCode("no action selected", cli.ExitCommandArguments)
+var errInvalidRole = exit.NewErrorWithCode("invalid role", cli.ExitCommandArguments)
 var errFailedGrant = exit.NewErrorWithCode("unable to manage grants", cli.ExitGeneric)
 
 func (dg *disGrant) Exec(cmd *cobra.Command, args []string) error {
@@ -121,12 +142,7 @@ func (dg *disGrant) runAddUser(cmd *cobra.Command, dis *dis.Distillery) error {
 	}
 
 	policy := dis.Policy()
-	if err := policy.Set(cmd.Context(), models.Grant{
-		User:            dg.Positionals.User,
-		Slug:            dg.Positionals.Slug,
-		DrupalUsername:  dg.Positionals.DrupalUser,
-		DrupalAdminRole: dg.DrupalAdmin,
-	}); err != nil {
+	if err := policy.Set(cmd.Context(), dg.grant(dg.Positionals.Slug, dg.Positionals.DrupalUser)); err != nil {
 		return fmt.Errorf("failed to set policy: %w", err)
 	}
 	return nil
@@ -156,12 +172,7 @@ func (dg *disGrant) runAddAll(cmd *cobra.Command, dis *dis.Distillery) error {
 		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Adding grant for user %s to %s\n", dg.Positionals.User, instance.Slug); err != nil {
 			return fmt.Errorf("failed to write text: %w", err)
 		}
-		if err := policy.Set(cmd.Context(), models.Grant{
-			User:            dg.Positionals.User,
-			Slug:            instance.Slug,
-			DrupalUsername:  dg.Positionals.User,
-			DrupalAdminRole: dg.DrupalAdmin,
-		}); err != nil {
+		if err := policy.Set(cmd.Context(), dg.grant(instance.Slug, dg.Positionals.User)); err != nil {
 			return fmt.Errorf("failed to add grant for instance %q to user: %w", instance.Slug, err)
 		}
 	}
@@ -1,11 +1,13 @@
 package cmd
 
-//spellchecker:words github wisski distillery internal cobra
+//spellchecker:words github wisski distillery internal component triplestore client cobra pkglib exit
 import (
 	"fmt"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/cli"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/triplestore/client"
 	"github.com/spf13/cobra"
+	"go.tkw01536.de/pkglib/exit"
 )
 
 func NewExportTSCommand() *cobra.Command {
@@ -14,28 +16,57 @@ func NewExportTSCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "export_ts SLUG",
 		Short:   "export the triplestore for a specific instance",
+		Long:    "export the triplestore for a specific instance to standard output. By default all statements are exported as n-quads.",
 		Args:    cobra.ExactArgs(1),
 		PreRunE: impl.ParseArgs,
 		RunE:    impl.Exec,
 	}
 
+	flags := cmd.Flags()
+	flags.StringVar(&impl.Format, "format", client.NQuads.Name, "format to export in, use --list-formats to list all formats")
+	flags.StringArrayVar(&impl.Graphs, "graph", nil, "only export the named graph with this IRI. may be given multiple times")
+	flags.BoolVar(&impl.ListFormats, "list-formats", false, "list available formats")
+	flags.BoolVar(&impl.ListGraphs, "list-graphs", false, "list the named graphs of the instance")
+
 	return cmd
 }
 
 type exportTS struct {
+	Format      string
+	Graphs      []string
+	ListFormats bool
+	ListGraphs  bool
+
 	Positionals struct {
 		Slug string
 	}
+
+	format client.Format
 }
 
+var errExportTSFormat = exit.NewErrorWithCode("invalid format", cli.ExitCommandArguments)
+
 func (ets *exportTS) ParseArgs(cmd *cobra.Command, args []string) error {
 	if len(args) >= 1 {
 		ets.Positionals.Slug = args[0]
 	}
+
+	var err error
+	ets.format, err = client.FormatByName(ets.Format)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errExportTSFormat, err)
+	}
 	return nil
 }
 
 func (ets *exportTS) Exec(cmd *cobra.Command, args []string) error {
+	if ets.ListFormats {
+		for _, format := range client.Formats {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", format.Name, format.ContentType)
+		}
+		return nil
+	}
+
 	dis, err := cli.GetDistillery(cmd, cli.Requirements{
 		NeedsDistillery: true,
 	})
@@ -48,8 +79,22 @@ func (ets *exportTS) Exec(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get WissKI: %w", err)
 	}
 
-	if err := instance.BoundTriplestore().SnapshotDB(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
-		return fmt.Errorf("failed to snapshot triplestore: %w", err)
+	if ets.ListGraphs {
+		graphs, err := instance.BoundTriplestore().Graphs(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to list graphs: %w", err)
+		}
+		for _, graph := range graphs {
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), graph)
+		}
+		return nil
+	}
+
+	if err := instance.BoundTriplestore().Export(cmd.Context(), cmd.OutOrStdout(), client.ExportOptions{
+		Format: ets.format,
+		Graphs: ets.Graphs,
+	}); err != nil {
+		return fmt.Errorf("failed to export triplestore: %w", err)
 	}
 	return nil
 }
@@ -111,6 +111,9 @@ func (i *info) exec(cmd *cobra.Command, dis *dis.Distillery) (err error) {
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Locked:               %v\n", info.Locked)
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Last Rebuild:         %v\n", info.LastRebuild.String())
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Last Update:          %v\n", info.LastUpdate.String())
+	if !info.GuardedUpdate.IsZero() {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Last Guarded Update:  %v (%s)\n", info.GuardedUpdate.Started.String(), info.GuardedUpdate.Result)
+	}
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Last Cron:            %v\n", info.LastCron.String())
 
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Drupal Version:       %v\n", info.DrupalVersion)
@@ -189,6 +192,22 @@ func (i *info) printReProvisionCommand(cmd *cobra.Command, instance *wisski.Wiss
 	if inst.IPAllowlist != "" {
 		args = append(args, "--ip-allowlist", inst.IPAllowlist)
 	}
+	if inst.Aliases != "" {
+		args = append(args, "--aliases", inst.Aliases)
+	}
+	for _, setting := range [][2]string{
+		{"--cpu-limit", inst.CPULimit},
+		{"--memory-limit", inst.MemoryLimit},
+		{"--php-memory-limit", inst.PHPMemoryLimit},
+		{"--service-cpu-limit", inst.ServiceCPULimit},
+		{"--service-memory-limit", inst.ServiceMemoryLimit},
+		{"--triplestore-heap", inst.TriplestoreHeap},
+		{"--solr-heap", inst.SolrHeap},
+	} {
+		if setting[1] != "" {
+			args = append(args, setting[0], setting[1])
+		}
+	}
 	if inst.DedicatedSQL {
 		args = append(args, "--dedicated-sql")
 	}
@@ -1,8 +1,9 @@
 package cmd
 
-//spellchecker:words github wisski distillery internal cobra pkglib exit
+//spellchecker:words time github wisski distillery internal cobra pkglib exit
 import (
 	"fmt"
+	"time"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/cli"
 	"github.com/spf13/cobra"
@@ -23,6 +24,8 @@ func NewInstanceLockCommand() *cobra.Command {
 	flags := cmd.Flags()
 	flags.BoolVar(&impl.Lock, "lock", false, "lock the provided instance")
 	flags.BoolVar(&impl.Unlock, "unlock", false, "unlock the provided instance")
+	flags.StringVar(&impl.Reason, "reason", "The instance is locked for maintenance.", "reason for locking, shown on the maintenance page")
+	flags.DurationVar(&impl.Estimate, "estimate", 0, "estimated duration of the lock, shown on the maintenance page")
 
 	return cmd
 }
@@ -30,6 +33,8 @@ func NewInstanceLockCommand() *cobra.Command {
 type instanceLock struct {
 	Lock        bool
 	Unlock      bool
+	Reason      string
+	Estimate    time.Duration
 	Positionals struct {
 		Slug string
 	}
@@ -70,7 +75,7 @@ func (l *instanceLock) Exec(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	if err := instance.Locker().TryLock(cmd.Context()); err != nil {
+	if err := instance.Locker().TryLock(cmd.Context(), l.Reason, l.Estimate); err != nil {
 		return fmt.Errorf("%w: %w", errLockFailed, err)
 	}
 
@@ -1,12 +1,11 @@
 package cmd
 
-//spellchecker:words github wisski distillery internal cobra pkglib errorsx exit
+//spellchecker:words github wisski distillery internal cobra pkglib exit
 import (
 	"fmt"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/cli"
 	"github.com/spf13/cobra"
-	"go.tkw01536.de/pkglib/errorsx"
 	"go.tkw01536.de/pkglib/exit"
 )
 
@@ -47,9 +46,8 @@ func (i *instancepause) ParseArgs(cmd *cobra.Command, args []string) error {
 
 var errStopStartExcluded = exit.NewErrorWithCode("stop and start are mutually exclusive", cli.ExitCommandArguments)
 var errInstancePauseWissKI = exit.NewErrorWithCode("unable to get WissKI", cli.ExitGeneric)
-var errInstancePauseStack = exit.NewErrorWithCode("unable to get stack", cli.ExitGeneric)
 
-func (i *instancepause) Exec(cmd *cobra.Command, args []string) (e error) {
+func (i *instancepause) Exec(cmd *cobra.Command, args []string) error {
 	dis, err := cli.GetDistillery(cmd, cli.Requirements{
 		NeedsDistillery: true,
 	})
@@ -63,18 +61,12 @@ func (i *instancepause) Exec(cmd *cobra.Command, args []string) (e error) {
 		return fmt.Errorf("%w: %w", errInstancePauseWissKI, err)
 	}
 
-	stack, err := instance.Barrel().OpenStack()
-	if err != nil {
-		return fmt.Errorf("%w: %w", errInstancePauseStack, err)
-	}
-	defer errorsx.Close(stack, &e, "stack")
-
 	if i.Stop {
-		if err := stack.Down(cmd.Context(), cmd.OutOrStdout()); err != nil {
+		if err := instance.Barrel().Stop(cmd.Context(), cmd.OutOrStdout()); err != nil {
 			return fmt.Errorf("failed to stop instance: %w", err)
 		}
 	} else {
-		if err := stack.Start(cmd.Context(), cmd.OutOrStdout()); err != nil {
+		if err := instance.Barrel().Start(cmd.Context(), cmd.OutOrStdout()); err != nil {
 			return fmt.Errorf("failed to start instance: %w", err)
 		}
 	}
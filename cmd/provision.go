@@ -1,6 +1,6 @@
 package cmd
 
-//spellchecker:words encoding json github wisski distillery internal component provision models ingredient barrel manager logging cobra pkglib exit
+//spellchecker:words encoding json github wisski distillery internal component provision models ingredient barrel manager logging cobra pflag pkglib exit
 import (
 	"encoding/json"
 	"fmt"
@@ -11,6 +11,7 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/barrel/manager"
 	"github.com/FAU-CDI/wisski-distillery/pkg/logging"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"go.tkw01536.de/pkglib/exit"
 )
 
@@ -35,13 +36,53 @@ func NewProvisionCommand() *cobra.Command {
 	flags.BoolVar(&impl.ListFlavors, "list-flavors", false, "List all known flavors")
 	flags.StringVar(&impl.ContentSecurityPolicy, "content-security-policy", "", "Setup ContentSecurityPolicy")
 	flags.StringVar(&impl.IPAllowlist, "ip-allowlist", "", "Setup comman-separated IP (or IP block) allowlist")
+	flags.StringVar(&impl.Aliases, "aliases", "", "Setup comma-separated custom domains to serve the instance under")
 	flags.BoolVar(&impl.DedicatedSQL, "dedicated-sql", false, "Use a dedicated SQL server for this instance")
 	flags.BoolVar(&impl.DedicatedTriplestore, "dedicated-triplestore", false, "Use a dedicated Triplestore for this instance")
 	flags.BoolVar(&impl.SolrServer, "solr-server", false, "Add a dedicated Solr server to this instance")
+	impl.Resources.register(flags)
 
 	return cmd
 }
 
+// resourceFlags are flags for the resource settings of an instance.
+// They are shared between provision and rebuild.
+type resourceFlags struct {
+	CPULimit           string
+	MemoryLimit        string
+	PHPMemoryLimit     string
+	ServiceCPULimit    string
+	ServiceMemoryLimit string
+	TriplestoreHeap    string
+	SolrHeap           string
+}
+
+func (rf *resourceFlags) register(flags *pflag.FlagSet) {
+	flags.StringVar(&rf.CPULimit, "cpu-limit", "", "Number of cpus available to the instance, e.g. '2.0'")
+	flags.StringVar(&rf.MemoryLimit, "memory-limit", "", "Memory available to the instance, e.g. '4g'")
+	flags.StringVar(&rf.PHPMemoryLimit, "php-memory-limit", "", "PHP memory_limit of the instance (default '"+models.DefaultPHPMemoryLimit+"')")
+	flags.StringVar(&rf.ServiceCPULimit, "service-cpu-limit", "", "Number of cpus available to each dedicated service (default '"+models.DefaultServiceCPULimit+"')")
+	flags.StringVar(&rf.ServiceMemoryLimit, "service-memory-limit", "", "Memory available to each dedicated service")
+	flags.StringVar(&rf.TriplestoreHeap, "triplestore-heap", "", "Maximum java heap of the dedicated triplestore (default '"+models.DefaultTriplestoreHeap+"')")
+	flags.StringVar(&rf.SolrHeap, "solr-heap", "", "Java heap of the solr server (default '"+models.DefaultSolrHeap+"')")
+}
+
+// set reports if any resource flag was set.
+func (rf resourceFlags) set() bool {
+	return rf != resourceFlags{}
+}
+
+// applyTo applies the resource flags to the given system.
+func (rf resourceFlags) applyTo(system *models.System) {
+	system.CPULimit = rf.CPULimit
+	system.MemoryLimit = rf.MemoryLimit
+	system.PHPMemoryLimit = rf.PHPMemoryLimit
+	system.ServiceCPULimit = rf.ServiceCPULimit
+	system.ServiceMemoryLimit = rf.ServiceMemoryLimit
+	system.TriplestoreHeap = rf.TriplestoreHeap
+	system.SolrHeap = rf.SolrHeap
+}
+
 type pv struct {
 	PHPVersion            string
 	ListPHPVersions       bool
@@ -50,10 +91,12 @@ type pv struct {
 	Flavor                string
 	ListFlavors           bool
 	IPAllowlist           string
+	Aliases               string
 	ContentSecurityPolicy string
 	DedicatedSQL          bool
 	DedicatedTriplestore  bool
 	SolrServer            bool
+	Resources             resourceFlags
 	Positionals           struct {
 		Slug string
 	}
@@ -87,19 +130,23 @@ func (p *pv) Exec(cmd *cobra.Command, args []string) error {
 		return p.listPHPVersions(cmd)
 	}
 
+	system := models.System{
+		PHP:                   p.PHPVersion,
+		IIPServer:             p.IIPServer,
+		PHPDevelopment:        p.PHPDevelopment,
+		ContentSecurityPolicy: p.ContentSecurityPolicy,
+		IPAllowlist:           p.IPAllowlist,
+		Aliases:               p.Aliases,
+		DedicatedSQL:          p.DedicatedSQL,
+		DedicatedTriplestore:  p.DedicatedTriplestore,
+		SolrServer:            p.SolrServer,
+	}
+	p.Resources.applyTo(&system)
+
 	instance, err := dis.Provision().Provision(cmd.ErrOrStderr(), cmd.Context(), provision.Flags{
 		Slug:   p.Positionals.Slug,
 		Flavor: p.Flavor,
-		System: models.System{
-			PHP:                   p.PHPVersion,
-			IIPServer:             p.IIPServer,
-			PHPDevelopment:        p.PHPDevelopment,
-			ContentSecurityPolicy: p.ContentSecurityPolicy,
-			IPAllowlist:           p.IPAllowlist,
-			DedicatedSQL:          p.DedicatedSQL,
-			DedicatedTriplestore:  p.DedicatedTriplestore,
-			SolrServer:            p.SolrServer,
-		},
+		System: system,
 	})
 	if err != nil {
 		return fmt.Errorf("%q: %w: %w", p.Positionals.Slug, errProvisionGeneric, err)
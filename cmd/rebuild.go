@@ -33,6 +33,8 @@ func NewRebuildCommand() *cobra.Command {
 	flags.StringVar(&impl.Flavor, "flavor", "", "Use specific flavor. Use 'provision --list-flavors' to list flavors.")
 	flags.StringVar(&impl.ContentSecurityPolicy, "content-security-policy", "", "Setup ContentSecurityPolicy")
 	flags.StringVar(&impl.IPAllowlist, "ip-allowlist", "", "Setup comman-separated IP (or IP block) allowlist")
+	flags.StringVar(&impl.Aliases, "aliases", "", "Setup comma-separated custom domains to serve the instance under")
+	impl.Resources.register(flags)
 
 	return cmd
 }
@@ -47,6 +49,8 @@ type rebuild struct {
 	Flavor                string
 	ContentSecurityPolicy string
 	IPAllowlist           string
+	Aliases               string
+	Resources             resourceFlags
 
 	Positionals struct {
 		Slug []string
@@ -59,7 +63,7 @@ func (rb *rebuild) ParseArgs(cmd *cobra.Command, args []string) error {
 	if rb.System {
 		return nil
 	}
-	if rb.PHPVersion != "" || rb.PHPDevelopment || rb.ContentSecurityPolicy != "" {
+	if rb.PHPVersion != "" || rb.PHPDevelopment || rb.ContentSecurityPolicy != "" || rb.Aliases != "" || rb.Resources.set() {
 		return errRebuildNoSystem
 	}
 	return nil
@@ -93,6 +97,13 @@ func (rb *rebuild) Exec(cmd *cobra.Command, args []string) (err error) {
 				ContentSecurityPolicy: rb.ContentSecurityPolicy,
 				IPAllowlist:           rb.IPAllowlist,
 			}
+			rb.Resources.applyTo(&sys)
+
+			aliases, err := dis.Instances().ValidateAliases(cmd.Context(), instance.Slug, rb.Aliases)
+			if err != nil {
+				return fmt.Errorf("invalid custom domains: %w", err)
+			}
+			sys.Aliases = aliases
 		}
 
 		return instance.SystemManager().Apply(cmd.Context(), writer, sys)
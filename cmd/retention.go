@@ -0,0 +1,107 @@
+package cmd
+
+//spellchecker:words github wisski distillery internal models cobra pkglib exit
+import (
+	"fmt"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/cli"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/spf13/cobra"
+	"go.tkw01536.de/pkglib/exit"
+)
+
+func NewRetentionCommand() *cobra.Command {
+	impl := new(retention)
+
+	cmd := &cobra.Command{
+		Use:     "retention [SLUG]",
+		Short:   "shows or sets the retention policy for snapshots of an instance, or for backups when SLUG is omitted",
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: impl.ParseArgs,
+		RunE:    impl.Exec,
+	}
+
+	flags := cmd.Flags()
+	flags.IntVar(&impl.Policy.Daily, "daily", 0, "number of days to keep the most recent export of")
+	flags.IntVar(&impl.Policy.Weekly, "weekly", 0, "number of weeks to keep the most recent export of")
+	flags.IntVar(&impl.Policy.Monthly, "monthly", 0, "number of months to keep the most recent export of")
+	flags.BoolVar(&impl.Clear, "clear", false, "remove the retention policy, and fall back to the maximum backup age")
+
+	return cmd
+}
+
+type retention struct {
+	Policy      models.RetentionPolicy
+	Clear       bool
+	Positionals struct {
+		Slug string
+	}
+}
+
+var errRetentionNegative = exit.NewErrorWithCode("retention counts must not be negative", cli.ExitCommandArguments)
+
+func (r *retention) ParseArgs(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		r.Positionals.Slug = args[0]
+	}
+
+	if r.Policy.Daily < 0 || r.Policy.Weekly < 0 || r.Policy.Monthly < 0 {
+		return errRetentionNegative
+	}
+	return nil
+}
+
+var (
+	errRetentionNoInstance = exit.NewErrorWithCode("unable to get WissKI", cli.ExitGeneric)
+	errRetentionFailed     = exit.NewErrorWithCode("failed to update retention policy", cli.ExitGeneric)
+)
+
+func (r *retention) Exec(cmd *cobra.Command, args []string) error {
+	dis, err := cli.GetDistillery(cmd, cli.Requirements{
+		NeedsDistillery: true,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", errRetentionFailed, err)
+	}
+
+	// make sure that the instance exists
+	if r.Positionals.Slug != "" {
+		if _, err := dis.Instances().WissKI(cmd.Context(), r.Positionals.Slug); err != nil {
+			return fmt.Errorf("%w %q: %w", errRetentionNoInstance, r.Positionals.Slug, err)
+		}
+	}
+
+	// check which parts of the policy to update
+	flags := cmd.Flags()
+	update := r.Clear || flags.Changed("daily") || flags.Changed("weekly") || flags.Changed("monthly")
+
+	if update {
+		policy := models.RetentionPolicy{}
+		if !r.Clear {
+			policy, err = dis.Exporter().RetentionPolicy(cmd.Context(), r.Positionals.Slug)
+			if err != nil {
+				return fmt.Errorf("%w: %w", errRetentionFailed, err)
+			}
+			if flags.Changed("daily") {
+				policy.Daily = r.Policy.Daily
+			}
+			if flags.Changed("weekly") {
+				policy.Weekly = r.Policy.Weekly
+			}
+			if flags.Changed("monthly") {
+				policy.Monthly = r.Policy.Monthly
+			}
+		}
+
+		if err := dis.Exporter().SetRetentionPolicy(cmd.Context(), r.Positionals.Slug, policy); err != nil {
+			return fmt.Errorf("%w: %w", errRetentionFailed, err)
+		}
+	}
+
+	policy, err := dis.Exporter().RetentionPolicy(cmd.Context(), r.Positionals.Slug)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errRetentionFailed, err)
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), policy)
+	return nil
+}
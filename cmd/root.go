@@ -1,12 +1,14 @@
 package cmd
 
-//spellchecker:words context user github wisski distillery internal wdlog cobra pkglib exit stream
+//spellchecker:words context user github wisski distillery internal component audit wdlog cobra pkglib exit stream
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/user"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/cli"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
 	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
 	"github.com/spf13/cobra"
 	"go.tkw01536.de/pkglib/cgo"
@@ -42,6 +44,13 @@ func NewCommand(ctx context.Context, parameters cli.Params) *cobra.Command {
 				return errUserIsNotRoot
 			}
 
+			// record actions as performed by the user invoking sudo, if any
+			actor := audit.Actor{Origin: audit.OriginCLI, User: usr.Username}
+			if sudo := os.Getenv("SUDO_USER"); sudo != "" {
+				actor.User = sudo
+			}
+			cmd.SetContext(audit.WithActor(cmd.Context(), actor))
+
 			// warn about cgo!
 			if cgo.Enabled {
 				if _, err := fmt.Fprint(cmd.ErrOrStderr(), warnCGoEnabled); err != nil {
@@ -75,6 +84,7 @@ func NewCommand(ctx context.Context, parameters cli.Params) *cobra.Command {
 
 	cli.SetFlags(root, &flags)
 	cli.SetParameters(root, &parameters)
+	cli.PrepareLogging(root)
 
 	// add all the commands
 	root.AddCommand(
@@ -106,6 +116,7 @@ func NewCommand(ctx context.Context, parameters cli.Params) *cobra.Command {
 		// instance tasks
 		NewShellCommand(),
 		NewBlindUpdateCommand(),
+		NewUpdatesCommand(),
 		NewUpdatePrefixConfigCommand(), // TODO: Move into post-instance configuration
 
 		NewPathbuildersCommand(),
@@ -123,6 +134,9 @@ func NewCommand(ctx context.Context, parameters cli.Params) *cobra.Command {
 		NewBackupCommand(),
 		NewSnapshotRestoreCommand(),
 		NewBackupsPruneCommand(),
+		NewRetentionCommand(),
+		NewScheduleCommand(),
+		NewVerifyCommand(),
 		NewCronCommand(),
 		NewMondayCommand(),
 
@@ -139,6 +153,9 @@ func NewCommand(ctx context.Context, parameters cli.Params) *cobra.Command {
 
 		NewMakeBlockCommand(),
 
+		// audit log
+		NewAuditCommand(),
+
 		// self commands
 		NewLicenseCommand(),
 	)
@@ -153,6 +170,21 @@ func NewCommand(ctx context.Context, parameters cli.Params) *cobra.Command {
 	}
 	wrapAllArgs(root)
 
+	// record commands in the audit log
+	var wrapAllRun func(cmd *cobra.Command)
+	wrapAllRun = func(cmd *cobra.Command) {
+		cmd.RunE = wrapRecord(cmd.RunE)
+		for _, child := range cmd.Commands() {
+			wrapAllRun(child)
+		}
+	}
+	for _, child := range root.Commands() {
+		if _, ok := unrecordedCommands[child.Name()]; ok {
+			continue
+		}
+		wrapAllRun(child)
+	}
+
 	// setup more flags
 
 	return root
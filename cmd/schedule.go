@@ -0,0 +1,179 @@
+package cmd
+
+//spellchecker:words slices strings time github wisski distillery internal models cobra pkglib exit
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/cli"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/spf13/cobra"
+	"go.tkw01536.de/pkglib/exit"
+)
+
+func NewScheduleCommand() *cobra.Command {
+	impl := new(schedule)
+
+	cmd := &cobra.Command{
+		Use:     "schedule SLUG",
+		Short:   "shows or sets the schedule for automatic snapshots of an instance",
+		Long:    "shows or sets the schedule for automatic snapshots of an instance. Scheduled snapshots are taken by the distillery cron task.",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: impl.ParseArgs,
+		RunE:    impl.Exec,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&impl.Frequency, "frequency", "", "how often to take snapshots, either 'daily' or 'weekly'")
+	flags.IntVar(&impl.Schedule.Hour, "hour", 0, "hour of the day (in local time) to take snapshots at")
+	flags.StringVar(&impl.Weekday, "weekday", "", "day of the week to take weekly snapshots on, e.g. 'sunday'")
+	flags.StringSliceVar(&impl.Schedule.Parts, "parts", nil, "parts to include in scheduled snapshots. defaults to all parts, see `snapshot --list-parts`")
+	flags.BoolVar(&impl.Schedule.Incremental, "incremental", false, "take incremental snapshots")
+	flags.BoolVar(&impl.Clear, "clear", false, "remove the schedule")
+	flags.BoolVar(&impl.Now, "now", false, "immediately take a snapshot according to the schedule, and record the outcome")
+
+	return cmd
+}
+
+type schedule struct {
+	Schedule    models.SnapshotSchedule
+	Frequency   string
+	Weekday     string
+	Clear       bool
+	Now         bool
+	Positionals struct {
+		Slug string
+	}
+}
+
+var (
+	errScheduleFrequency = exit.NewErrorWithCode("frequency must be 'daily' or 'weekly'", cli.ExitCommandArguments)
+	errScheduleHour      = exit.NewErrorWithCode("hour must be between 0 and 23", cli.ExitCommandArguments)
+	errScheduleWeekday   = exit.NewErrorWithCode("unknown day of the week", cli.ExitCommandArguments)
+)
+
+func (s *schedule) ParseArgs(cmd *cobra.Command, args []string) error {
+	s.Positionals.Slug = args[0]
+
+	switch freq := models.ScheduleFrequency(s.Frequency); freq {
+	case models.ScheduleNever, models.ScheduleDaily, models.ScheduleWeekly:
+		s.Schedule.Frequency = freq
+	default:
+		return errScheduleFrequency
+	}
+
+	if s.Schedule.Hour < 0 || s.Schedule.Hour > 23 {
+		return errScheduleHour
+	}
+
+	if s.Weekday != "" {
+		day := slices.IndexFunc([]time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}, func(day time.Weekday) bool {
+			return strings.EqualFold(day.String(), s.Weekday)
+		})
+		if day < 0 {
+			return fmt.Errorf("%w: %q", errScheduleWeekday, s.Weekday)
+		}
+		s.Schedule.Weekday = time.Weekday(day)
+	}
+	return nil
+}
+
+var (
+	errScheduleNoInstance = exit.NewErrorWithCode("unable to get WissKI", cli.ExitGeneric)
+	errScheduleFailed     = exit.NewErrorWithCode("failed to update snapshot schedule", cli.ExitGeneric)
+	errScheduleUnknown    = exit.NewErrorWithCode("unknown snapshot part", cli.ExitCommandArguments)
+	errScheduleNotSet     = exit.NewErrorWithCode("instance does not have a snapshot schedule", cli.ExitGeneric)
+	errScheduleSnapshot   = exit.NewErrorWithCode("scheduled snapshot failed", cli.ExitGeneric)
+)
+
+func (s *schedule) Exec(cmd *cobra.Command, args []string) error {
+	dis, err := cli.GetDistillery(cmd, cli.Requirements{
+		NeedsDistillery: true,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", errScheduleFailed, err)
+	}
+
+	instance, err := dis.Instances().WissKI(cmd.Context(), s.Positionals.Slug)
+	if err != nil {
+		return fmt.Errorf("%w %q: %w", errScheduleNoInstance, s.Positionals.Slug, err)
+	}
+
+	for _, part := range s.Schedule.Parts {
+		if !slices.Contains(dis.Exporter().Parts(), part) {
+			return fmt.Errorf("%w: %q", errScheduleUnknown, part)
+		}
+	}
+
+	// check which parts of the schedule to update
+	flags := cmd.Flags()
+	changed := slices.ContainsFunc([]string{"frequency", "hour", "weekday", "parts", "incremental"}, flags.Changed)
+
+	if s.Clear || changed {
+		value := models.SnapshotSchedule{}
+		if !s.Clear {
+			value, err = instance.Schedule().Get(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("%w: %w", errScheduleFailed, err)
+			}
+			if flags.Changed("frequency") {
+				value.Frequency = s.Schedule.Frequency
+			}
+			if flags.Changed("hour") {
+				value.Hour = s.Schedule.Hour
+			}
+			if flags.Changed("weekday") {
+				value.Weekday = s.Schedule.Weekday
+			}
+			if flags.Changed("parts") {
+				value.Parts = s.Schedule.Parts
+			}
+			if flags.Changed("incremental") {
+				value.Incremental = s.Schedule.Incremental
+			}
+			if value.IsZero() {
+				return errScheduleFrequency
+			}
+		}
+
+		if err := instance.Schedule().Set(cmd.Context(), value); err != nil {
+			return fmt.Errorf("%w: %w", errScheduleFailed, err)
+		}
+	}
+
+	value, err := instance.Schedule().Get(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("%w: %w", errScheduleFailed, err)
+	}
+
+	if s.Now {
+		if value.IsZero() {
+			return errScheduleNotSet
+		}
+		if err := dis.Scheduler().Run(cmd.Context(), cmd.ErrOrStderr(), instance, value); err != nil {
+			return fmt.Errorf("%w: %w", errScheduleSnapshot, err)
+		}
+	}
+
+	state, err := instance.Schedule().State(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("%w: %w", errScheduleFailed, err)
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Schedule:     %s\n", value)
+	if !value.IsZero() {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Next:         %s\n", value.Next(time.Now()).Format(time.RFC3339))
+	}
+	if !state.LastRun.IsZero() {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Last Run:     %s\n", state.LastRun.Format(time.RFC3339))
+	}
+	if !state.LastSuccess.IsZero() {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Last Success: %s\n", state.LastSuccess.Format(time.RFC3339))
+	}
+	if state.Failed() {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Last Error:   %s\n", state.Error)
+	}
+	return nil
+}
@@ -63,6 +63,12 @@ func (s *server) Exec(cmd *cobra.Command, args []string) error {
 		notify, cancel := dis.Cron().Listen(cmd.Context())
 		defer cancel()
 
+		// start taking scheduled snapshots, which cron triggers
+		scheduled := dis.Scheduler().Start(cmd.Context())
+		defer func() {
+			<-scheduled
+		}()
+
 		// start the cron tasks
 		done := dis.Cron().Start(cmd.Context(), notify)
 		defer func() {
@@ -24,6 +24,7 @@ func NewSnapshotCommand() *cobra.Command {
 	flags := cmd.Flags()
 	flags.BoolVar(&impl.Keepalive, "keepalive", false, "keep instance running while taking a backup. might lead to inconsistent state")
 	flags.BoolVar(&impl.StagingOnly, "staging-only", false, "do not package into a snapshot archive, but only create a staging directory")
+	flags.BoolVar(&impl.Incremental, "incremental", false, "store snapshot incrementally, only storing files changed since the previous incremental snapshot")
 	flags.StringSliceVar(&impl.Parts, "parts", nil, "parts to include in snapshots. defaults to all parts, use l to list all available parts")
 	flags.BoolVar(&impl.List, "list-parts", false, "list available parts")
 
@@ -33,6 +34,7 @@ func NewSnapshotCommand() *cobra.Command {
 type snapshot struct {
 	Keepalive   bool
 	StagingOnly bool
+	Incremental bool
 	Parts       []string
 	List        bool
 	Positionals struct {
@@ -81,6 +83,7 @@ func (sn *snapshot) Exec(cmd *cobra.Command, args []string) error {
 	err = dis.Exporter().MakeExport(cmd.Context(), cmd.ErrOrStderr(), exporter.ExportTask{
 		Dest:        sn.Positionals.Dest,
 		StagingOnly: sn.StagingOnly,
+		Incremental: sn.Incremental,
 
 		SnapshotDescription: exporter.SnapshotDescription{
 			Parts: sn.Parts,
@@ -1,40 +1,33 @@
 package cmd
 
-//spellchecker:words bufio encoding json path filepath strings github wisski distillery internal component exporter cobra pkglib exit
+//spellchecker:words bufio strconv strings filippo github wisski distillery internal component exporter instances restorer models cobra pkglib errorsx exit
 import (
 	"bufio"
-	"cmp"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"io/fs"
-	"os"
-	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"filippo.io/age"
 	"github.com/FAU-CDI/wisski-distillery/internal/cli"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances/restorer"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
-	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient"
 	"github.com/FAU-CDI/wisski-distillery/pkg/logging"
 	"github.com/spf13/cobra"
 	"go.tkw01536.de/pkglib/errorsx"
 	"go.tkw01536.de/pkglib/exit"
-	"go.tkw01536.de/pkglib/fsx"
-	"go.tkw01536.de/pkglib/status"
-	"go.tkw01536.de/pkglib/stream"
 )
 
 func NewSnapshotRestoreCommand() *cobra.Command {
 	impl := new(snapshotRestore)
 
 	cmd := &cobra.Command{
-		Use:     "snapshot_restore DIRECTORY SLUG",
-		Short:   "restores an instance from a snapshot directory",
+		Use:     "snapshot_restore SOURCE SLUG",
+		Short:   "restores an instance from a snapshot directory, archive or export log entry",
 		Args:    cobra.ExactArgs(2),
 		PreRunE: impl.ParseArgs,
 		RunE:    impl.Exec,
@@ -42,20 +35,26 @@ func NewSnapshotRestoreCommand() *cobra.Command {
 
 	flags := cmd.Flags()
 	flags.BoolVar(&impl.Yes, "yes", false, "do not ask for confirmation")
+	flags.BoolVar(&impl.FromLog, "log", false, "interpret SOURCE as the id of an entry in the export log, or 'latest' for the newest snapshot of SLUG")
+	flags.BoolVar(&impl.New, "new", false, "provision a new instance SLUG from the snapshot instead of restoring into an existing instance")
+	flags.StringVar(&impl.Identity, "identity", "", "read an additional age identity to decrypt encrypted archives from `FILE`")
 
 	return cmd
 }
 
 type snapshotRestore struct {
 	Yes         bool
+	FromLog     bool
+	New         bool
+	Identity    string
 	Positionals struct {
-		Slug      string // instance to restore to
-		Directory string // path to the snapshot directory
+		Slug   string // instance to restore to
+		Source string // path to the snapshot directory or archive, or export log id
 	}
 }
 
 func (sr *snapshotRestore) ParseArgs(cmd *cobra.Command, args []string) error {
-	sr.Positionals.Directory = args[0]
+	sr.Positionals.Source = args[0]
 	sr.Positionals.Slug = args[1]
 	return nil
 }
@@ -63,6 +62,7 @@ func (sr *snapshotRestore) ParseArgs(cmd *cobra.Command, args []string) error {
 var (
 	errSnapshotRestoreNoConfirmation = exit.NewErrorWithCode("aborting after request was not confirmed. either type `yes` or pass `--yes` on the command line", cli.ExitGeneric)
 	errSnapshotRestoreFailed         = exit.NewErrorWithCode("failed to restore snapshot", cli.ExitGeneric)
+	errSnapshotRestoreExists         = exit.NewErrorWithCode("instance to create already exists", cli.ExitGeneric)
 )
 
 func (sr *snapshotRestore) Exec(cmd *cobra.Command, args []string) error {
@@ -79,37 +79,65 @@ func (sr *snapshotRestore) Exec(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func (sr *snapshotRestore) exec(cmd *cobra.Command, dis *dis.Distillery) error {
+func (sr *snapshotRestore) exec(cmd *cobra.Command, dis *dis.Distillery) (e error) {
 	if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Loading instance to restore to"); err != nil {
 		return fmt.Errorf("failed to log message: %w", err)
 	}
 
 	// Get information about the instance to restore to.
-	instance, err := dis.Instances().WissKI(cmd.Context(), sr.Positionals.Slug)
-	if err != nil {
-		return fmt.Errorf("instance to restore to does not exist: %w", err)
+	// When creating a new instance, it is provisioned only after confirmation.
+	var (
+		instance *wisski.WissKI
+		err      error
+	)
+	if sr.New {
+		exists, err := dis.Instances().Has(cmd.Context(), sr.Positionals.Slug)
+		if err != nil {
+			return fmt.Errorf("failed to check if instance exists: %w", err)
+		}
+		if exists {
+			return errSnapshotRestoreExists
+		}
+	} else {
+		instance, err = dis.Instances().WissKI(cmd.Context(), sr.Positionals.Slug)
+		if err != nil {
+			return fmt.Errorf("instance to restore to does not exist: %w", err)
+		}
 	}
 
 	if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Loading snapshot"); err != nil {
 		return fmt.Errorf("failed to log message: %w", err)
 	}
 
-	snapshot, err := loadArchive(sr.Positionals.Directory)
+	staged, err := sr.stage(cmd, dis)
 	if err != nil {
 		return fmt.Errorf("failed to load snapshot: %w", err)
 	}
+	defer func() {
+		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Cleaning up staged snapshot"); err != nil {
+			e = errorsx.Combine(e, fmt.Errorf("failed to log message: %w", err))
+		}
+		e = errorsx.Combine(e, staged.Close())
+	}()
+	snapshot := staged.Snapshot
 
-	checkResult, err := readArchiveParts(sr.Positionals.Directory, snapshot)
+	checkResult, err := restorer.ReadParts(staged)
 	if err != nil {
 		return fmt.Errorf("snapshot is not suitable for restoration: %w", err)
 	}
 	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "================================================\n"); err != nil {
 		return fmt.Errorf("failed to log message: %w", err)
 	}
-	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Instance: %s\n", instance.FilesystemBase); err != nil {
-		return fmt.Errorf("failed to log message: %w", err)
+	if sr.New {
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Instance: %s (new, cloned from %s)\n", sr.Positionals.Slug, snapshot.Instance.Slug); err != nil {
+			return fmt.Errorf("failed to log message: %w", err)
+		}
+	} else {
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Instance: %s\n", instance.FilesystemBase); err != nil {
+			return fmt.Errorf("failed to log message: %w", err)
+		}
 	}
-	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Snapshot: %s\n", sr.Positionals.Directory); err != nil {
+	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Snapshot: %s\n", sr.Positionals.Source); err != nil {
 		return fmt.Errorf("failed to log message: %w", err)
 	}
 	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "================================================\n"); err != nil {
@@ -130,7 +158,11 @@ func (sr *snapshotRestore) exec(cmd *cobra.Command, dis *dis.Distillery) error {
 
 	// check the confirmation from the user
 	if !sr.Yes {
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "About to restore instance %q from %q (taken at %s). This will overwrite existing data.\n", sr.Positionals.Slug, sr.Positionals.Directory, snapshot.StartTime.Format(time.RFC3339))
+		if sr.New {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "About to create instance %q from %q (taken at %s).\n", sr.Positionals.Slug, sr.Positionals.Source, snapshot.StartTime.Format(time.RFC3339))
+		} else {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "About to restore instance %q from %q (taken at %s). This will overwrite existing data.\n", sr.Positionals.Slug, sr.Positionals.Source, snapshot.StartTime.Format(time.RFC3339))
+		}
 		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Type 'yes' to continue: ")
 		reader := bufio.NewReader(cmd.InOrStdin())
 		line, err := reader.ReadString('\n')
@@ -139,563 +171,77 @@ func (sr *snapshotRestore) exec(cmd *cobra.Command, dis *dis.Distillery) error {
 		}
 	}
 
-	{
-		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Shutting down instance"); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		if err := shutdownInstance(cmd, instance); err != nil {
-			return fmt.Errorf("failed to shutdown instance: %w", err)
-		}
-	}
-
-	// Data
-	{
-		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Restoring data directory"); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		oldDataDirectory := filepath.Join(instance.FilesystemBase, "data")
-
-		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Old data directory: %s\n", oldDataDirectory); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "New data directory: %s\n", checkResult.DataPath); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		if err := checkResult.restoreDataDirectory(cmd, oldDataDirectory); err != nil {
-			return fmt.Errorf("failed to restore data directory: %w", err)
-		}
-
-		// TODO: chown the data directory to the www-data user
-
-		if _, err := fmt.Fprintln(cmd.OutOrStdout(), "Data directory restored."); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-	}
-
-	// Triplestore
-	{
-		if err := logging.LogOperation(func() error {
-			return checkResult.restoreTriplestore(cmd, instance)
-		}, cmd.ErrOrStderr(), "Restoring triplestore"); err != nil {
-			return fmt.Errorf("failed to restore triplestore: %w", err)
-		}
-
-		if _, err := fmt.Fprintln(cmd.OutOrStdout(), "Triplestore restored."); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-	}
-
-	// SQL
-	{
-		if err := logging.LogOperation(func() error {
-			return checkResult.restoreSQL(cmd, instance)
-		}, cmd.ErrOrStderr(), "Restoring SQL"); err != nil {
-			return fmt.Errorf("failed to restore SQL database: %w", err)
-		}
-
-		if _, err := fmt.Fprintln(cmd.OutOrStdout(), "SQL restored."); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-	}
-
-	// Restart instance
-	// TODO: Restart in dummy mode!
-	{
-		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Re-Starting instance"); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		if err := startInstance(cmd, instance); err != nil {
-			return fmt.Errorf("failed to restart instance: %w", err)
-		}
-	}
-
-	// Re-set permissions
-	{
-		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Re-setting permissions and ownership"); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		barrel := instance.Barrel()
-		for _, script := range [][]string{
-			{"chown", "-R", "www-data:www-data", "/var/www/data/project/"},
-		} {
-			if err := barrel.BashScriptAs(cmd.Context(), "root", stream.NonInteractive(cmd.OutOrStdout()), script...); err != nil {
-				return fmt.Errorf("failed to reset permissions: %w", err)
-			}
-		}
-	}
-
-	// Re-create SQL config
-	{
-		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Re-Creating SQL config"); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		if err := checkResult.restoreSQLConfig(cmd, instance); err != nil {
-			return fmt.Errorf("failed to restart instance: %w", err)
-		}
-	}
-
-	// Restart instance
-	{
-		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Re-Starting instance"); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		if err := startInstance(cmd, instance); err != nil {
-			return fmt.Errorf("failed to restart instance: %w", err)
-		}
-	}
-
-	// Re-create Adapter
-	{
-		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Re-Creating Adapter"); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		if _, err := instance.Adapters().SetAdapter(cmd.Context(), nil, instance.Adapters().DefaultAdapter()); err != nil {
-			return fmt.Errorf("failed to restore adapter: %w", err)
-		}
-
-		if _, err := fmt.Fprintln(cmd.OutOrStdout(), "Adapter re-created."); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-	}
-
-	// Re-build settings
-	{
-		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Re-Applying settings"); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		if err := instance.SystemManager().Apply(cmd.Context(), cmd.OutOrStdout(), instance.System); err != nil {
-			return fmt.Errorf("failed to apply settings: %w", err)
-		}
-	}
-
-	// Wait for SQL
-	{
-		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Waiting for SQL"); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		if err := waitSQL(cmd, instance); err != nil {
-			return fmt.Errorf("failed to wait for SQL: %w", err)
-		}
-	}
-
-	// clear cache
-	{
-		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Clearing cache"); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		if err := instance.Drush().Exec(cmd.Context(), cmd.OutOrStdout(), "cr"); err != nil {
-			return fmt.Errorf("failed to run drush cr: %w", err)
-		}
-
-		if _, err := fmt.Fprintln(cmd.OutOrStdout(), "Cache cleared."); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-	}
-
-	// and do a final restart for good measure ...
-	{
-		if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Re-Starting instance"); err != nil {
-			return fmt.Errorf("failed to log message: %w", err)
-		}
-
-		if err := startInstance(cmd, instance); err != nil {
-			return fmt.Errorf("failed to restart instance: %w", err)
+	if sr.New {
+		if _, err := dis.Restorer().Clone(cmd.Context(), cmd.ErrOrStderr(), sr.Positionals.Slug, staged); err != nil {
+			return fmt.Errorf("failed to restore into new instance: %w", err)
 		}
+		return nil
 	}
 
-	if _, err := logging.LogMessage(cmd.ErrOrStderr(), "Instance should be restored."); err != nil {
-		return fmt.Errorf("failed to log message: %w", err)
+	if err := dis.Restorer().Restore(cmd.Context(), cmd.ErrOrStderr(), instance, staged); err != nil {
+		return fmt.Errorf("failed to restore instance: %w", err)
 	}
 	return nil
 }
 
 var (
-	errFailedToCheckDirectory = errors.New("failed to check if path is a directory")
-	errPathNotDirectory       = errors.New("path is not a directory")
-	errFailedToOpenReport     = errors.New("failed to open snapshot report")
-	errFailedToDecodeReport   = errors.New("failed to decode snapshot report")
-
-	errSnapshotMissingDataPart = errors.New("data part is not present in the snapshot")
-
-	errDataFolderNotDirectory        = errors.New("data folder is not a directory")
-	errSQLDataNotFoundInSnapshot     = errors.New("sql data not found in snapshot")
-	errTriplestoreDataNotRegularFile = errors.New("triplestore data not a regular file")
-
-	errFailedToOpenStack                    = errors.New("failed to open stack")
-	errFailedToCreateTemporaryDirectory     = errors.New("failed to create temporary directory")
-	errFailedToComputeRelativePath          = errors.New("failed to compute relative path")
-	errFailedToGetDirectoryInfo             = errors.New("failed to get directory info")
-	errFailedToCreateDirectory              = errors.New("failed to create directory")
-	errFailedToOpenFile                     = errors.New("failed to open file")
-	errFailedToCopyFile                     = errors.New("failed to copy file")
-	errFailedToCopyDirectory                = errors.New("failed to copy directory")
-	errFailedToRemoveOldDirectory           = errors.New("failed to remove old directory")
-	errFailedToMoveRestoredDirectoryInPlace = errors.New("failed to move restored directory into place")
-
-	errFailedToLogMessage                 = errors.New("failed to log message")
-	errFailedToPurgeTriplestoreData       = errors.New("failed to purge triplestore data")
-	errFailedToProvisionTriplestore       = errors.New("failed to provision triplestore")
-	errFailedToOpenTriplestoreBackup      = errors.New("failed to open triplestore backup")
-	errFailedToRestoreTriplestoreContents = errors.New("failed to restore triplestore contents")
-
-	errFailedToPurgeSQLDatabase     = errors.New("failed to purge SQL database")
-	errFailedToProvisionSQLDatabase = errors.New("failed to provision SQL database")
-	errFailedToOpenSQLBackup        = errors.New("failed to open SQL backup")
-	errFailedToRestoreSQLContents   = errors.New("failed to restore SQL contents")
-	errFailedToRestoreSQLConfig     = errors.New("failed to restore SQL config")
-
-	errFailedToLstat         = errors.New("failed to lstat")
-	errFailedToWalkDir       = errors.New("failed to walk dir")
-	errFailedToCreateSymlink = errors.New("failed to create symlink")
-
-	errFailedToShutdownInstance = errors.New("failed to shutdown instance")
-	errFailedToStartInstance    = errors.New("failed to start instance")
-	errFailedToWaitSQL          = errors.New("failed to wait for SQL")
-
-	errSnapshotMissingPart    = errors.New("snapshot missing part")
-	errDataNotFoundInSnapshot = errors.New("data not found in snapshot")
+	errExportLogLatestNew = exit.NewErrorWithCode("'latest' can not be used when creating a new instance", cli.ExitGeneric)
+	errExportLogInvalidID = exit.NewErrorWithCode("export log id must be a number or 'latest'", cli.ExitGeneric)
+	errExportLogNoLatest  = exit.NewErrorWithCode("no snapshot of the instance found in the export log", cli.ExitGeneric)
 )
 
-func loadArchive(path string) (s exporter.Snapshot, e error) {
-	isDirectory, err := fsx.IsDirectory(path, false)
-	if err != nil {
-		return exporter.Snapshot{}, fmt.Errorf("%w: %w", errFailedToCheckDirectory, err)
-	}
-	if !isDirectory {
-		return exporter.Snapshot{}, &fs.PathError{Op: "loadArchive", Path: path, Err: errPathNotDirectory}
-	}
-
-	reportPath := filepath.Join(path, exporter.ReportMachinePath)
-	reportFile, err := os.Open(reportPath) // #nosec G304 -- intended
-	if err != nil {
-		return exporter.Snapshot{}, fmt.Errorf("%w: %w", errFailedToOpenReport, &fs.PathError{Op: "open", Path: reportPath, Err: err})
-	}
-	defer errorsx.Close(reportFile, &e, "report file")
-
-	var snapshot exporter.Snapshot
-	if err := json.NewDecoder(reportFile).Decode(&snapshot); err != nil {
-		return exporter.Snapshot{}, fmt.Errorf("%w: %w", errFailedToDecodeReport, err)
-	}
-
-	return snapshot, nil
-}
-
-type archiveParts struct {
-	DataPath string
-
-	SQLFilePath string
-
-	TSFilePath string
-}
-
-func readArchiveParts(path string, archive exporter.Snapshot) (parts archiveParts, err error) {
-	{
-		if !slices.Contains(archive.Description.Parts, "data") {
-			return archiveParts{}, errSnapshotMissingDataPart
-		}
-
-		parts.DataPath = filepath.Join(path, "data", "data")
-		if isDirectory, err := fsx.IsDirectory(parts.DataPath, false); !isDirectory {
-			return archiveParts{}, fmt.Errorf("%w: %w", errDataFolderNotDirectory, cmp.Or(err, fs.ErrNotExist))
-		}
-	}
-
-	{
-		local, err := findPartPath(archive, "sql", "sql", "sql")
+// stage stages the snapshot to restore from.
+// The caller must close the returned snapshot.
+func (sr *snapshotRestore) stage(cmd *cobra.Command, dis *dis.Distillery) (*exporter.StagedSnapshot, error) {
+	var identities []age.Identity
+	if sr.Identity != "" {
+		var err error
+		identities, err = exporter.ReadIdentities(sr.Identity)
 		if err != nil {
-			return archiveParts{}, err
-		}
-
-		parts.SQLFilePath = filepath.Join(path, local)
-		if isFile, err := fsx.IsRegular(parts.SQLFilePath, false); !isFile {
-			return archiveParts{}, fmt.Errorf("%w: %s: %w", errSQLDataNotFoundInSnapshot, parts.SQLFilePath, cmp.Or(err, fs.ErrNotExist))
+			return nil, fmt.Errorf("failed to read identity: %w", err)
 		}
 	}
 
-	{
-		local, err := findPartPath(archive, "triplestore", "triplestore", "nq")
-		if err != nil {
-			return archiveParts{}, err
-		}
-
-		parts.TSFilePath = filepath.Join(path, local)
-		if isFile, err := fsx.IsRegular(parts.TSFilePath, false); !isFile {
-			return archiveParts{}, fmt.Errorf("%w: %s: %w", errTriplestoreDataNotRegularFile, parts.TSFilePath, cmp.Or(err, fs.ErrNotExist))
-		}
+	if !sr.FromLog {
+		return dis.Exporter().Stage(cmd.Context(), cmd.ErrOrStderr(), sr.Positionals.Source, identities...)
 	}
 
-	return parts, nil
-}
-
-func findPartPath(archive exporter.Snapshot, part string, dir string, extension string) (string, error) {
-	hasSQLPart := false
-	for _, name := range archive.Description.Parts {
-		if name != part {
-			continue
-		}
-		hasSQLPart = true
-	}
-	if !hasSQLPart {
-		return "", fmt.Errorf("%w: %s", errSnapshotMissingPart, part)
-	}
-
-	extension = "." + extension
-	var candidates []string
-	for _, path := range archive.Manifest {
-		// TODO: This is a very ugly search of the manifest
-		// But it's good enough for now.
-		if !strings.HasPrefix(path, dir+"/") || !strings.HasSuffix(path, extension) {
-			continue
-		}
-		candidates = append(candidates, path)
-	}
-
-	if len(candidates) == 0 {
-		return "", fmt.Errorf("%w: No %s files found in manifest", errDataNotFoundInSnapshot, extension)
-	}
-	if len(candidates) > 1 {
-		return "", fmt.Errorf("%w: Multiple %s files found in manifest", errDataNotFoundInSnapshot, extension)
-	}
-	return candidates[0], nil
-}
-
-func shutdownInstance(cmd *cobra.Command, instance *wisski.WissKI) (e error) {
-	stack, err := instance.Barrel().OpenStack()
+	entry, err := sr.findExport(cmd, dis)
 	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToOpenStack, err)
+		return nil, err
 	}
-	defer errorsx.Close(stack, &e, "stack")
-
-	if err := stack.Down(cmd.Context(), cmd.OutOrStdout()); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToShutdownInstance, err)
+	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Export log entry %d: %s\n", entry.Pk, entry.Path); err != nil {
+		return nil, fmt.Errorf("failed to log message: %w", err)
 	}
-	return nil
+	return dis.Exporter().StageExport(cmd.Context(), cmd.ErrOrStderr(), entry, identities...)
 }
 
-func startInstance(cmd *cobra.Command, instance *wisski.WissKI) (e error) {
-	stack, err := instance.Barrel().OpenStack()
-	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToOpenStack, err)
-	}
-	defer errorsx.Close(stack, &e, "stack")
-	if err := stack.Start(cmd.Context(), cmd.OutOrStdout()); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToStartInstance, err)
-	}
-
-	return waitSQL(cmd, instance)
-}
-
-func waitSQL(cmd *cobra.Command, instance *wisski.WissKI) (e error) {
-	// wait for the sql to be up
-	if err := instance.BoundSQL().Impl.StartAndWait(cmd.Context(), cmd.OutOrStdout()); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToWaitSQL, err)
-	}
-	return nil
-}
-
-func (parts archiveParts) restoreDataDirectory(cmd *cobra.Command, old string) (e error) {
-	fresh := parts.DataPath
-
-	st := status.NewWithCompat(cmd.ErrOrStderr(), 1)
-	st.Start()
-	defer st.Set(0, "")
-	defer st.Stop()
-
-	// Create a temporary directory next to the old directory
-	oldParent := filepath.Dir(old)
-	tempDir, err := os.MkdirTemp(oldParent, ".restore-*")
-	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToCreateTemporaryDirectory, err)
-	}
-
-	// Clean up temporary directory on failure
-	defer func() {
-		if e != nil {
-			if _, err := logging.LogMessage(cmd.ErrOrStderr(), "failed to restore, cleaning up"); err != nil {
-				e = errorsx.Combine(e, fmt.Errorf("%w: %w", errFailedToLogMessage, err))
-			}
-			err := os.RemoveAll(tempDir)
-			e = errorsx.Combine(e, err)
-		}
-	}()
-
-	// Copy the new directory contents to the temporary directory
-	if err := filepath.WalkDir(fresh, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Compute relative path and destination
-		relPath, err := filepath.Rel(fresh, path)
-		if err != nil {
-			return fmt.Errorf("%w: %w", errFailedToComputeRelativePath, err)
-		}
-		destPath := filepath.Join(tempDir, relPath)
-
-		st.Set(0, relPath)
-
-		if d.IsDir() {
-			// Create directory with same permissions
-			info, err := d.Info()
-			if err != nil {
-				return fmt.Errorf("%w: %w", errFailedToGetDirectoryInfo, err)
-			}
-			if err := os.MkdirAll(destPath, info.Mode().Perm()); err != nil {
-				return fmt.Errorf("%w: %w", errFailedToCreateDirectory, &fs.PathError{Op: "mkdirall", Path: destPath, Err: err})
-			}
-			return nil
+// findExport finds the export log entry referred to by the source.
+func (sr *snapshotRestore) findExport(cmd *cobra.Command, dis *dis.Distillery) (models.Export, error) {
+	if sr.Positionals.Source == "latest" {
+		if sr.New {
+			return models.Export{}, errExportLogLatestNew
 		}
-
-		// Copy regular file
-		if err := copyFile(path, destPath); err != nil {
-			return fmt.Errorf("%w: %w", errFailedToCopyFile, &fs.PathError{Op: "copy", Path: relPath, Err: err})
-		}
-
-		return nil
-	}); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToCopyDirectory, err)
-	}
-
-	// Remove the old directory
-	if err := os.RemoveAll(old); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToRemoveOldDirectory, err)
-	}
-
-	// Move the temporary directory to the old directory's place
-	if err := os.Rename(tempDir, old); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToMoveRestoredDirectoryInPlace, err)
-	}
-
-	return nil
-}
-
-// Copies a file from src to dst.
-// If it is a symlink, it is copied as a symlink.
-func copyFile(src, dst string) (e error) {
-	srcInfo, err := os.Lstat(src)
-	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToLstat, err)
-	}
-
-	if srcInfo.Mode()&os.ModeSymlink != 0 {
-		linkTarget, err := os.Readlink(src)
+		exports, err := dis.ExporterLogger().For(cmd.Context(), sr.Positionals.Slug)
 		if err != nil {
-			return fmt.Errorf("%w: %w", errFailedToWalkDir, err)
+			return models.Export{}, fmt.Errorf("failed to read export log: %w", err)
 		}
-		if err := os.Symlink(linkTarget, dst); err != nil {
-			return fmt.Errorf("%w: %w", errFailedToCreateSymlink, err)
+		if len(exports) == 0 {
+			return models.Export{}, errExportLogNoLatest
 		}
-		return nil
+		return slices.MaxFunc(exports, func(a, b models.Export) int {
+			return a.Created.Compare(b.Created)
+		}), nil
 	}
 
-	srcFile, err := os.Open(src) // #nosec G304 -- intended
+	pk, err := strconv.ParseUint(sr.Positionals.Source, 10, 0)
 	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToOpenFile, err)
+		return models.Export{}, fmt.Errorf("%w: %w", errExportLogInvalidID, err)
 	}
-	defer errorsx.Close(srcFile, &e, "src file")
 
-	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, srcInfo.Mode().Perm()) // #nosec G304 -- intended
+	entry, err := dis.ExporterLogger().Get(cmd.Context(), uint(pk))
 	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToOpenFile, err)
-	}
-	defer errorsx.Close(dstFile, &e, "dst file")
-
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToCopyFile, err)
-	}
-
-	return nil
-}
-
-func (parts archiveParts) restoreTriplestore(cmd *cobra.Command, instance *wisski.WissKI) (e error) {
-	progress := cmd.OutOrStdout()
-	liquid := ingredient.GetLiquid(instance.TRB())
-
-	stack, err := instance.Barrel().OpenStack()
-	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToOpenStack, err)
-	}
-	defer errorsx.Close(stack, &e, "stack")
-
-	if _, err := logging.LogMessage(progress, "Purging triplestore repository"); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
-	}
-	if err := liquid.TS.For(liquid.Instance).Purge(cmd.Context(), progress, true); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToPurgeTriplestoreData, err)
-	}
-
-	if _, err := logging.LogMessage(progress, "Re-provisioning triplestore repository"); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
+		return models.Export{}, fmt.Errorf("failed to find export: %w", err)
 	}
-	if err := liquid.TS.Provision(cmd.Context(), progress, liquid.Instance, liquid.Domain(), &stack); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToProvisionTriplestore, err)
-	}
-
-	if _, err := logging.LogMessage(progress, "Restoring triplestore contents"); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
-	}
-
-	file, err := os.Open(parts.TSFilePath)
-	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToOpenTriplestoreBackup, &fs.PathError{Op: "open", Path: parts.TSFilePath, Err: err})
-	}
-	defer func() {
-		_ = file.Close()
-	}()
-	if err := liquid.TS.For(liquid.Instance).RestoreDB(cmd.Context(), progress, file); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToRestoreTriplestoreContents, err)
-	}
-	return nil
-}
-
-func (parts archiveParts) restoreSQL(cmd *cobra.Command, instance *wisski.WissKI) (e error) {
-	liquid := ingredient.GetLiquid(instance.TRB())
-	progress := cmd.OutOrStdout()
-
-	if _, err := logging.LogMessage(progress, "Purging SQL database"); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
-	}
-	if err := liquid.BoundSQL().Purge(cmd.Context(), progress); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToPurgeSQLDatabase, err)
-	}
-
-	if _, err := logging.LogMessage(progress, "Re-provisioning SQL database"); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
-	}
-	if err := liquid.BoundSQL().Provision(cmd.Context(), progress); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToProvisionSQLDatabase, err)
-	}
-
-	if _, err := logging.LogMessage(progress, "Restoring SQL contents"); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
-	}
-
-	file, err := os.Open(parts.SQLFilePath)
-	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToOpenSQLBackup, &fs.PathError{Op: "open", Path: parts.SQLFilePath, Err: err})
-	}
-	defer errorsx.Close(file, &e, "file")
-
-	if err := liquid.BoundSQL().Restore(cmd.Context(), file, stream.NewIOStream(progress, cmd.ErrOrStderr(), nil)); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToRestoreSQLContents, err)
-	}
-	return nil
-}
-
-func (parts archiveParts) restoreSQLConfig(cmd *cobra.Command, instance *wisski.WissKI) (e error) {
-	if err := instance.Settings().SetDefaultDBConnection(cmd.Context(), nil, instance.BoundSQL().SQLUrl()); err != nil {
-		return fmt.Errorf("%w: %w", errFailedToRestoreSQLConfig, err)
-	}
-	return nil
+	return entry, nil
 }
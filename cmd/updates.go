@@ -0,0 +1,133 @@
+package cmd
+
+//spellchecker:words encoding json sync github wisski distillery internal models cobra pkglib exit status
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/cli"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
+	"github.com/spf13/cobra"
+	"go.tkw01536.de/pkglib/exit"
+	"go.tkw01536.de/pkglib/status"
+)
+
+func NewUpdatesCommand() *cobra.Command {
+	impl := new(updates)
+
+	cmd := &cobra.Command{
+		Use:     "updates SLUG...",
+		Short:   "lists the pending package and database updates of instances, without applying them",
+		Long:    "lists the pending package and database updates of the given instances, or all instances if none are given.",
+		Args:    cobra.ArbitraryArgs,
+		PreRunE: impl.ParseArgs,
+		RunE:    impl.Exec,
+	}
+
+	flags := cmd.Flags()
+	flags.IntVar(&impl.Parallel, "parallel", 1, "check (at most) this many instances in parallel. 0 for no limit")
+	flags.BoolVar(&impl.Cached, "cached", false, "do not check for updates, but print the result of the last check")
+	flags.BoolVar(&impl.JSON, "json", false, "print pending updates as JSON")
+
+	return cmd
+}
+
+type updates struct {
+	Parallel    int
+	Cached      bool
+	JSON        bool
+	Positionals struct {
+		Slug []string
+	}
+}
+
+func (u *updates) ParseArgs(cmd *cobra.Command, args []string) error {
+	u.Positionals.Slug = args
+	return nil
+}
+
+var errUpdatesFailed = exit.NewErrorWithCode("failed to check updates", cli.ExitGeneric)
+
+func (u *updates) Exec(cmd *cobra.Command, args []string) error {
+	dis, err := cli.GetDistillery(cmd, cli.Requirements{
+		NeedsDistillery: true,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUpdatesFailed, err)
+	}
+
+	wissKIs, err := dis.Instances().Load(cmd.Context(), u.Positionals.Slug...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUpdatesFailed, err)
+	}
+
+	var mu sync.Mutex
+	pending := make(map[string]models.PendingUpdates, len(wissKIs))
+
+	checkErr := status.WriterGroup(cmd.ErrOrStderr(), u.Parallel, func(instance *wisski.WissKI, writer io.Writer) error {
+		var result models.PendingUpdates
+		var err error
+		if u.Cached {
+			result, err = instance.Composer().PendingUpdates(cmd.Context())
+		} else {
+			result, err = instance.Composer().CheckUpdates(cmd.Context(), writer)
+		}
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		pending[instance.Slug] = result
+		return nil
+	}, wissKIs, status.SmartMessage(func(item *wisski.WissKI) string {
+		return fmt.Sprintf("updates %q", item.Slug)
+	}))
+
+	// print the results of all instances that could be checked
+	if u.JSON {
+		if err := json.NewEncoder(cmd.OutOrStdout()).Encode(pending); err != nil {
+			return fmt.Errorf("%w: %w", errUpdatesFailed, err)
+		}
+	} else {
+		for _, instance := range wissKIs {
+			result, ok := pending[instance.Slug]
+			if !ok {
+				continue
+			}
+			printPendingUpdates(cmd.OutOrStdout(), instance.Slug, result)
+		}
+	}
+
+	if checkErr != nil {
+		return fmt.Errorf("%w: %w", errUpdatesFailed, checkErr)
+	}
+	return nil
+}
+
+// printPendingUpdates prints the pending updates of the instance with the given slug in human-readable form.
+func printPendingUpdates(w io.Writer, slug string, pending models.PendingUpdates) {
+	if pending.IsZero() {
+		_, _ = fmt.Fprintf(w, "%s: not checked\n", slug)
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "%s: %d package update(s) (%d security), %d database update(s)\n", slug, len(pending.Packages), pending.SecurityCount(), len(pending.Database))
+	for _, pkg := range pending.Packages {
+		security := ""
+		if pkg.Security {
+			security = " [security]"
+		}
+		_, _ = fmt.Fprintf(w, "  package  %s %s %s => %s%s\n", pkg.Name, pkg.Operation, pkg.From, pkg.To, security)
+	}
+	for _, update := range pending.Database {
+		_, _ = fmt.Fprintf(w, "  database %s %s: %s\n", update.Module, update.ID, update.Description)
+	}
+	for _, advisory := range pending.Advisories {
+		_, _ = fmt.Fprintf(w, "  advisory %s: %s %s\n", advisory.Package, advisory.Title, advisory.CVE)
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+//spellchecker:words filippo github wisski distillery internal component exporter models cobra pkglib exit
+import (
+	"fmt"
+
+	"filippo.io/age"
+	"github.com/FAU-CDI/wisski-distillery/internal/cli"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/spf13/cobra"
+	"go.tkw01536.de/pkglib/exit"
+)
+
+func NewVerifyCommand() *cobra.Command {
+	impl := new(verify)
+
+	cmd := &cobra.Command{
+		Use:     "verify [SLUG]",
+		Short:   "verifies the integrity of exported backups and snapshots",
+		Long:    "verifies that exports are still stored, readable, and match the checksums recorded when they were made. Results are shown in the admin interface.",
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: impl.ParseArgs,
+		RunE:    impl.Exec,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&impl.Backups, "backups", false, "only verify backups")
+	flags.StringVar(&impl.Identity, "identity", "", "read an additional age identity to verify the content of encrypted archives from `FILE`")
+
+	return cmd
+}
+
+type verify struct {
+	Backups     bool
+	Identity    string
+	Positionals struct {
+		Slug string
+	}
+}
+
+var errVerifyArguments = exit.NewErrorWithCode("`--backups` cannot be combined with a slug", cli.ExitCommandArguments)
+
+func (v *verify) ParseArgs(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		v.Positionals.Slug = args[0]
+	}
+	if v.Backups && v.Positionals.Slug != "" {
+		return errVerifyArguments
+	}
+	return nil
+}
+
+var (
+	errVerifyFailed   = exit.NewErrorWithCode("failed to verify exports", cli.ExitGeneric)
+	errVerifyProblems = exit.NewErrorWithCode("some exports failed verification", cli.ExitGeneric)
+)
+
+func (v *verify) Exec(cmd *cobra.Command, args []string) error {
+	dis, err := cli.GetDistillery(cmd, cli.Requirements{
+		NeedsDistillery: true,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", errVerifyFailed, err)
+	}
+
+	// find the exports to verify
+	var exports []models.Export
+	if v.Backups || v.Positionals.Slug != "" {
+		exports, err = dis.ExporterLogger().For(cmd.Context(), v.Positionals.Slug)
+	} else {
+		exports, err = dis.ExporterLogger().Log(cmd.Context())
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", errVerifyFailed, err)
+	}
+
+	// also verify exports flagged as missing, in case they have reappeared
+	missing, err := dis.ExporterLogger().Missing(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("%w: %w", errVerifyFailed, err)
+	}
+	for _, export := range missing {
+		if (!v.Backups && v.Positionals.Slug == "") || export.Slug == v.Positionals.Slug {
+			exports = append(exports, export)
+		}
+	}
+
+	var identities []age.Identity
+	if v.Identity != "" {
+		identities, err = exporter.ReadIdentities(v.Identity)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errVerifyFailed, err)
+		}
+	}
+
+	results, err := dis.Verifier().VerifyExports(cmd.Context(), cmd.OutOrStdout(), exports, identities...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errVerifyFailed, err)
+	}
+
+	problems := 0
+	for _, result := range results {
+		if result.Problem != "" {
+			problems++
+		}
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Verified %d export(s), %d with problems\n", len(results), problems)
+
+	if problems > 0 {
+		return errVerifyProblems
+	}
+	return nil
+}
@@ -27,7 +27,14 @@ func main() {
 
 	// and run the command
 	cmd := cmd.NewCommand(ctx, params)
-	if err := cmd.Execute(); err != nil {
+	err = cmd.Execute()
+
+	// flush log files and remote sinks before exiting
+	if cerr := cli.CloseLogging(cmd); cerr != nil {
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), cerr)
+	}
+
+	if err != nil {
 		code, _ := exit.CodeFromError(err, cli.ExitGeneric)
 		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), err)
 		code.Return()
@@ -12,6 +12,8 @@ type cobraKey int
 const (
 	flagsKey cobraKey = iota
 	parametersKey
+	loggingKey
+	loggingCleanupKey
 )
 
 // SetFlags sets the value for a cobra command from a set of flags.
@@ -73,6 +73,13 @@ func GetDistillery(cmd *cobra.Command, req Requirements) (d *dis.Distillery, e e
 	d.Config = &config.Config{
 		ConfigPath: cfg,
 	}
-	e = d.Config.Unmarshal(f)
-	return
+	if err := d.Config.Unmarshal(f); err != nil {
+		return nil, err
+	}
+
+	// log as configured by the distillery
+	if err := setupLogging(cmd, flags, d.Config); err != nil {
+		return nil, err
+	}
+	return d, nil
 }
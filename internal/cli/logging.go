@@ -0,0 +1,67 @@
+package cli
+
+//spellchecker:words github wisski distillery internal config wdlog cobra pkglib exit
+import (
+	"fmt"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/config"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+	"github.com/spf13/cobra"
+	"go.tkw01536.de/pkglib/exit"
+)
+
+var (
+	errSetupLogging = exit.NewErrorWithCode("failed to setup logging", ExitGeneralArguments)
+	errCloseLogging = exit.NewErrorWithCode("failed to close logging", ExitGeneric)
+)
+
+// loggingCleanup records how to close the log files and remote sinks opened by [setupLogging].
+type loggingCleanup struct {
+	cleanup func() error
+}
+
+// PrepareLogging prepares the root command to close logging set up by any of its subcommands.
+// It must be called before the command is executed.
+func PrepareLogging(root *cobra.Command) {
+	set(root, loggingCleanupKey, &loggingCleanup{})
+}
+
+// CloseLogging closes the log files and remote sinks opened while executing root, see [PrepareLogging].
+// It should be called once root has been executed.
+func CloseLogging(root *cobra.Command) error {
+	holder, ok := root.Context().Value(loggingCleanupKey).(*loggingCleanup)
+	if !ok || holder.cleanup == nil {
+		return nil
+	}
+
+	cleanup := holder.cleanup
+	holder.cleanup = nil
+	if err := cleanup(); err != nil {
+		return fmt.Errorf("%w: %w", errCloseLogging, err)
+	}
+	return nil
+}
+
+// setupLogging replaces the logger of cmd with one logging as described by the configuration.
+// Subsequent calls for the same command do nothing.
+//
+// Log files and remote sinks stay open until [CloseLogging] is called.
+func setupLogging(cmd *cobra.Command, flags Flags, cfg *config.Config) error {
+	if get[bool](cmd, loggingKey) {
+		return nil
+	}
+
+	logger, cleanup, err := wdlog.Open(cmd.ErrOrStderr(), cfg.Logging.Options(cfg.Paths.Root, cmd.Name(), flags.LogLevel.Level()))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errSetupLogging, err)
+	}
+
+	if holder, ok := cmd.Context().Value(loggingCleanupKey).(*loggingCleanup); ok {
+		holder.cleanup = cleanup
+	}
+
+	configured := true
+	set(cmd, loggingKey, &configured)
+	cmd.SetContext(wdlog.Set(cmd.Context(), logger))
+	return nil
+}
@@ -0,0 +1,55 @@
+//spellchecker:words config
+package config
+
+//spellchecker:words net strconv time
+import (
+	"net"
+	"strconv"
+	"time"
+)
+
+// AlertsConfig configures health checks, and the notifications sent when their state changes.
+type AlertsConfig struct {
+	// Disabled lists the kinds of checks that are not evaluated.
+	// Kinds are "barrel", "drupal_cron", "cron_task", "sql" and "triplestore".
+	Disabled []string `yaml:"disabled"`
+
+	// CronMaxAge is the maximal time since drupal cron last ran in a running instance.
+	CronMaxAge time.Duration `default:"24h" validate:"duration" yaml:"cron_max_age"`
+
+	// Repeat is the interval in which notifications about checks that keep failing are repeated.
+	Repeat time.Duration `default:"24h" validate:"duration" yaml:"repeat"`
+
+	// SMTP configures notifications via e-mail.
+	SMTP SMTPConfig `recurse:"true" yaml:"smtp"`
+
+	// Webhooks are urls that notifications are posted to as JSON.
+	// The payload contains a "text" field, making it compatible with Slack and Matrix webhooks.
+	Webhooks []string `validate:"http_urls" yaml:"webhooks"`
+}
+
+// SMTPConfig configures sending e-mail via an SMTP server.
+type SMTPConfig struct {
+	// Host and Port of the SMTP server.
+	// When Host is empty, no e-mails are sent.
+	Host string `yaml:"host"`
+	Port uint16 `default:"587" validate:"port" yaml:"port"`
+
+	// Credentials to authenticate with, if any.
+	Username string `yaml:"username"`
+	Password string `sensitive:"****" yaml:"password"`
+
+	// From is the sender of notifications, To the recipients.
+	From string   `validate:"email" yaml:"from"`
+	To   []string `validate:"emails" yaml:"to"`
+}
+
+// Enabled checks if e-mails should be sent.
+func (sc SMTPConfig) Enabled() bool {
+	return sc.Host != "" && len(sc.To) > 0
+}
+
+// Addr returns the address of the SMTP server.
+func (sc SMTPConfig) Addr() string {
+	return net.JoinHostPort(sc.Host, strconv.Itoa(int(sc.Port)))
+}
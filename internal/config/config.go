@@ -37,7 +37,23 @@ type Config struct {
 	SQL SQLConfig `recurse:"true" yaml:"sql"`
 	TS  TSConfig  `recurse:"true" yaml:"triplestore"`
 
-	// Maximum age for backup in days
+	// Storage determines where exported archives are stored
+	Storage StorageConfig `recurse:"true" yaml:"storage"`
+
+	// Encryption determines if and how exported archives are encrypted
+	Encryption EncryptionConfig `recurse:"true" yaml:"encryption"`
+
+	// OIDC configures login via an OpenID Connect identity provider
+	OIDC OIDCConfig `recurse:"true" yaml:"oidc"`
+
+	// Alerts configures health checks and notifications about them
+	Alerts AlertsConfig `recurse:"true" yaml:"alerts"`
+
+	// Logging configures the format and destinations of log messages
+	Logging LoggingConfig `recurse:"true" yaml:"logging"`
+
+	// Maximum age for backup in days.
+	// Only used for exports without a retention policy.
 	MaxBackupAge time.Duration `validate:"duration" yaml:"age"`
 
 	// Various components use password-based-authentication.
@@ -0,0 +1,19 @@
+//spellchecker:words config
+package config
+
+// EncryptionConfig configures encryption of exported archives at rest.
+type EncryptionConfig struct {
+	// Recipients are the age X25519 public keys ("age1...") new archives are encrypted to.
+	// When empty, archives are not encrypted.
+	Recipients []string `validate:"age_recipients" yaml:"recipients"`
+
+	// IdentityFile is the path to a file holding age identities ("AGE-SECRET-KEY-1...").
+	// It is used to decrypt archives when restoring and verifying them.
+	// When empty, encrypted archives can only be decrypted with an explicitly provided identity.
+	IdentityFile string `yaml:"identity_file"`
+}
+
+// Enabled checks if new archives should be encrypted.
+func (ec EncryptionConfig) Enabled() bool {
+	return len(ec.Recipients) > 0
+}
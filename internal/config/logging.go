@@ -0,0 +1,57 @@
+//spellchecker:words config
+package config
+
+//spellchecker:words slog path filepath github wisski distillery internal wdlog
+import (
+	"log/slog"
+	"path/filepath"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+)
+
+// LoggingConfig configures how and where the distillery logs.
+type LoggingConfig struct {
+	// Format of log messages, either "text" or "json".
+	Format string `default:"text" validate:"log_format" yaml:"format"`
+
+	// Directory to additionally write log files into.
+	// Every command logs into a file named after it, e.g. "server.log".
+	// Relative paths are resolved against the deployment directory.
+	// When empty, messages are only written to standard error.
+	Directory string `yaml:"directory"`
+
+	// Log files are rotated once they exceed MaxSize megabytes.
+	// At most MaxFiles rotated files are kept.
+	MaxSize  int `default:"100" validate:"positive" yaml:"max_size"`
+	MaxFiles int `default:"5"   validate:"positive" yaml:"max_files"`
+
+	// Syslog and GELF are urls of remote sinks to additionally send messages to.
+	// Supported are "udp://host:port", "tcp://host:port" and "unix:///path/to/socket".
+	Syslog string `validate:"log_sink" yaml:"syslog"`
+	GELF   string `validate:"log_sink" yaml:"gelf"`
+}
+
+// Options returns the options to log with.
+// root is the deployment directory, command the name of the running command.
+func (lc LoggingConfig) Options(root string, command string, level slog.Level) wdlog.Options {
+	options := wdlog.Options{
+		Level:  level,
+		Format: wdlog.Format(lc.Format),
+
+		MaxSize:  int64(lc.MaxSize) * 1024 * 1024,
+		MaxFiles: lc.MaxFiles,
+
+		Syslog:  lc.Syslog,
+		GELF:    lc.GELF,
+		AppName: "wdcli-" + command,
+	}
+
+	if lc.Directory != "" {
+		dir := lc.Directory
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		options.File = filepath.Join(dir, command+".log")
+	}
+	return options
+}
@@ -0,0 +1,73 @@
+//spellchecker:words config
+package config
+
+//spellchecker:words slices github wisski distillery internal config validators
+import (
+	"slices"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/config/validators"
+)
+
+// OIDCConfig configures login via an OpenID Connect identity provider.
+type OIDCConfig struct {
+	// Issuer is the url of the identity provider, e.g. "https://idp.example.com/realms/university".
+	// When empty, login via OpenID Connect is disabled.
+	Issuer *validators.URL `yaml:"issuer"`
+
+	// Client credentials registered with the identity provider.
+	// The redirect url to register is "/auth/oidc/callback" on the panel domain.
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `sensitive:"****" yaml:"client_secret"`
+
+	// Title is shown on the login button.
+	Title string `default:"Single Sign-On" validate:"nonempty" yaml:"title"`
+
+	// Scopes to request in addition to "openid", "profile" and "email".
+	Scopes []string `yaml:"scopes"`
+
+	// UsernameClaim is the claim of the id token holding the name of the distillery user.
+	// Users that do not exist yet are created on their first login.
+	UsernameClaim string `default:"preferred_username" validate:"nonempty" yaml:"username_claim"`
+
+	// GroupsClaim is the claim of the id token holding the groups of the user.
+	GroupsClaim string `default:"groups" validate:"nonempty" yaml:"groups_claim"`
+
+	// AdminGroups are the groups whose members are distillery administrators.
+	// When non-empty, the admin flag of users is updated on every login.
+	AdminGroups []string `yaml:"admin_groups"`
+
+	// Grants map groups to access to instances, in the form "group=slug" or "group=slug:role".
+	// Role is one of "reviewer" (the default), "editor", "operator" or "admin".
+	// Grants to instances that appear here are updated on every login.
+	Grants []string `validate:"oidc_grants" yaml:"grants"`
+}
+
+// Enabled checks if login via OpenID Connect is enabled.
+func (oc OIDCConfig) Enabled() bool {
+	return oc.Issuer.String() != ""
+}
+
+// IsAdmin checks if any of the given groups makes a user an administrator.
+// managed indicates if the admin flag is managed by the identity provider at all.
+func (oc OIDCConfig) IsAdmin(groups []string) (admin, managed bool) {
+	if len(oc.AdminGroups) == 0 {
+		return false, false
+	}
+	return slices.ContainsFunc(oc.AdminGroups, func(group string) bool {
+		return slices.Contains(groups, group)
+	}), true
+}
+
+// GrantMappings returns the parsed group to grant mappings.
+// Invalid mappings are skipped, they are rejected when the configuration is validated.
+func (oc OIDCConfig) GrantMappings() []validators.OIDCGrant {
+	grants := make([]validators.OIDCGrant, 0, len(oc.Grants))
+	for _, value := range oc.Grants {
+		grant, err := validators.ParseOIDCGrant(value)
+		if err != nil {
+			continue
+		}
+		grants = append(grants, grant)
+	}
+	return grants
+}
@@ -0,0 +1,40 @@
+//spellchecker:words config
+package config
+
+//spellchecker:words time github wisski distillery internal config validators
+import (
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/config/validators"
+)
+
+// StorageConfig determines where exported archives are stored.
+type StorageConfig struct {
+	// Target is the name of the target new archives are stored in.
+	// Either "local" to keep archives in the local archive directory, or "s3" to upload them to an S3-compatible storage.
+	Target string `default:"local" validate:"nonempty" yaml:"target"`
+
+	// S3 configures the S3-compatible storage.
+	// Only used when Target is "s3".
+	S3 S3Config `recurse:"true" yaml:"s3"`
+
+	// VerifyInterval is the interval in which stored exports are re-verified by cron.
+	VerifyInterval time.Duration `default:"168h" validate:"duration" yaml:"verify_interval"`
+}
+
+// S3Config configures an S3-compatible object storage.
+type S3Config struct {
+	// Endpoint is the url of the storage, e.g. "https://s3.eu-central-1.amazonaws.com".
+	Endpoint *validators.URL `yaml:"endpoint"`
+
+	// Region to sign requests for.
+	Region string `default:"us-east-1" validate:"nonempty" yaml:"region"`
+
+	// Bucket to store archives in, and prefix for the name of each archive.
+	Bucket string `yaml:"bucket"`
+	Prefix string `yaml:"prefix"`
+
+	// Credentials to access the bucket with.
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `sensitive:"****" yaml:"secret_key"`
+}
@@ -0,0 +1,20 @@
+//spellchecker:words validators
+package validators
+
+//spellchecker:words filippo
+import (
+	"fmt"
+
+	"filippo.io/age"
+)
+
+// ValidateAgeRecipient checks that recipient is an age X25519 public key.
+func ValidateAgeRecipient(recipient *string, dflt string) error {
+	if *recipient == "" {
+		*recipient = dflt
+	}
+	if _, err := age.ParseX25519Recipient(*recipient); err != nil {
+		return fmt.Errorf("%q is not a valid age recipient: %w", *recipient, err)
+	}
+	return nil
+}
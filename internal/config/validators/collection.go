@@ -20,13 +20,21 @@ func New() validator.Collection {
 	validator.Add(coll, "domain", ValidateDomain)
 	validator.AddSlice(coll, "domains", ",", ValidateDomain)
 	validator.Add(coll, "https", ValidateHTTPSURL)
+	validator.AddSlice(coll, "http_urls", ",", ValidateHTTPURL)
 	validator.Add(coll, "slug", ValidateSlug)
 	validator.Add(coll, "email", ValidateEmail)
+	validator.AddSlice(coll, "emails", ",", ValidateEmail)
 
 	validator.Add(coll, "positive", ValidatePositive)
 	validator.Add(coll, "port", ValidatePort)
 	validator.AddSlice(coll, "ports", ",", ValidatePort)
 
 	validator.Add(coll, "duration", ValidateDuration)
+
+	validator.Add(coll, "log_format", ValidateLogFormat)
+	validator.Add(coll, "log_sink", ValidateLogSink)
+
+	validator.AddSlice(coll, "age_recipients", ",", ValidateAgeRecipient)
+	validator.AddSlice(coll, "oidc_grants", ",", ValidateOIDCGrant)
 	return coll
 }
@@ -0,0 +1,39 @@
+//spellchecker:words validators
+package validators
+
+//spellchecker:words errors github wisski distillery internal wdlog
+import (
+	"errors"
+	"fmt"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+)
+
+var errInvalidLogFormat = errors.New("invalid log format, expected \"text\" or \"json\"")
+
+// ValidateLogFormat validates that format is a known log format.
+func ValidateLogFormat(format *string, dflt string) error {
+	if *format == "" {
+		*format = dflt
+	}
+	switch wdlog.Format(*format) {
+	case wdlog.FormatText, wdlog.FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", errInvalidLogFormat, *format)
+	}
+}
+
+// ValidateLogSink validates that sink is empty, or the url of a remote log sink.
+func ValidateLogSink(sink *string, dflt string) error {
+	if *sink == "" {
+		*sink = dflt
+	}
+	if *sink == "" {
+		return nil
+	}
+	if _, _, err := wdlog.ParseSink(*sink); err != nil {
+		return fmt.Errorf("invalid log sink: %w", err)
+	}
+	return nil
+}
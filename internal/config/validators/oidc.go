@@ -0,0 +1,52 @@
+//spellchecker:words validators
+package validators
+
+//spellchecker:words errors strings github wisski distillery internal models
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+// OIDCGrant maps members of an identity provider group to access to an instance.
+type OIDCGrant struct {
+	Group string
+	Slug  string
+	Role  models.Role // role of the user on the instance
+}
+
+var errInvalidOIDCGrant = errors.New("grant must be of the form 'group=slug' or 'group=slug:role'")
+
+// ParseOIDCGrant parses a grant of the form "group=slug" or "group=slug:role".
+// When no role is given, the user is granted [models.RoleReviewer].
+func ParseOIDCGrant(value string) (grant OIDCGrant, err error) {
+	group, target, ok := strings.Cut(value, "=")
+	if !ok || group == "" {
+		return OIDCGrant{}, fmt.Errorf("%w: %q", errInvalidOIDCGrant, value)
+	}
+
+	slug, name, hasRole := strings.Cut(target, ":")
+	role := models.RoleReviewer
+	if hasRole {
+		role, err = models.ParseRole(name)
+		if err != nil {
+			return OIDCGrant{}, fmt.Errorf("%w: %q: %w", errInvalidOIDCGrant, value, err)
+		}
+	}
+	if err := ValidateSlug(&slug, ""); err != nil {
+		return OIDCGrant{}, fmt.Errorf("%w: %q: %w", errInvalidOIDCGrant, value, err)
+	}
+
+	return OIDCGrant{Group: group, Slug: slug, Role: role}, nil
+}
+
+// ValidateOIDCGrant validates that value is a grant accepted by [ParseOIDCGrant].
+func ValidateOIDCGrant(value *string, dflt string) error {
+	if *value == "" {
+		*value = dflt
+	}
+	_, err := ParseOIDCGrant(*value)
+	return err
+}
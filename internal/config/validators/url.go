@@ -49,3 +49,20 @@ func ValidateHTTPSURL(url **URL, dflt string) error {
 	}
 	return nil
 }
+
+var errNotValidHTTPURL = errors.New("not a valid http or https URL")
+
+// ValidateHTTPURL checks that s is a url with an http or https scheme, and then returns it as is.
+func ValidateHTTPURL(s *string, dflt string) error {
+	if *s == "" {
+		*s = dflt
+	}
+	u, err := url.Parse(*s)
+	if err != nil {
+		return fmt.Errorf("failed to parse url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q", errNotValidHTTPURL, *s)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+//spellchecker:words audit
+package audit
+
+//spellchecker:words context
+import (
+	"context"
+)
+
+// Origin describes how the actor of an action was authenticated.
+type Origin string
+
+const (
+	OriginSystem  Origin = "system"  // the distillery itself, e.g. from a cron task
+	OriginSession Origin = "session" // a user logged in using a browser session
+	OriginToken   Origin = "token"   // a user authenticated with an api token
+	OriginCLI     Origin = "cli"     // a system user using the command line
+)
+
+// Actor is whoever performs an action.
+type Actor struct {
+	Origin Origin
+	User   string // distillery user, or system user for the command line
+	Token  string // id of the token, if any
+}
+
+// System is the actor used when no other actor is known.
+var System = Actor{Origin: OriginSystem}
+
+type actorKey struct{}
+
+// WithActor returns a new context that records actions as being performed by actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorOf returns the actor stored in the given context.
+// If there is no actor, returns [System].
+func ActorOf(ctx context.Context) Actor {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	if !ok {
+		return System
+	}
+	return actor
+}
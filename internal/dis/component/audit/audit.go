@@ -0,0 +1,162 @@
+// Package audit implements a persistent log of administrative actions.
+//
+//spellchecker:words audit
+package audit
+
+//spellchecker:words context encoding json strings time github wisski distillery internal component models wdlog gorm
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+	"gorm.io/gorm"
+)
+
+// Audit records administrative actions, and who performed them.
+type Audit struct {
+	component.Base
+	dependencies struct {
+		SQL *sql.SQL
+	}
+}
+
+var (
+	_ component.Table = (*Audit)(nil)
+)
+
+func (*Audit) TableInfo() component.TableInfo {
+	return component.TableInfo{
+		Model: models.AuditEntry{},
+	}
+}
+
+func (audit *Audit) table(ctx context.Context) (*gorm.DB, error) {
+	conn, err := audit.dependencies.SQL.OpenTable(ctx, audit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table: %w", err)
+	}
+	return conn, nil
+}
+
+// Record records that the actor stored in ctx performed the given action.
+// Slug is the instance targeted by the action, and may be empty.
+// Params are json-encoded and stored alongside the entry.
+// Err is the outcome of the action, with nil indicating success.
+//
+// Failing to record an action does not fail the action itself.
+// Instead, the failure is logged.
+func (audit *Audit) Record(ctx context.Context, action string, slug string, params any, err error) {
+	actor := ActorOf(ctx)
+
+	entry := models.AuditEntry{
+		Time: time.Now(),
+
+		Origin: string(actor.Origin),
+		User:   actor.User,
+		Token:  actor.Token,
+
+		Action:  action,
+		Slug:    slug,
+		Success: err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if rerr := audit.add(ctx, &entry, params); rerr != nil {
+		wdlog.Of(ctx).Warn(
+			"failed to record action in audit log",
+			"action", action,
+			"slug", slug,
+			"error", rerr,
+		)
+	}
+}
+
+func (audit *Audit) add(ctx context.Context, entry *models.AuditEntry, params any) (err error) {
+	if params != nil {
+		entry.Params, err = json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to marshal params: %w", err)
+		}
+	}
+
+	table, err := audit.table(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := table.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to create entry: %w", err)
+	}
+	return nil
+}
+
+// Filter determines which entries are returned by [Audit.Entries].
+// Empty fields match all entries.
+type Filter struct {
+	User   string
+	Action string // exact name of an action, or a prefix ending in "."
+	Slug   string
+
+	Since time.Time // only return entries after this time
+	Limit int       // maximum number of entries to return, defaults to [DefaultLimit]
+
+	Failed bool // only return failed actions
+}
+
+// DefaultLimit is the default maximal number of entries returned by [Audit.Entries].
+const DefaultLimit = 100
+
+// Entries returns the entries matching the given filter, newest first.
+func (audit *Audit) Entries(ctx context.Context, filter Filter) ([]models.AuditEntry, error) {
+	table, err := audit.table(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := table.Order("time desc, pk desc")
+	if filter.User != "" {
+		query = query.Where("user = ?", filter.User)
+	}
+	if filter.Action != "" {
+		if filter.Action[len(filter.Action)-1] == '.' {
+			query = query.Where("action LIKE ?", escapeLike(filter.Action)+"%")
+		} else {
+			query = query.Where("action = ?", filter.Action)
+		}
+	}
+	if filter.Slug != "" {
+		query = query.Where("slug = ?", filter.Slug)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("time >= ?", filter.Since)
+	}
+	if filter.Failed {
+		query = query.Where("success = ?", false)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	var entries []models.AuditEntry
+	if err := query.Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to find entries: %w", err)
+	}
+	return entries, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern in s.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -1,12 +1,14 @@
 //spellchecker:words auth
 package auth
 
-//spellchecker:words context http github wisski distillery internal component auth tokens server templating gorilla sessions julienschmidt httprouter pkglib lazy
+//spellchecker:words context http github wisski distillery internal component audit auth passkeys tokens server templating gorilla sessions julienschmidt httprouter pkglib lazy
 import (
 	"context"
 	"net/http"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/passkeys"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/tokens"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
@@ -20,12 +22,17 @@ type Auth struct {
 	dependencies struct {
 		SQL             *sql.SQL
 		UserDeleteHooks []component.UserDeleteHook
+		SSOLoginHooks   []component.SSOLoginHook
 		Templating      *templating.Templating
 		ScopeProviders  []component.ScopeProvider
 		Tokens          *tokens.Tokens
+		Passkeys        *passkeys.Passkeys
+		Audit           *audit.Audit
 	}
 
-	store lazy.Lazy[sessions.Store]
+	store     lazy.Lazy[sessions.Store]
+	flowStore lazy.Lazy[sessions.Store]
+	oidc      oidcProvider
 
 	scopeMap lazy.Lazy[map[component.Scope]scopeMapEntry]
 }
@@ -56,7 +63,18 @@ func (auth *Auth) HandleRoute(ctx context.Context, route string) (http.Handler,
 		router.Handler(http.MethodPost, route+"login", login)
 	}
 
+	{
+		passkey := auth.authPasskey(ctx)
+		router.Handler(http.MethodGet, route+"passkey", passkey)
+		router.Handler(http.MethodPost, route+"passkey", passkey)
+	}
+
 	router.Handler(http.MethodGet, route+"logout", auth.authLogout(ctx))
 
+	if component.GetStill(auth).Config.OIDC.Enabled() {
+		router.Handler(http.MethodGet, route+"oidc/login", auth.oidcLogin(ctx))
+		router.Handler(http.MethodGet, route+"oidc/callback", auth.oidcCallback(ctx))
+	}
+
 	return router, nil
 }
@@ -63,7 +63,7 @@ func (next *Next) getInstance(r *http.Request) (wisski *wisski.WissKI, path stri
 	}
 
 	// find the slug
-	slug, ok := component.GetStill(next).Config.HTTP.SlugFromHost(url.Host)
+	slug, ok := next.dependencies.Instances.SlugFromHost(r.Context(), url.Host)
 	if slug == "" || !ok {
 		return nil, "", httpx.ErrBadRequest
 	}
@@ -92,6 +92,11 @@ func (next *Next) HandleRoute(ctx context.Context, path string) (http.Handler, e
 			return "", 0, fmt.Errorf("failed to get session: %w", err)
 		}
 
+		// tokens may be restricted to specific instances
+		if err := next.dependencies.Auth.CheckInstance(instance.Slug, r); err != nil {
+			return "", 0, httpx.ErrForbidden
+		}
+
 		// check if they have a grant
 		var isImplicitGrant bool
 		grant, err := next.dependencies.Policy.Has(r.Context(), user.User.User, instance.Slug)
@@ -112,7 +117,7 @@ func (next *Next) HandleRoute(ctx context.Context, path string) (http.Handler, e
 			Implicit:        isImplicitGrant,
 			Destination:     path,
 			CreateIfMissing: true,
-			GrantAdminRole:  grant.DrupalAdminRole,
+			Role:            grant.GetRole(),
 		})
 		if err != nil {
 			return "", 0, fmt.Errorf("failed to login user: %w", err)
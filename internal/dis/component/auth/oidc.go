@@ -0,0 +1,445 @@
+//spellchecker:words auth
+package auth
+
+//spellchecker:words context crypto rand sha256 encoding errors html template slog http slices strings sync github coreos oidc wisski distillery internal config component server assets templating models wdlog gorilla sessions pkglib httpx golang oauth gorm embed
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/config"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/assets"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gorilla/sessions"
+	"go.tkw01536.de/pkglib/httpx"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+
+	_ "embed"
+)
+
+// OIDCIdentity is the identity of a user as asserted by an OpenID Connect identity provider.
+type OIDCIdentity struct {
+	// Issuer and Subject uniquely identify the user.
+	Issuer  string
+	Subject string
+
+	// Username is the name of the user, it may change over time.
+	Username string
+	Groups   []string
+
+	// MFA indicates if the identity provider authenticated the user with multiple factors.
+	MFA bool
+}
+
+// Link returns the value linking a local user to this identity.
+// It is derived from the issuer and subject, which never change for the same user.
+func (identity OIDCIdentity) Link() string {
+	sum := sha256.Sum256([]byte(identity.Issuer + "\x00" + identity.Subject))
+	return hex.EncodeToString(sum[:])
+}
+
+// OIDCProvider authenticates users against an OpenID Connect identity provider.
+type OIDCProvider struct {
+	config   config.OIDCConfig
+	oauth2   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCProvider discovers the identity provider described by config.
+// redirectURL is the url the identity provider redirects to after a login.
+func NewOIDCProvider(ctx context.Context, config config.OIDCConfig, redirectURL string) (*OIDCProvider, error) {
+	provider, err := oidc.NewProvider(ctx, config.Issuer.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover identity provider: %w", err)
+	}
+
+	scopes := []string{oidc.ScopeOpenID, "profile", "email"}
+	for _, scope := range config.Scopes {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return &OIDCProvider{
+		config: config,
+		oauth2: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  redirectURL,
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: config.ClientID}),
+	}, nil
+}
+
+// AuthCodeURL returns the url to send the user to in order to login at the identity provider.
+// state, nonce and verifier must be random strings that are unique to this login attempt.
+func (op *OIDCProvider) AuthCodeURL(state, nonce, verifier string) string {
+	return op.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+}
+
+var (
+	errOIDCNoIDToken  = errors.New("token response did not contain an id token")
+	errOIDCNonce      = errors.New("id token nonce does not match")
+	errOIDCNoSubject  = errors.New("id token does not contain an issuer and subject")
+	errOIDCNoUsername = errors.New("id token does not contain a username")
+	errOIDCGroups     = errors.New("id token contains invalid groups")
+)
+
+// Exchange exchanges the code returned by the identity provider for the identity of the user.
+// nonce and verifier must be the values originally passed to AuthCodeURL.
+func (op *OIDCProvider) Exchange(ctx context.Context, code, nonce, verifier string) (identity OIDCIdentity, err error) {
+	token, err := op.oauth2.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return identity, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	raw, ok := token.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return identity, errOIDCNoIDToken
+	}
+
+	idToken, err := op.verifier.Verify(ctx, raw)
+	if err != nil {
+		return identity, fmt.Errorf("failed to verify id token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return identity, errOIDCNonce
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return identity, fmt.Errorf("failed to decode claims: %w", err)
+	}
+	return op.Identity(claims)
+}
+
+// Identity extracts the identity of a user from the claims of an id token.
+// The groups claim may be missing, a single string, or a list of strings.
+//
+// Multi-factor authentication is detected by the "mfa" value of the "amr" claim, see RFC 8176.
+func (op *OIDCProvider) Identity(claims map[string]any) (identity OIDCIdentity, err error) {
+	identity.Issuer, _ = claims["iss"].(string)
+	identity.Subject, _ = claims["sub"].(string)
+	if identity.Issuer == "" || identity.Subject == "" {
+		return identity, errOIDCNoSubject
+	}
+
+	if amr, ok := claims["amr"].([]any); ok {
+		identity.MFA = slices.Contains(amr, any("mfa"))
+	}
+
+	identity.Username, _ = claims[op.config.UsernameClaim].(string)
+	if identity.Username == "" {
+		return identity, fmt.Errorf("%w: claim %q", errOIDCNoUsername, op.config.UsernameClaim)
+	}
+
+	var ok bool
+	switch groups := claims[op.config.GroupsClaim].(type) {
+	case nil:
+	case string:
+		identity.Groups = []string{groups}
+	case []any:
+		identity.Groups = make([]string, len(groups))
+		for i, group := range groups {
+			identity.Groups[i], ok = group.(string)
+			if !ok {
+				return identity, fmt.Errorf("%w: claim %q", errOIDCGroups, op.config.GroupsClaim)
+			}
+		}
+	default:
+		return identity, fmt.Errorf("%w: claim %q", errOIDCGroups, op.config.GroupsClaim)
+	}
+	return identity, nil
+}
+
+// oidcProvider holds the discovered identity provider.
+// Discovery is retried on the next login if it fails.
+type oidcProvider struct {
+	m        sync.Mutex
+	provider *OIDCProvider
+}
+
+// OIDC returns the identity provider used for single sign-on.
+func (auth *Auth) OIDC(ctx context.Context) (*OIDCProvider, error) {
+	auth.oidc.m.Lock()
+	defer auth.oidc.m.Unlock()
+
+	if auth.oidc.provider != nil {
+		return auth.oidc.provider, nil
+	}
+
+	config := component.GetStill(auth).Config
+	provider, err := NewOIDCProvider(ctx, config.OIDC, config.HTTP.JoinPath("auth", "oidc", "callback").String())
+	if err != nil {
+		return nil, err
+	}
+	auth.oidc.provider = provider
+	return provider, nil
+}
+
+// ErrSSOAccountExists is returned by LoginSSO when the username of an identity belongs to a local account that cannot be linked.
+var ErrSSOAccountExists = errors.New("an account with the same username already exists")
+
+// LoginSSO logs in the user with the given identity, creating them if they do not yet exist.
+//
+// Users are found by the link to their identity, see [OIDCIdentity.Link].
+// An identity without a linked user is linked to the user with the same username only if that user has neither a password nor a link to another identity.
+// Otherwise [ErrSSOAccountExists] is returned, as the account may belong to someone else.
+// Existing users that have been disabled are rejected.
+func (auth *Auth) LoginSSO(ctx context.Context, identity OIDCIdentity) (user *AuthUser, err error) {
+	link := identity.Link()
+
+	user, err = auth.userBySSO(ctx, link)
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		user, err = auth.linkSSO(ctx, identity.Username, link)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case !user.IsEnabled():
+		return nil, ErrUserDisabled
+	}
+
+	if admin, managed := component.GetStill(auth).Config.OIDC.IsAdmin(identity.Groups); managed {
+		user.SetAdmin(admin)
+	}
+	if err := user.Save(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, hook := range auth.dependencies.SSOLoginHooks {
+		if err := hook.OnSSOLogin(ctx, &user.User, identity.Groups); err != nil {
+			return nil, fmt.Errorf("failed to run login hook %q: %w", hook.Name(), err)
+		}
+	}
+	return user, nil
+}
+
+// userBySSO returns the user linked to the identity with the given link.
+func (auth *Auth) userBySSO(ctx context.Context, link string) (au *AuthUser, err error) {
+	table, err := sql.OpenInterface[models.User](ctx, auth.dependencies.SQL, auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open interface: %w", err)
+	}
+
+	au = &AuthUser{auth: auth}
+	au.User, err = table.Where(&models.User{SSO: &link}).First(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %w", ErrUserNotFound, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return au, nil
+}
+
+// linkSSO links the user with the given name to the identity with the given link, creating the user if needed.
+// The user is not saved.
+func (auth *Auth) linkSSO(ctx context.Context, username string, link string) (*AuthUser, error) {
+	user, err := auth.User(ctx, username)
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		user, err = auth.CreateUser(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+		user.SetEnabled(true)
+	case err != nil:
+		return nil, err
+	case user.SSO != nil || user.HasPassword():
+		return nil, fmt.Errorf("%w: %q", ErrSSOAccountExists, username)
+	case !user.IsEnabled():
+		return nil, ErrUserDisabled
+	}
+
+	user.SSO = &link
+	return user, nil
+}
+
+// keys within the oidc flow session.
+const (
+	oidcStateKey    = "s"
+	oidcNonceKey    = "n"
+	oidcVerifierKey = "v"
+	oidcNextKey     = "next"
+)
+
+// flow returns the session holding the state of a pending single sign-on.
+//
+// The session cookie is SameSite=Strict and hence not sent along with the redirect from the identity provider.
+// This session is only valid for a short time, and is sent along with the redirect.
+func (auth *Auth) flow(r *http.Request) *sessions.Session {
+	sess, err := auth.flowStore.Get(func() sessions.Store {
+		config := component.GetStill(auth).Config
+
+		cookiestore := sessions.NewCookieStore(config.SessionKey())
+		cookiestore.Options.Path = "/auth/oidc/"
+		cookiestore.Options.HttpOnly = true
+		cookiestore.Options.Secure = config.HTTP.HTTPSEnabled()
+		cookiestore.Options.SameSite = http.SameSiteLaxMode
+		cookiestore.Options.MaxAge = 60 * 10 // 10 minutes
+
+		return cookiestore
+	}).Get(r, server.OIDCCookie)
+	if err != nil {
+		wdlog.Of(r.Context()).Debug("failed to decode oidc session, using new session instead", "error", err)
+	}
+	return sess
+}
+
+// oidcLogin redirects the user to the identity provider.
+func (auth *Auth) oidcLogin(ctx context.Context) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provider, err := auth.OIDC(r.Context())
+		if err != nil {
+			wdlog.Of(ctx).Error("failed to setup single sign-on", slog.Any("error", err))
+			httpx.TextInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		state, nonce, verifier := rand.Text(), rand.Text(), oauth2.GenerateVerifier()
+
+		flow := auth.flow(r)
+		flow.Values[oidcStateKey] = state
+		flow.Values[oidcNonceKey] = nonce
+		flow.Values[oidcVerifierKey] = verifier
+		flow.Values[oidcNextKey] = r.URL.Query().Get("next")
+		if err := flow.Save(r, w); err != nil {
+			wdlog.Of(ctx).Error("failed to save oidc session", slog.Any("error", err))
+			httpx.TextInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		http.Redirect(w, r, provider.AuthCodeURL(state, nonce, verifier), http.StatusSeeOther)
+	})
+}
+
+var (
+	errOIDCState    = errors.New("state does not match")
+	errOIDCProvider = errors.New("identity provider returned an error")
+)
+
+//go:embed "oidc.html"
+var oidcHTML []byte
+var oidcTemplate = templating.Parse[oidcContext](
+	"oidc.html", oidcHTML, nil,
+
+	templating.Title("Login"),
+	templating.Assets(assets.AssetsUser),
+)
+
+type oidcContext struct {
+	templating.RuntimeFlags
+
+	Next string
+}
+
+// oidcCallback handles the redirect back from the identity provider.
+//
+// Because the redirect happens cross-site, the browser would not send the new session cookie along with a plain http redirect.
+// Instead, a page is rendered that sends the user on.
+func (auth *Auth) oidcCallback(ctx context.Context) http.Handler {
+	tpl := oidcTemplate.Prepare(auth.dependencies.Templating)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next, err := auth.completeOIDC(w, r)
+		if err != nil {
+			wdlog.Of(ctx).Warn("single sign-on failed", slog.Any("error", err))
+			httpx.Response{
+				ContentType: "text/plain",
+				StatusCode:  http.StatusForbidden,
+				Body:        []byte("login via single sign-on failed"),
+			}.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := tpl.Template().Execute(w, tpl.Context(r, oidcContext{Next: next})); err != nil {
+			tpl.LogTemplateError(r, err)
+		}
+	})
+}
+
+// completeOIDC validates the response of the identity provider, and logs the user in.
+// It returns the url to send the user on to.
+func (auth *Auth) completeOIDC(w http.ResponseWriter, r *http.Request) (next string, err error) {
+	flow := auth.flow(r)
+	state, _ := flow.Values[oidcStateKey].(string)
+	nonce, _ := flow.Values[oidcNonceKey].(string)
+	verifier, _ := flow.Values[oidcVerifierKey].(string)
+	next, _ = flow.Values[oidcNextKey].(string)
+
+	// the flow can only be completed once
+	flow.Options.MaxAge = -1
+	if err := flow.Save(r, w); err != nil {
+		return "", fmt.Errorf("failed to save oidc session: %w", err)
+	}
+
+	query := r.URL.Query()
+	if state == "" || query.Get("state") != state {
+		return "", errOIDCState
+	}
+	if code := query.Get("error"); code != "" {
+		return "", fmt.Errorf("%w: %q: %s", errOIDCProvider, code, query.Get("error_description"))
+	}
+
+	provider, err := auth.OIDC(r.Context())
+	if err != nil {
+		return "", err
+	}
+
+	identity, err := provider.Exchange(r.Context(), query.Get("code"), nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := auth.LoginSSO(r.Context(), identity)
+	if err != nil {
+		return "", fmt.Errorf("failed to login %q: %w", identity.Username, err)
+	}
+
+	method := LoginSSO
+	if identity.MFA {
+		method = LoginSSOMFA
+	}
+	if err := auth.Login(w, r, user, method); err != nil {
+		return "", err
+	}
+
+	if next == "" || next[0] != '/' {
+		next = "/"
+	}
+	return next, nil
+}
+
+// ssoURL returns the url to start a single sign-on that returns to next.
+// If single sign-on is not enabled, returns the empty string.
+func (auth *Auth) ssoURL(next string) string {
+	if !component.GetStill(auth).Config.OIDC.Enabled() {
+		return ""
+	}
+	if next == "" {
+		return "/auth/oidc/login"
+	}
+	return "/auth/oidc/login?next=" + url.QueryEscape(next)
+}
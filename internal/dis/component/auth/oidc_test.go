@@ -0,0 +1,191 @@
+//spellchecker:words auth
+package auth_test
+
+//spellchecker:words context crypto ecdsa elliptic rand encoding json http httptest slices testing time github jose wisski distillery internal config validators component auth
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/config"
+	"github.com/FAU-CDI/wisski-distillery/internal/config/validators"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// mockIssuer is a minimal OpenID Connect identity provider.
+// It issues an id token with the given claims for every code.
+type mockIssuer struct {
+	*httptest.Server
+
+	key    *ecdsa.PrivateKey
+	claims map[string]any
+	nonce  string
+}
+
+func newMockIssuer(t *testing.T, claims map[string]any) *mockIssuer {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := &mockIssuer{key: key, claims: claims}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                issuer.URL,
+			"authorization_endpoint":                issuer.URL + "/authorize",
+			"token_endpoint":                        issuer.URL + "/token",
+			"jwks_uri":                              issuer.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{string(jose.ES256)},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{
+			Keys: []jose.JSONWebKey{{Key: &issuer.key.PublicKey, KeyID: "test", Algorithm: string(jose.ES256), Use: "sig"}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != "code" || r.PostForm.Get("code_verifier") == "" {
+			http.Error(w, "invalid grant", http.StatusBadRequest)
+			return
+		}
+
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: issuer.key}, (&jose.SignerOptions{}).WithHeader("kid", "test"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		now := time.Now()
+		token, err := jwt.Signed(signer).Claims(jwt.Claims{
+			Issuer:   issuer.URL,
+			Subject:  "alice-id",
+			Audience: jwt.Audience{"distillery"},
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(now.Add(time.Minute)),
+		}).Claims(map[string]any{"nonce": issuer.nonce}).Claims(issuer.claims).Serialize()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     token,
+		})
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+
+	return issuer
+}
+
+func (issuer *mockIssuer) provider(t *testing.T) *auth.OIDCProvider {
+	t.Helper()
+
+	u, err := url.Parse(issuer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := auth.NewOIDCProvider(t.Context(), config.OIDCConfig{
+		Issuer:        (*validators.URL)(u),
+		ClientID:      "distillery",
+		ClientSecret:  "secret",
+		UsernameClaim: "preferred_username",
+		GroupsClaim:   "groups",
+	}, "https://panel.example.com/auth/oidc/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestOIDCProvider_Exchange(t *testing.T) {
+	t.Parallel()
+
+	issuer := newMockIssuer(t, map[string]any{
+		"preferred_username": "alice",
+		"groups":             []string{"staff", "wisski-admins"},
+	})
+	issuer.nonce = "nonce"
+	provider := issuer.provider(t)
+
+	// the user is sent to the issuer
+	dest, err := url.Parse(provider.AuthCodeURL("state", "nonce", "verifier"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dest.Query(); got.Get("state") != "state" || got.Get("nonce") != "nonce" || got.Get("code_challenge_method") != "S256" {
+		t.Errorf("AuthCodeURL() has unexpected query %v", got)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		identity, err := provider.Exchange(context.Background(), "code", "nonce", "verifier")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if identity.Subject != "alice-id" || identity.Issuer != issuer.URL || identity.Username != "alice" || !slices.Equal(identity.Groups, []string{"staff", "wisski-admins"}) {
+			t.Errorf("Exchange() = %v", identity)
+		}
+	})
+
+	t.Run("wrong nonce", func(t *testing.T) {
+		if _, err := provider.Exchange(context.Background(), "code", "other", "verifier"); err == nil {
+			t.Error("Exchange() did not return an error")
+		}
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		if _, err := provider.Exchange(context.Background(), "other", "nonce", "verifier"); err == nil {
+			t.Error("Exchange() did not return an error")
+		}
+	})
+}
+
+func TestOIDCProvider_Identity(t *testing.T) {
+	t.Parallel()
+
+	provider := newMockIssuer(t, nil).provider(t)
+
+	for _, tt := range []struct {
+		name    string
+		claims  map[string]any
+		want    auth.OIDCIdentity
+		wantErr bool
+	}{
+		{"no groups", map[string]any{"iss": "idp", "sub": "1", "preferred_username": "bob"}, auth.OIDCIdentity{Username: "bob"}, false},
+		{"single group", map[string]any{"iss": "idp", "sub": "1", "preferred_username": "bob", "groups": "staff"}, auth.OIDCIdentity{Username: "bob", Groups: []string{"staff"}}, false},
+		{"mfa", map[string]any{"iss": "idp", "sub": "1", "preferred_username": "bob", "amr": []any{"pwd", "mfa"}}, auth.OIDCIdentity{Username: "bob", MFA: true}, false},
+		{"no subject", map[string]any{"iss": "idp", "preferred_username": "bob"}, auth.OIDCIdentity{}, true},
+		{"no username", map[string]any{"iss": "idp", "sub": "1", "groups": []any{"staff"}}, auth.OIDCIdentity{}, true},
+		{"invalid groups", map[string]any{"iss": "idp", "sub": "1", "preferred_username": "bob", "groups": []any{1}}, auth.OIDCIdentity{}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := provider.Identity(tt.claims)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Identity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Link() != (auth.OIDCIdentity{Issuer: "idp", Subject: "1"}).Link() {
+				t.Errorf("Identity().Link() = %q", got.Link())
+			}
+			if !tt.wantErr && (got.Username != tt.want.Username || got.MFA != tt.want.MFA || !slices.Equal(got.Groups, tt.want.Groups)) {
+				t.Errorf("Identity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
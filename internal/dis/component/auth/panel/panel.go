@@ -1,7 +1,7 @@
 //spellchecker:words panel
 package panel
 
-//spellchecker:words context http github wisski distillery internal component auth next policy scopes tokens instances server handling templating sshkeys models julienschmidt httprouter pkglib httpx form
+//spellchecker:words context http github wisski distillery internal component auth next passkeys policy scopes tokens instances provision requests server handling templating sshkeys models julienschmidt httprouter pkglib httpx form
 import (
 	"context"
 	"net/http"
@@ -9,10 +9,12 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/next"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/passkeys"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/policy"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/scopes"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/tokens"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/provision/requests"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/handling"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/ssh2"
@@ -32,7 +34,9 @@ type UserPanel struct {
 
 		Policy    *policy.Policy
 		Tokens    *tokens.Tokens
+		Passkeys  *passkeys.Passkeys
 		Instances *instances.Instances
+		Requests  *requests.Requests
 		Next      *next.Next
 		Keys      *sshkeys.SSHKeys
 		SSH2      *ssh2.SSH2
@@ -75,6 +79,12 @@ var (
 	menuTokens    = component.MenuItem{Title: "Tokens", Path: "/user/tokens/"}
 	menuTokensAdd = component.MenuItem{Title: "Add New Token", Path: "/user/tokens/add/"}
 
+	menuRequests    = component.MenuItem{Title: "Instance Requests", Path: "/user/requests/"}
+	menuRequestsAdd = component.MenuItem{Title: "Request New Instance", Path: "/user/requests/add/"}
+
+	menuPasskeys    = component.MenuItem{Title: "Passkeys", Path: "/user/passkeys/"}
+	menuPasskeysAdd = component.MenuItem{Title: "Add New Passkey", Path: "/user/passkeys/add/"}
+
 	menuTOTPAction  = component.DummyMenuItem()
 	menuTOTPDisable = component.MenuItem{Title: "Disable Passcode (TOTP)", Path: "/user/totp/disable/"}
 	menuTOTPEnable  = component.MenuItem{Title: "Enable Passcode (TOTP)", Path: "/user/totp/enable/"}
@@ -112,6 +122,22 @@ func (panel *UserPanel) HandleRoute(ctx context.Context, route string) (http.Han
 		router.Handler(http.MethodPost, route+"totp/disable", totpdisable)
 	}
 
+	{
+		passkeys := panel.passkeysRoute(ctx)
+		router.Handler(http.MethodGet, route+"passkeys", passkeys)
+	}
+
+	{
+		passkeysAdd := panel.passkeysAddRoute(ctx)
+		router.Handler(http.MethodGet, route+"passkeys/add", passkeysAdd)
+		router.Handler(http.MethodPost, route+"passkeys/add", passkeysAdd)
+	}
+
+	{
+		passkeysDelete := panel.passkeysDeleteRoute(ctx)
+		router.Handler(http.MethodPost, route+"passkeys/delete", passkeysDelete)
+	}
+
 	{
 		ssh := panel.sshRoute(ctx)
 		router.Handler(http.MethodGet, route+"ssh", ssh)
@@ -128,6 +154,22 @@ func (panel *UserPanel) HandleRoute(ctx context.Context, route string) (http.Han
 		router.Handler(http.MethodPost, route+"ssh/delete", sshDelete)
 	}
 
+	{
+		requests := panel.requestsRoute(ctx)
+		router.Handler(http.MethodGet, route+"requests", requests)
+	}
+
+	{
+		requestsAdd := panel.requestsAddRoute(ctx)
+		router.Handler(http.MethodGet, route+"requests/add", requestsAdd)
+		router.Handler(http.MethodPost, route+"requests/add", requestsAdd)
+	}
+
+	{
+		requestsWithdraw := panel.requestsWithdrawRoute(ctx)
+		router.Handler(http.MethodPost, route+"requests/withdraw", requestsWithdraw)
+	}
+
 	{
 		tokens := panel.tokensRoute(ctx)
 		router.Handler(http.MethodGet, route+"tokens", tokens)
@@ -0,0 +1,256 @@
+//spellchecker:words panel
+package panel
+
+//spellchecker:words context encoding json errors http strconv github wisski distillery internal component auth server assets templating models wdlog webauthn protocol pkglib httpx embed
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/assets"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+	"github.com/go-webauthn/webauthn/protocol"
+	"go.tkw01536.de/pkglib/httpx"
+
+	_ "embed"
+)
+
+//go:embed "templates/passkeys.html"
+var passkeysHTML []byte
+var passkeysTemplate = templating.Parse[PasskeysTemplateContext](
+	"passkeys.html", passkeysHTML, nil,
+
+	templating.Title("Passkeys"),
+	templating.Assets(assets.AssetsUser),
+)
+
+type PasskeysTemplateContext struct {
+	templating.RuntimeFlags
+
+	Passkeys []models.Passkey
+}
+
+func (panel *UserPanel) passkeysRoute(context.Context) http.Handler {
+	tpl := passkeysTemplate.Prepare(
+		panel.dependencies.Templating,
+		templating.Crumbs(
+			menuUser,
+			menuPasskeys,
+		),
+		templating.Actions(
+			menuPasskeysAdd,
+		),
+	)
+
+	return tpl.HTMLHandler(panel.dependencies.Handling, func(r *http.Request) (pc PasskeysTemplateContext, err error) {
+		user, err := panel.dependencies.Auth.UserOfSession(r)
+		if err != nil {
+			return pc, fmt.Errorf("failed to get user of session: %w", err)
+		}
+		if user == nil {
+			return pc, errNoUserInSession
+		}
+
+		pc.Passkeys, err = panel.dependencies.Passkeys.Passkeys(r.Context(), user.User.User)
+		if err != nil {
+			return pc, fmt.Errorf("failed to get passkeys: %w", err)
+		}
+		return pc, nil
+	})
+}
+
+func (panel *UserPanel) passkeysDeleteRoute(ctx context.Context) http.Handler {
+	logger := wdlog.Of(ctx)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyFormBytes)
+		if err := r.ParseForm(); err != nil {
+			logger.Error(
+				"failed to parse form",
+				"error", err,
+				"action", "delete passkey",
+			)
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+		user, err := panel.dependencies.Auth.UserOfSession(r)
+		if err != nil || user == nil {
+			logger.Error(
+				"failed to get current user",
+				"error", err,
+				"action", "delete passkey",
+			)
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PostFormValue("id"), 10, 0)
+		if err != nil {
+			logger.Error(
+				"failed to get passkey",
+				"error", err,
+				"action", "delete passkey",
+			)
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		if err := panel.dependencies.Passkeys.Remove(r.Context(), user.User.User, uint(id)); err != nil {
+			logger.Error(
+				"failed to delete passkey",
+				"error", err,
+				"action", "delete passkey",
+			)
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		http.Redirect(w, r, string(menuPasskeys.Path), http.StatusSeeOther)
+	})
+}
+
+//go:embed "templates/passkeys_add.html"
+var passkeysAddHTML []byte
+var passkeysAddTemplate = templating.Parse[PasskeysAddTemplateContext](
+	"passkeys_add.html", passkeysAddHTML, nil,
+
+	templating.Title("Add Passkey"),
+	templating.Assets(assets.AssetsUser),
+)
+
+type PasskeysAddTemplateContext struct {
+	templating.RuntimeFlags
+
+	Options     string // json-encoded options to pass to the browser
+	HasPassword bool   // user has to confirm their password
+}
+
+// addPasskeyRequest is the body sent by the browser to complete adding a passkey.
+type addPasskeyRequest struct {
+	Name       string          `json:"name"`
+	Password   string          `json:"password"`
+	Credential json.RawMessage `json:"credential"`
+}
+
+var (
+	errAddPasskey       = errors.New("unable to add passkey")
+	errPasskeyPassword  = errors.New("wrong password")
+	errPasskeyBadFormat = errors.New("invalid passkey response")
+)
+
+// passkeysAddRoute adds a new passkey.
+//
+// GET requests render a page that asks the browser to create a new passkey.
+// The browser then POSTs the response back to the same url.
+func (panel *UserPanel) passkeysAddRoute(ctx context.Context) http.Handler {
+	tpl := passkeysAddTemplate.Prepare(
+		panel.dependencies.Templating,
+		templating.Crumbs(
+			menuUser,
+			menuPasskeys,
+			menuPasskeysAdd,
+		),
+	)
+	logger := wdlog.Of(ctx)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := panel.dependencies.Auth.UserOfSession(r)
+		if err != nil || user == nil {
+			logger.Error(
+				"failed to get current user",
+				"error", err,
+				"action", "add passkey",
+			)
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			if err := panel.addPasskey(w, r, user); err != nil {
+				logger.Warn(
+					"failed to add passkey",
+					"error", err,
+					"user", user.User.User,
+				)
+
+				msg := errAddPasskey.Error()
+				if errors.Is(err, errPasskeyPassword) {
+					msg = errPasskeyPassword.Error()
+				}
+				httpx.Response{
+					ContentType: "text/plain",
+					StatusCode:  http.StatusBadRequest,
+					Body:        []byte(msg),
+				}.ServeHTTP(w, r)
+				return
+			}
+
+			body, _ := json.Marshal(map[string]string{"next": string(menuPasskeys.Path)}) // cannot fail
+			httpx.Response{
+				ContentType: "application/json",
+				Body:        body,
+			}.ServeHTTP(w, r)
+			return
+		}
+
+		creation, err := panel.dependencies.Auth.BeginPasskeyRegistration(w, r, user)
+		if err != nil {
+			logger.Error(
+				"failed to begin passkey registration",
+				"error", err,
+				"action", "add passkey",
+			)
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+		options, err := json.Marshal(creation)
+		if err != nil {
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := tpl.Template().Execute(w, tpl.Context(r, PasskeysAddTemplateContext{
+			Options:     string(options),
+			HasPassword: len(user.PasswordHash) > 0,
+		})); err != nil {
+			tpl.LogTemplateError(r, err)
+		}
+	})
+}
+
+// addPasskey validates the response of the browser, and stores the new passkey.
+func (panel *UserPanel) addPasskey(w http.ResponseWriter, r *http.Request, user *auth.AuthUser) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyFormBytes)
+
+	var request addPasskeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return fmt.Errorf("%w: %w", errPasskeyBadFormat, err)
+	}
+
+	// users with a password have to confirm it
+	if len(user.PasswordHash) > 0 {
+		if err := user.CheckPassword(r.Context(), []byte(request.Password)); err != nil {
+			return fmt.Errorf("%w: %w", errPasskeyPassword, err)
+		}
+	}
+
+	response, err := protocol.ParseCredentialCreationResponseBytes(request.Credential)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPasskeyBadFormat, err)
+	}
+
+	if request.Name == "" {
+		request.Name = "Passkey"
+	}
+	if _, err := panel.dependencies.Auth.FinishPasskeyRegistration(w, r, user, request.Name, response); err != nil {
+		return fmt.Errorf("failed to register passkey: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+//spellchecker:words panel
+package panel
+
+//spellchecker:words context errors http maps slices strconv strings github wisski distillery internal component auth server assets templating models ingredient barrel manager wdlog pkglib httpx form field embed
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/assets"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/barrel/manager"
+	"go.tkw01536.de/pkglib/httpx"
+	"go.tkw01536.de/pkglib/httpx/form"
+	"go.tkw01536.de/pkglib/httpx/form/field"
+
+	_ "embed"
+)
+
+//go:embed "templates/requests.html"
+var requestsHTML []byte
+var requestsTemplate = templating.Parse[RequestsTemplateContext](
+	"requests.html", requestsHTML, nil,
+
+	templating.Title("Instance Requests"),
+	templating.Assets(assets.AssetsUser),
+)
+
+type RequestsTemplateContext struct {
+	templating.RuntimeFlags
+
+	Requests []models.InstanceRequest
+}
+
+func (panel *UserPanel) requestsRoute(context.Context) http.Handler {
+	tpl := requestsTemplate.Prepare(
+		panel.dependencies.Templating,
+		templating.Crumbs(
+			menuUser,
+			menuRequests,
+		),
+		templating.Actions(
+			menuRequestsAdd,
+		),
+	)
+
+	return tpl.HTMLHandler(panel.dependencies.Handling, func(r *http.Request) (rc RequestsTemplateContext, err error) {
+		user, err := panel.dependencies.Auth.UserOfSession(r)
+		if err != nil {
+			return rc, fmt.Errorf("failed to get user of session: %w", err)
+		}
+		if user == nil {
+			return rc, errNoUserInSession
+		}
+
+		rc.Requests, err = panel.dependencies.Requests.User(r.Context(), user.User.User)
+		if err != nil {
+			return rc, fmt.Errorf("failed to get requests: %w", err)
+		}
+		return rc, nil
+	})
+}
+
+func (panel *UserPanel) requestsWithdrawRoute(ctx context.Context) http.Handler {
+	logger := wdlog.Of(ctx)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyFormBytes)
+		if err := r.ParseForm(); err != nil {
+			logger.Error(
+				"failed to parse form",
+				"error", err,
+				"action", "withdraw request",
+			)
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+		user, err := panel.dependencies.Auth.UserOfSession(r)
+		if err != nil || user == nil {
+			logger.Error(
+				"failed to get current user",
+				"error", err,
+				"action", "withdraw request",
+			)
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		id, err := strconv.ParseUint(r.PostFormValue("id"), 10, 0)
+		if err != nil {
+			logger.Error(
+				"failed to get request",
+				"error", err,
+				"action", "withdraw request",
+			)
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		if err := panel.dependencies.Requests.Withdraw(r.Context(), user.User.User, uint(id)); err != nil {
+			logger.Error(
+				"failed to withdraw request",
+				"error", err,
+				"action", "withdraw request",
+			)
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		http.Redirect(w, r, string(menuRequests.Path), http.StatusSeeOther)
+	})
+}
+
+//go:embed "templates/requests_add.html"
+var requestsAddHTML []byte
+var requestsAddTemplate = templating.ParseForm(
+	"requests_add.html", requestsAddHTML, form.FormTemplate,
+	templating.Title("Request Instance"),
+	templating.Assets(assets.AssetsUser),
+)
+
+type addRequestResult struct {
+	User    *auth.AuthUser
+	Slug    string
+	Flavor  string
+	Purpose string
+}
+
+var errSubmitRequest = errors.New("unable to request instance")
+
+func (panel *UserPanel) requestsAddRoute(context.Context) http.Handler {
+	tpl := requestsAddTemplate.Prepare(
+		panel.dependencies.Templating,
+		templating.Crumbs(
+			menuUser,
+			menuRequests,
+			menuRequestsAdd,
+		),
+	)
+
+	domain := component.GetStill(panel).Config.HTTP.PrimaryDomain
+	flavors := slices.Sorted(maps.Keys(manager.Profiles()))
+
+	return &form.Form[addRequestResult]{
+		Fields: []field.Field{
+			{Name: "slug", Type: field.Text, Label: "Slug (the instance will be reachable at slug." + domain + ")"},
+			{Name: "flavor", Type: field.Text, Label: "Flavor (one of " + strings.Join(flavors, ", ") + "; leave empty for " + manager.DefaultProfile() + ")"},
+			{Name: "purpose", Type: field.Textarea, Label: "Purpose (what project is the instance for, and who will use it?)"},
+		},
+		FieldTemplate: assets.PureCSSFieldTemplate,
+
+		Template:         tpl.Template(),
+		TemplateContext:  templating.FormTemplateContext(tpl),
+		LogTemplateError: tpl.LogTemplateError,
+
+		Validate: func(r *http.Request, values map[string]string) (ar addRequestResult, err error) {
+			ar.User, err = panel.dependencies.Auth.UserOfSession(r)
+			if err != nil || ar.User == nil {
+				return ar, errInvalidUser
+			}
+
+			ar.Slug = strings.TrimSpace(values["slug"])
+			ar.Flavor = strings.TrimSpace(values["flavor"])
+			ar.Purpose = values["purpose"]
+			return ar, nil
+		},
+
+		Success: func(ar addRequestResult, values map[string]string, w http.ResponseWriter, r *http.Request) error {
+			if _, err := panel.dependencies.Requests.Submit(r.Context(), ar.User.User.User, ar.Slug, ar.Flavor, ar.Purpose); err != nil {
+				return fmt.Errorf("%w: %w", errSubmitRequest, err)
+			}
+			http.Redirect(w, r, string(menuRequests.Path), http.StatusSeeOther)
+			return nil
+		},
+	}
+}
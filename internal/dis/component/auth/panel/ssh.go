@@ -98,6 +98,9 @@ var (
 	errKeyParse    = errors.New("unable to parse ssh key")
 	errAddKey      = errors.New("unable to add key")
 	errAddToken    = errors.New("unable to add token")
+
+	errTokenExpires  = errors.New("expiry must be a non-negative number of days")
+	errTokenInstance = errors.New("unknown instance")
 )
 
 func (panel *UserPanel) sshDeleteRoute(ctx context.Context) http.Handler {
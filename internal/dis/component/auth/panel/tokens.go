@@ -1,13 +1,16 @@
 //spellchecker:words panel
 package panel
 
-//spellchecker:words context errors html template http github wisski distillery internal component auth server assets templating models wdlog pkglib httpx form field embed
+//spellchecker:words context errors html template http strconv strings time github wisski distillery internal component auth server assets templating models wdlog pkglib httpx form field embed
 import (
 	"context"
 	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
@@ -134,6 +137,8 @@ type addTokenResult struct {
 	User        *auth.AuthUser
 	Description string
 	Scopes      []string
+	Instances   []string
+	Expires     *time.Time
 }
 
 //go:embed "templates/token_created.html"
@@ -173,6 +178,8 @@ func (panel *UserPanel) tokensAddRoute(context.Context) http.Handler {
 	return &form.Form[addTokenResult]{
 		Fields: []field.Field{
 			{Name: "description", Type: field.Text, Label: "Description"},
+			{Name: "expires", Type: field.Text, Label: "Expires after (days, leave empty to never expire)"},
+			{Name: "instances", Type: field.Text, Label: "Restrict to instances (comma-separated slugs, leave empty for all; restricted tokens cannot perform actions beyond these instances)"},
 		},
 		FieldTemplate: assets.PureCSSFieldTemplate,
 
@@ -193,12 +200,36 @@ func (panel *UserPanel) tokensAddRoute(context.Context) http.Handler {
 
 			at.Scopes = nil
 
+			if expires := strings.TrimSpace(values["expires"]); expires != "" {
+				days, err := strconv.ParseUint(expires, 10, 16)
+				if err != nil {
+					return at, errTokenExpires
+				}
+				if days > 0 {
+					deadline := time.Now().AddDate(0, 0, int(days))
+					at.Expires = &deadline
+				}
+			}
+
+			for slug := range strings.SplitSeq(values["instances"], ",") {
+				slug = strings.TrimSpace(slug)
+				if slug == "" {
+					continue
+				}
+
+				ok, err := panel.dependencies.Instances.Has(r.Context(), slug)
+				if err != nil || !ok {
+					return at, fmt.Errorf("%w: %q", errTokenInstance, slug)
+				}
+				at.Instances = append(at.Instances, slug)
+			}
+
 			return at, nil
 		},
 
 		Success: func(at addTokenResult, values map[string]string, w http.ResponseWriter, r *http.Request) error {
 			// add the key to the user
-			tok, err := panel.dependencies.Tokens.Add(r.Context(), at.User.User.User, at.Description, at.Scopes)
+			tok, err := panel.dependencies.Tokens.Add(r.Context(), at.User.User.User, at.Description, at.Scopes, at.Instances, at.Expires)
 			if err != nil {
 				return errAddToken
 			}
@@ -33,8 +33,10 @@ type userContext struct {
 	templating.RuntimeFlags
 	*auth.AuthUser
 
-	ShowAdminURLs bool
-	Grants        []GrantWithURL
+	ShowAdminURLs         bool
+	HasSecondFactor       bool // the user has a second factor enabled
+	PresentedSecondFactor bool // the user logged in with a second factor
+	Grants                []GrantWithURL
 }
 
 type GrantWithURL struct {
@@ -51,7 +53,9 @@ func (panel *UserPanel) routeUser(context.Context) http.Handler {
 	actions := []component.MenuItem{
 		menuChangePassword,
 		menuTOTPAction,
+		menuPasskeys,
 		menuSSH,
+		menuRequests,
 	}
 	if component.GetStill(panel).Config.HTTP.API.Value {
 		actions = append(actions, menuTokens)
@@ -75,6 +79,15 @@ func (panel *UserPanel) routeUser(context.Context) http.Handler {
 			return uc, nil, errNoUserInSession
 		}
 
+		uc.HasSecondFactor, err = uc.AuthUser.HasSecondFactor(r.Context())
+		if err != nil {
+			return uc, nil, fmt.Errorf("failed to check second factor: %w", err)
+		}
+		uc.PresentedSecondFactor, err = panel.dependencies.Auth.PresentedSecondFactor(r)
+		if err != nil {
+			return uc, nil, fmt.Errorf("failed to check second factor: %w", err)
+		}
+
 		uc.ShowAdminURLs = panel.dependencies.Auth.CheckScope("", scopes.ScopeUserAdmin, r) == nil
 
 		// replace the totp action in the menu
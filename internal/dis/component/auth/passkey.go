@@ -0,0 +1,285 @@
+//spellchecker:words auth
+package auth
+
+//spellchecker:words context encoding json errors html template slog http github go-webauthn protocol webauthn wisski distillery internal component server assets templating models wdlog pkglib httpx embed
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/assets"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.tkw01536.de/pkglib/httpx"
+
+	_ "embed"
+)
+
+// HasSecondFactor checks if the user has a second factor enabled.
+// A second factor is either TOTP or at least one passkey.
+func (au *AuthUser) HasSecondFactor(ctx context.Context) (bool, error) {
+	if au.IsTOTPEnabled() {
+		return true, nil
+	}
+	has, err := au.auth.dependencies.Passkeys.Has(ctx, au.User.User)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for passkeys: %w", err)
+	}
+	return has, nil
+}
+
+// keys within the session to hold state of passkey ceremonies.
+const (
+	passkeyUserKey    = "p" // user that has entered the correct password, but still has to use a passkey
+	passkeySessionKey = "w" // webauthn session data of an ongoing ceremony
+)
+
+// startCeremony stores the data of a new passkey ceremony in the session.
+func (auth *Auth) startCeremony(w http.ResponseWriter, r *http.Request, data *webauthn.SessionData) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session data: %w", err)
+	}
+
+	sess := auth.session(r)
+	sess.Values[passkeySessionKey] = encoded
+	if err := sess.Save(r, w); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+var errNoCeremony = errors.New("no passkey ceremony in progress")
+
+// endCeremony removes the data of the current passkey ceremony from the session, and returns it.
+func (auth *Auth) endCeremony(w http.ResponseWriter, r *http.Request) (data webauthn.SessionData, err error) {
+	sess := auth.session(r)
+	encoded, ok := sess.Values[passkeySessionKey].([]byte)
+	if !ok {
+		return data, errNoCeremony
+	}
+
+	delete(sess.Values, passkeySessionKey)
+	if err := sess.Save(r, w); err != nil {
+		return data, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return data, fmt.Errorf("failed to unmarshal session data: %w", err)
+	}
+	return data, nil
+}
+
+// BeginPasskeyRegistration starts registering a new passkey for the given user.
+// It returns the options to pass to the browser.
+func (auth *Auth) BeginPasskeyRegistration(w http.ResponseWriter, r *http.Request, user *AuthUser) (*protocol.CredentialCreation, error) {
+	rp, err := auth.dependencies.Passkeys.WebAuthn()
+	if err != nil {
+		return nil, err
+	}
+	wu, err := auth.dependencies.Passkeys.User(r.Context(), &user.User)
+	if err != nil {
+		return nil, err
+	}
+
+	creation, data, err := rp.BeginRegistration(wu, webauthn.WithExclusions(webauthn.Credentials(wu.WebAuthnCredentials()).CredentialDescriptors()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin registration: %w", err)
+	}
+	if err := auth.startCeremony(w, r, data); err != nil {
+		return nil, err
+	}
+	return creation, nil
+}
+
+// FinishPasskeyRegistration completes registering a passkey for the given user, and stores it under the given name.
+func (auth *Auth) FinishPasskeyRegistration(w http.ResponseWriter, r *http.Request, user *AuthUser, name string, response *protocol.ParsedCredentialCreationData) (*models.Passkey, error) {
+	data, err := auth.endCeremony(w, r)
+	if err != nil {
+		return nil, err
+	}
+
+	rp, err := auth.dependencies.Passkeys.WebAuthn()
+	if err != nil {
+		return nil, err
+	}
+	wu, err := auth.dependencies.Passkeys.User(r.Context(), &user.User)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := rp.CreateCredential(wu, data, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify passkey: %w", err)
+	}
+	return auth.dependencies.Passkeys.Add(r.Context(), user.User.User, name, credential)
+}
+
+// pendingUser returns the user that has entered their password, but still has to use a passkey.
+func (auth *Auth) pendingUser(r *http.Request) (*AuthUser, error) {
+	name, ok := auth.session(r).Values[passkeyUserKey].(string)
+	if !ok || name == "" {
+		return nil, nil
+	}
+	return auth.checkUser(r.Context(), name)
+}
+
+// setPendingUser marks user as having entered their password, but still having to use a passkey.
+func (auth *Auth) setPendingUser(w http.ResponseWriter, r *http.Request, user *AuthUser) error {
+	sess := auth.session(r)
+	sess.Values[passkeyUserKey] = user.User.User
+	if err := sess.Save(r, w); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+//go:embed "passkey.html"
+var passkeyHTML []byte
+var passkeyTemplate = templating.Parse[passkeyContext](
+	"passkey.html", passkeyHTML, nil,
+
+	templating.Title("Use Passkey"),
+	templating.Assets(assets.AssetsUser),
+)
+
+type passkeyContext struct {
+	templating.RuntimeFlags
+
+	Options string // json-encoded options to pass to the browser
+	Next    string
+}
+
+var errPasskeyFailed = errors.New("passkey login failed")
+
+// authPasskey implements the second step of a login using a passkey.
+//
+// GET requests render a page that asks the browser to use a passkey.
+// The browser then POSTs the response back to the same url.
+func (auth *Auth) authPasskey(ctx context.Context) http.Handler {
+	tpl := passkeyTemplate.Prepare(
+		auth.dependencies.Templating,
+		func(flags templating.Flags, r *http.Request) templating.Flags {
+			flags.Crumbs = []component.MenuItem{
+				{Title: "Login", Path: template.URL(r.URL.RequestURI())}, // #nosec G203 -- request URI assumed to be safe
+			}
+			return flags
+		},
+	)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next := r.URL.Query().Get("next")
+		if next == "" || next[0] != '/' {
+			next = "/"
+		}
+
+		user, err := auth.pendingUser(r)
+		if err != nil {
+			wdlog.Of(ctx).Error("failed to get pending user", slog.Any("error", err))
+			httpx.TextInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+		if user == nil {
+			http.Redirect(w, r, "/auth/login?next="+url.QueryEscape(next), http.StatusSeeOther)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			if err := auth.finishPasskeyLogin(w, r, user); err != nil {
+				wdlog.Of(ctx).Warn("passkey login failed", slog.String("user", user.User.User), slog.Any("error", err))
+				httpx.Response{
+					ContentType: "text/plain",
+					StatusCode:  http.StatusForbidden,
+					Body:        []byte(errPasskeyFailed.Error()),
+				}.ServeHTTP(w, r)
+				return
+			}
+
+			body, _ := json.Marshal(map[string]string{"next": next}) // cannot fail
+			httpx.Response{
+				ContentType: "application/json",
+				Body:        body,
+			}.ServeHTTP(w, r)
+			return
+		}
+
+		options, err := auth.beginPasskeyLogin(w, r, user)
+		if err != nil {
+			wdlog.Of(ctx).Error("failed to begin passkey login", slog.Any("error", err))
+			httpx.TextInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := tpl.Template().Execute(w, tpl.Context(r, passkeyContext{Options: options, Next: next})); err != nil {
+			tpl.LogTemplateError(r, err)
+		}
+	})
+}
+
+// beginPasskeyLogin starts a login ceremony for user, and returns the json-encoded options to pass to the browser.
+func (auth *Auth) beginPasskeyLogin(w http.ResponseWriter, r *http.Request, user *AuthUser) (string, error) {
+	rp, err := auth.dependencies.Passkeys.WebAuthn()
+	if err != nil {
+		return "", err
+	}
+	wu, err := auth.dependencies.Passkeys.User(r.Context(), &user.User)
+	if err != nil {
+		return "", err
+	}
+
+	assertion, data, err := rp.BeginLogin(wu)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin login: %w", err)
+	}
+	if err := auth.startCeremony(w, r, data); err != nil {
+		return "", err
+	}
+
+	options, err := json.Marshal(assertion)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal options: %w", err)
+	}
+	return string(options), nil
+}
+
+// finishPasskeyLogin validates the response of the browser, and logs in user on success.
+func (auth *Auth) finishPasskeyLogin(w http.ResponseWriter, r *http.Request, user *AuthUser) error {
+	data, err := auth.endCeremony(w, r)
+	if err != nil {
+		return err
+	}
+
+	rp, err := auth.dependencies.Passkeys.WebAuthn()
+	if err != nil {
+		return err
+	}
+	wu, err := auth.dependencies.Passkeys.User(r.Context(), &user.User)
+	if err != nil {
+		return err
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPasskeyBodyBytes)
+	credential, err := rp.FinishLogin(wu, data, r)
+	if err != nil {
+		return fmt.Errorf("failed to verify passkey: %w", err)
+	}
+	if err := auth.dependencies.Passkeys.Use(r.Context(), user.User.User, credential); err != nil {
+		return err
+	}
+
+	return auth.Login(w, r, user, LoginPasskey)
+}
+
+// maxPasskeyBodyBytes is the maximum size of a response to a passkey ceremony.
+const maxPasskeyBodyBytes = 64 * 1024
@@ -0,0 +1,228 @@
+// Package passkeys stores WebAuthn credentials of distillery users.
+//
+//spellchecker:words passkeys
+package passkeys
+
+//spellchecker:words context errors time github webauthn protocol wisski distillery internal component models gorm
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/gorm"
+)
+
+// Passkeys stores the WebAuthn credentials of distillery users.
+type Passkeys struct {
+	component.Base
+
+	dependencies struct {
+		SQL *sql.SQL
+	}
+}
+
+var (
+	_ component.UserDeleteHook = (*Passkeys)(nil)
+	_ component.Table          = (*Passkeys)(nil)
+)
+
+func (pk *Passkeys) TableInfo() component.TableInfo {
+	return component.TableInfo{
+		Model: models.Passkey{},
+	}
+}
+
+func (pk *Passkeys) table(ctx context.Context) (*gorm.DB, error) {
+	conn, err := pk.dependencies.SQL.OpenTable(ctx, pk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table: %w", err)
+	}
+	return conn, nil
+}
+
+func (pk *Passkeys) OnUserDelete(ctx context.Context, user *models.User) error {
+	table, err := pk.table(ctx)
+	if err != nil {
+		return err
+	}
+	return table.Delete(&models.Passkey{}, &models.Passkey{User: user.User}).Error
+}
+
+// timeout is the time a user has to complete a WebAuthn ceremony.
+const timeout = 5 * time.Minute
+
+// WebAuthn returns the relying party used for WebAuthn ceremonies.
+// Passkeys are bound to the primary panel domain.
+func (pk *Passkeys) WebAuthn() (*webauthn.WebAuthn, error) {
+	config := component.GetStill(pk).Config
+
+	origins := make([]string, 0, len(config.HTTP.PanelDomains()))
+	for _, domain := range config.HTTP.PanelDomains() {
+		origin := "http://" + domain
+		if config.HTTP.HTTPSEnabled() {
+			origin = "https://" + domain
+		}
+		origins = append(origins, origin)
+	}
+
+	rp, err := webauthn.New(&webauthn.Config{
+		RPID:          config.HTTP.PanelDomain(),
+		RPDisplayName: config.Home.Title,
+		RPOrigins:     origins,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			UserVerification: protocol.VerificationDiscouraged,
+		},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true, Timeout: timeout, TimeoutUVD: timeout},
+			Registration: webauthn.TimeoutConfig{Enforce: true, Timeout: timeout, TimeoutUVD: timeout},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure webauthn: %w", err)
+	}
+	return rp, nil
+}
+
+// Passkeys returns the passkeys registered by the given user.
+func (pk *Passkeys) Passkeys(ctx context.Context, user string) ([]models.Passkey, error) {
+	// the empty user has no passkeys
+	if user == "" {
+		return nil, nil
+	}
+
+	table, err := pk.table(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var passkeys []models.Passkey
+	if err := table.Order("pk asc").Find(&passkeys, &models.Passkey{User: user}).Error; err != nil {
+		return nil, fmt.Errorf("failed to find passkeys: %w", err)
+	}
+	return passkeys, nil
+}
+
+// Has checks if the given user has registered at least one passkey.
+func (pk *Passkeys) Has(ctx context.Context, user string) (bool, error) {
+	if user == "" {
+		return false, nil
+	}
+
+	table, err := pk.table(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	if err := table.Model(&models.Passkey{}).Where(&models.Passkey{User: user}).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to count passkeys: %w", err)
+	}
+	return count > 0, nil
+}
+
+// Add registers a new passkey for the given user.
+func (pk *Passkeys) Add(ctx context.Context, user string, name string, credential *webauthn.Credential) (*models.Passkey, error) {
+	passkey := models.Passkey{
+		User: user,
+		Name: name,
+	}
+	if err := passkey.SetCredential(credential); err != nil {
+		return nil, err
+	}
+
+	table, err := pk.table(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := table.Create(&passkey).Error; err != nil {
+		return nil, fmt.Errorf("failed to create passkey: %w", err)
+	}
+	return &passkey, nil
+}
+
+var errNoPasskey = errors.New("passkey does not exist")
+
+// Use stores the updated credential after it was used to login, and records the time of use.
+func (pk *Passkeys) Use(ctx context.Context, user string, credential *webauthn.Credential) error {
+	var passkey models.Passkey
+	if err := passkey.SetCredential(credential); err != nil {
+		return err
+	}
+
+	table, err := pk.table(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	res := table.Model(&models.Passkey{}).
+		Where(&models.Passkey{User: user, CredentialID: passkey.CredentialID}).
+		Updates(models.Passkey{Credential: passkey.Credential, LastUsed: &now})
+	if res.Error != nil {
+		return fmt.Errorf("failed to update passkey: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errNoPasskey
+	}
+	return nil
+}
+
+// Remove removes the passkey with the given id from the user.
+func (pk *Passkeys) Remove(ctx context.Context, user string, id uint) error {
+	table, err := pk.table(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := table.Where("user = ? AND pk = ?", user, id).Delete(&models.Passkey{}).Error; err != nil {
+		return fmt.Errorf("failed to delete passkey: %w", err)
+	}
+	return nil
+}
+
+// User returns the user as seen by WebAuthn ceremonies, including all their credentials.
+func (pk *Passkeys) User(ctx context.Context, user *models.User) (webauthn.User, error) {
+	passkeys, err := pk.Passkeys(ctx, user.User)
+	if err != nil {
+		return nil, err
+	}
+
+	wu := &webAuthnUser{user: user, credentials: make([]webauthn.Credential, len(passkeys))}
+	for i, passkey := range passkeys {
+		wu.credentials[i], err = passkey.GetCredential()
+		if err != nil {
+			return nil, fmt.Errorf("passkey %q: %w", passkey.Name, err)
+		}
+	}
+	return wu, nil
+}
+
+// webAuthnUser implements webauthn.User.
+type webAuthnUser struct {
+	user        *models.User
+	credentials []webauthn.Credential
+}
+
+// WebAuthnID returns the name of the user, which is unique across the distillery.
+func (wu *webAuthnUser) WebAuthnID() []byte {
+	return []byte(wu.user.User)
+}
+
+func (wu *webAuthnUser) WebAuthnName() string {
+	return wu.user.User
+}
+
+func (wu *webAuthnUser) WebAuthnDisplayName() string {
+	return wu.user.User
+}
+
+func (wu *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return wu.credentials
+}
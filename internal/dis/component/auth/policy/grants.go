@@ -20,11 +20,19 @@ var (
 //
 // User and Slug must not be empty.
 // If DrupalUsername is empty, sets the username to be equal to the user.
-func (policy *Policy) Set(ctx context.Context, grant models.Grant) error {
+// If Role is empty, it is derived from DrupalAdminRole.
+func (policy *Policy) Set(ctx context.Context, grant models.Grant) (err error) {
 	if grant.DrupalUsername == "" {
 		grant.DrupalUsername = grant.User
 	}
-	if grant.User == "" || grant.Slug == "" {
+	if grant.Role == "" {
+		grant.Role = grant.GetRole()
+	}
+	grant.SetRole(grant.Role)
+	defer func() {
+		policy.dependencies.Audit.Record(ctx, "grant.set", grant.Slug, map[string]any{"user": grant.User, "drupal_user": grant.DrupalUsername, "role": grant.Role}, err)
+	}()
+	if grant.User == "" || grant.Slug == "" || !grant.Role.Valid() {
 		return errInvalid
 	}
 
@@ -50,7 +58,11 @@ func (policy *Policy) Set(ctx context.Context, grant models.Grant) error {
 
 // Remove removes access for the given username form the given instance.
 // The user not having access is not an error.
-func (policy *Policy) Remove(ctx context.Context, username string, slug string) error {
+func (policy *Policy) Remove(ctx context.Context, username string, slug string) (err error) {
+	defer func() {
+		policy.dependencies.Audit.Record(ctx, "grant.remove", slug, map[string]string{"user": username}, err)
+	}()
+
 	// empty username or slug never have acccess
 	if username == "" || slug == "" {
 		return errInvalid
@@ -1,12 +1,13 @@
 //spellchecker:words policy
 package policy
 
-//spellchecker:words context github wisski distillery internal component auth models gorm
+//spellchecker:words context github wisski distillery internal component audit auth models gorm
 import (
 	"context"
 	"fmt"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
 	"github.com/FAU-CDI/wisski-distillery/internal/models"
@@ -17,8 +18,9 @@ type Policy struct {
 	component.Base
 
 	dependencies struct {
-		SQL  *sql.SQL
-		Auth *auth.Auth
+		SQL   *sql.SQL
+		Auth  *auth.Auth
+		Audit *audit.Audit
 	}
 }
 
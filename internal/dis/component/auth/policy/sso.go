@@ -0,0 +1,65 @@
+//spellchecker:words policy
+package policy
+
+//spellchecker:words context maps slices github wisski distillery internal component models
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+var _ component.SSOLoginHook = (*Policy)(nil)
+
+// OnSSOLogin updates the grants of user according to the group mappings in the configuration.
+// Grants for instances that do not occur in any mapping are left untouched.
+func (pol *Policy) OnSSOLogin(ctx context.Context, user *models.User, groups []string) error {
+	mappings := component.GetStill(pol).Config.OIDC.GrantMappings()
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	// determine access for each managed instance
+	managed := make(map[string]bool, len(mappings))      // slug => granted
+	roles := make(map[string]models.Role, len(mappings)) // slug => most privileged role
+	for _, mapping := range mappings {
+		if !slices.Contains(groups, mapping.Group) {
+			if _, ok := managed[mapping.Slug]; !ok {
+				managed[mapping.Slug] = false
+			}
+			continue
+		}
+		managed[mapping.Slug] = true
+		if role, ok := roles[mapping.Slug]; !ok || mapping.Role.AtLeast(role) {
+			roles[mapping.Slug] = mapping.Role
+		}
+	}
+
+	// keep the drupal username of existing grants
+	existing, err := pol.User(ctx, user.User)
+	if err != nil {
+		return fmt.Errorf("failed to get grants: %w", err)
+	}
+
+	for _, slug := range slices.Sorted(maps.Keys(managed)) {
+		if !managed[slug] {
+			if err := pol.Remove(ctx, user.User, slug); err != nil {
+				return fmt.Errorf("failed to remove grant for %q: %w", slug, err)
+			}
+			continue
+		}
+
+		grant := models.Grant{User: user.User, Slug: slug, Role: roles[slug]}
+		if index := slices.IndexFunc(existing, func(g models.Grant) bool { return g.Slug == slug }); index >= 0 {
+			grant.DrupalUsername = existing[index].DrupalUsername
+		}
+		if err := pol.Set(ctx, grant); err != nil {
+			return fmt.Errorf("failed to set grant for %q: %w", slug, err)
+		}
+	}
+
+	return nil
+}
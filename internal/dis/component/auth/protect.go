@@ -1,7 +1,7 @@
 //spellchecker:words auth
 package auth
 
-//spellchecker:words context errors http github wisski distillery internal component pkglib httpx
+//spellchecker:words context errors http github wisski distillery internal component audit pkglib httpx
 import (
 	"context"
 	"errors"
@@ -9,6 +9,7 @@ import (
 	"net/url"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
 	"go.tkw01536.de/pkglib/httpx"
 )
 
@@ -71,8 +72,8 @@ func (auth *Auth) Protect(handler http.Handler, allowToken bool, scope component
 			}
 		}
 
-		// store the user into the session, and then return the new session
-		r = r.WithContext(context.WithValue(r.Context(), ctxUserKey, user))
+		// store the user into the session, and record them as the actor of any action
+		r = r.WithContext(audit.WithActor(context.WithValue(r.Context(), ctxUserKey, user), auth.actorOf(r, session)))
 		handler.ServeHTTP(w, r)
 		return
 	forbidden:
@@ -89,6 +90,19 @@ func (auth *Auth) Protect(handler http.Handler, allowToken bool, scope component
 	})
 }
 
+// actorOf returns the actor for the given session.
+func (auth *Auth) actorOf(r *http.Request, session component.SessionInfo) audit.Actor {
+	if !session.Token {
+		return audit.Actor{Origin: audit.OriginSession, User: session.Username()}
+	}
+
+	actor := audit.Actor{Origin: audit.OriginToken, User: session.Username()}
+	if token, err := auth.dependencies.Tokens.TokenOf(r); err == nil && token != nil {
+		actor.Token = token.TokenID
+	}
+	return actor
+}
+
 // Require returns a slice containing one decorator that acts like auth.Protect(allowToken,scope,param) on every request.
 func (auth *Auth) Require(allowToken bool, scope component.Scope, param func(*http.Request) string) func(http.Handler) http.Handler {
 	// TODO: Work on this stuff
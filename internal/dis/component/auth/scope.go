@@ -1,12 +1,14 @@
 //spellchecker:words auth
 package auth
 
-//spellchecker:words errors http github wisski distillery internal component
+//spellchecker:words errors http github wisski distillery internal component auth tokens
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/tokens"
 )
 
 var (
@@ -44,6 +46,7 @@ func (auth *Auth) getScopeMap() map[component.Scope]scopeMapEntry {
 // A request can be one of two types:
 // - A signed in user with an implicitly associated set of scopes
 // - A session authorized with a token only
+// Tokens may further restrict the scopes and instances available to a request.
 // If the request is denied a scope, the error will be wrapping an error of type AccessDeniedError.
 func (auth *Auth) CheckScope(param string, scope component.Scope, r *http.Request) error {
 	// the empty scope is always permitted implicitly
@@ -69,8 +72,33 @@ func (auth *Auth) CheckScope(param string, scope component.Scope, r *http.Reques
 	if err != nil {
 		return entry.Info.CheckError(err)
 	}
-	if ok {
-		return nil
+	if !ok {
+		return entry.Info.DeniedError()
+	}
+
+	// check that a token (if any) permits the scope
+	token, err := auth.dependencies.Tokens.TokenOf(r)
+	if err != nil {
+		return entry.Info.CheckError(err)
+	}
+	if token != nil && !tokens.Permits(token, entry.Info, param) {
+		return entry.Info.DeniedError()
+	}
+	return nil
+}
+
+// CheckInstance checks if the given request may access the instance with the given slug.
+// This is only restricted for requests authorized with a token that is bound to specific instances.
+// Access to the instance should be checked separately using [Auth.CheckScope].
+//
+// If access is denied, the error will be of type AccessDeniedError.
+func (auth *Auth) CheckInstance(slug string, r *http.Request) error {
+	token, err := auth.dependencies.Tokens.TokenOf(r)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	if token != nil && !token.PermitsInstance(slug) {
+		return component.AccessDeniedError(fmt.Sprintf("token may not access instance %q", slug))
 	}
-	return entry.Info.DeniedError()
+	return nil
 }
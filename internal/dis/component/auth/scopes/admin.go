@@ -29,7 +29,7 @@ func (*AdminLoggedIn) Scope() component.ScopeInfo {
 	return component.ScopeInfo{
 		Scope:         ScopeUserAdmin,
 		Description:   "session must have a valid admin",
-		DeniedMessage: "user must have an admin account with TOTP enabled",
+		DeniedMessage: "user must have an admin account and login with a second factor (TOTP or passkey)",
 		TakesParam:    false,
 	}
 }
@@ -39,5 +39,12 @@ func (al *AdminLoggedIn) HasScope(param string, r *http.Request) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("failed to get session: %w", err)
 	}
-	return user != nil && user.IsAdmin() && user.IsTOTPEnabled(), nil
+	if user == nil || !user.IsAdmin() {
+		return false, nil
+	}
+	ok, err := al.dependencies.Auth.PresentedSecondFactor(r)
+	if err != nil {
+		return false, fmt.Errorf("failed to check second factor: %w", err)
+	}
+	return ok, nil
 }
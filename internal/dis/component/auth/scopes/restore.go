@@ -0,0 +1,40 @@
+//spellchecker:words scopes
+package scopes
+
+//spellchecker:words http github wisski distillery internal component auth policy
+import (
+	"net/http"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/policy"
+)
+
+type RestoreScope struct {
+	component.Base
+	dependencies struct {
+		Auth   *auth.Auth
+		Policy *policy.Policy
+	}
+}
+
+var (
+	_ component.ScopeProvider = (*RestoreScope)(nil)
+)
+
+const (
+	ScopeInstanceRestore Scope = "instance.restore"
+)
+
+func (*RestoreScope) Scope() component.ScopeInfo {
+	return component.ScopeInfo{
+		Scope:         ScopeInstanceRestore,
+		Description:   "restore the instance with the given slug from one of its snapshots",
+		DeniedMessage: instanceDeniedMessage,
+		TakesParam:    true,
+	}
+}
+
+func (rs *RestoreScope) HasScope(param string, r *http.Request) (bool, error) {
+	return hasInstanceScope(rs.dependencies.Auth, rs.dependencies.Policy, ScopeInstanceRestore, param, r)
+}
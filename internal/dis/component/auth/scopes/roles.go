@@ -0,0 +1,203 @@
+//spellchecker:words scopes
+package scopes
+
+//spellchecker:words errors http slices github wisski distillery internal component auth policy models
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/policy"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+const (
+	ScopeInstanceSnapshot Scope = "instance.snapshot"
+	ScopeInstanceRebuild  Scope = "instance.rebuild"
+	ScopeInstanceSystem   Scope = "instance.system"
+	ScopeInstanceControl  Scope = "instance.control"
+	ScopeInstancePurge    Scope = "instance.purge"
+)
+
+// roleScopes maps roles on an instance to the scopes they grant for the instance.
+// Distillery admins have all of these scopes for every instance.
+var roleScopes = map[models.Role][]Scope{
+	models.RoleReviewer: {},
+	models.RoleEditor:   {},
+	models.RoleOperator: {ScopeInstanceSnapshot, ScopeInstanceRebuild, ScopeInstanceControl},
+	models.RoleAdmin:    {ScopeInstanceSnapshot, ScopeInstanceRebuild, ScopeInstanceControl, ScopeInstanceRestore},
+}
+
+// RoleScopes returns the scopes granted by the given role for an instance.
+func RoleScopes(role models.Role) []Scope {
+	return roleScopes[role]
+}
+
+const instanceDeniedMessage = "user must be a distillery admin or have a role on the instance that permits this, and login with a second factor (TOTP or passkey)"
+
+// hasInstanceScope checks if the user of the given request has the given scope for the instance with the given slug.
+//
+// Distillery admins have the scope for every instance.
+// Everyone else needs a grant with a role that includes the scope.
+// Grants created before roles existed never include a scope, as they only gave access to drupal.
+// In both cases, the user must have presented a second factor.
+func hasInstanceScope(au *auth.Auth, pol *policy.Policy, scope Scope, slug string, r *http.Request) (bool, error) {
+	_, user, err := au.SessionOf(r)
+	if err != nil {
+		return false, fmt.Errorf("failed to get session: %w", err)
+	}
+	if user == nil {
+		return false, nil
+	}
+	hasSecondFactor, err := au.PresentedSecondFactor(r)
+	if err != nil {
+		return false, fmt.Errorf("failed to check second factor: %w", err)
+	}
+	if !hasSecondFactor {
+		return false, nil
+	}
+
+	if user.IsAdmin() {
+		return true, nil
+	}
+
+	grant, err := pol.Has(r.Context(), user.User.User, slug)
+	if errors.Is(err, policy.ErrNoAccess) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check grant: %w", err)
+	}
+	if grant.Legacy() {
+		return false, nil
+	}
+	return slices.Contains(RoleScopes(grant.Role), scope), nil
+}
+
+type SnapshotScope struct {
+	component.Base
+	dependencies struct {
+		Auth   *auth.Auth
+		Policy *policy.Policy
+	}
+}
+
+var (
+	_ component.ScopeProvider = (*SnapshotScope)(nil)
+)
+
+func (*SnapshotScope) Scope() component.ScopeInfo {
+	return component.ScopeInfo{
+		Scope:         ScopeInstanceSnapshot,
+		Description:   "make snapshots of the instance with the given slug",
+		DeniedMessage: instanceDeniedMessage,
+		TakesParam:    true,
+	}
+}
+
+func (ss *SnapshotScope) HasScope(param string, r *http.Request) (bool, error) {
+	return hasInstanceScope(ss.dependencies.Auth, ss.dependencies.Policy, ScopeInstanceSnapshot, param, r)
+}
+
+type RebuildScope struct {
+	component.Base
+	dependencies struct {
+		Auth   *auth.Auth
+		Policy *policy.Policy
+	}
+}
+
+var (
+	_ component.ScopeProvider = (*RebuildScope)(nil)
+)
+
+func (*RebuildScope) Scope() component.ScopeInfo {
+	return component.ScopeInfo{
+		Scope:         ScopeInstanceRebuild,
+		Description:   "rebuild and update the instance with the given slug",
+		DeniedMessage: instanceDeniedMessage,
+		TakesParam:    true,
+	}
+}
+
+func (rs *RebuildScope) HasScope(param string, r *http.Request) (bool, error) {
+	return hasInstanceScope(rs.dependencies.Auth, rs.dependencies.Policy, ScopeInstanceRebuild, param, r)
+}
+
+type SystemScope struct {
+	component.Base
+	dependencies struct {
+		Auth   *auth.Auth
+		Policy *policy.Policy
+	}
+}
+
+var (
+	_ component.ScopeProvider = (*SystemScope)(nil)
+)
+
+func (*SystemScope) Scope() component.ScopeInfo {
+	return component.ScopeInfo{
+		Scope:         ScopeInstanceSystem,
+		Description:   "change the system properties of the instance with the given slug, such as its aliases, limits and access protection",
+		DeniedMessage: instanceDeniedMessage,
+		TakesParam:    true,
+	}
+}
+
+func (ss *SystemScope) HasScope(param string, r *http.Request) (bool, error) {
+	return hasInstanceScope(ss.dependencies.Auth, ss.dependencies.Policy, ScopeInstanceSystem, param, r)
+}
+
+type ControlScope struct {
+	component.Base
+	dependencies struct {
+		Auth   *auth.Auth
+		Policy *policy.Policy
+	}
+}
+
+var (
+	_ component.ScopeProvider = (*ControlScope)(nil)
+)
+
+func (*ControlScope) Scope() component.ScopeInfo {
+	return component.ScopeInfo{
+		Scope:         ScopeInstanceControl,
+		Description:   "start and stop the instance with the given slug, run its cron and view its logs",
+		DeniedMessage: instanceDeniedMessage,
+		TakesParam:    true,
+	}
+}
+
+func (cs *ControlScope) HasScope(param string, r *http.Request) (bool, error) {
+	return hasInstanceScope(cs.dependencies.Auth, cs.dependencies.Policy, ScopeInstanceControl, param, r)
+}
+
+type PurgeScope struct {
+	component.Base
+	dependencies struct {
+		Auth   *auth.Auth
+		Policy *policy.Policy
+	}
+}
+
+var (
+	_ component.ScopeProvider = (*PurgeScope)(nil)
+)
+
+func (*PurgeScope) Scope() component.ScopeInfo {
+	return component.ScopeInfo{
+		Scope:         ScopeInstancePurge,
+		Description:   "purge the instance with the given slug",
+		DeniedMessage: instanceDeniedMessage,
+		TakesParam:    true,
+	}
+}
+
+func (ps *PurgeScope) HasScope(param string, r *http.Request) (bool, error) {
+	return hasInstanceScope(ps.dependencies.Auth, ps.dependencies.Policy, ScopeInstancePurge, param, r)
+}
@@ -30,6 +30,9 @@ func (*UserLoggedIn) Scope() component.ScopeInfo {
 		Scope:       ScopeUserValid,
 		Description: "session must have a valid user",
 		TakesParam:  false,
+
+		// required to access the socket, which checks the scope of each action separately
+		InstanceTokens: true,
 	}
 }
 
@@ -9,6 +9,7 @@ import (
 	"html/template"
 	"log/slog"
 	"net/http"
+	"net/url"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server"
@@ -140,19 +141,66 @@ func (auth *Auth) Menu(r *http.Request) []component.MenuItem {
 	}
 }
 
+// PresentedSecondFactor reports if the user of the given request presented a second factor.
+//
+// For a browser session, a second factor must have been used to login.
+// For a token, the user must have a second factor enabled.
+func (auth *Auth) PresentedSecondFactor(r *http.Request) (bool, error) {
+	session, user, err := auth.SessionOf(r)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, nil
+	}
+
+	if session.Token {
+		return user.HasSecondFactor(r.Context())
+	}
+
+	method, _ := auth.session(r).Values[server.SessionMethodKey].(string)
+	return LoginMethod(method).SecondFactor(), nil
+}
+
+// LoginMethod describes how a user logged into a session.
+type LoginMethod string
+
+const (
+	LoginPassword    LoginMethod = "password"    // password only
+	LoginTOTP        LoginMethod = "totp"        // password and passcode
+	LoginPasskey     LoginMethod = "passkey"     // password and passkey
+	LoginSSO         LoginMethod = "sso"         // single sign-on
+	LoginSSOMFA      LoginMethod = "sso-mfa"     // single sign-on, where the identity provider used multiple factors
+	LoginImpersonate LoginMethod = "impersonate" // impersonation by an admin, who needs a second factor to do so
+)
+
+// SecondFactor reports if the login method involves a second factor.
+func (method LoginMethod) SecondFactor() bool {
+	switch method {
+	case LoginTOTP, LoginPasskey, LoginSSOMFA, LoginImpersonate:
+		return true
+	default:
+		return false
+	}
+}
+
 type contextUserKey struct{}
 
 var ctxUserKey = contextUserKey{}
 
 // Login logs a user into the given request.
+// method is recorded in the session, see [Auth.PresentedSecondFactor].
 //
 // If a user was previously logged into this session,
 // UserOf may not return the correct user until the user makes a new request.
 //
 // It is recommended to send a HTTP redirect to make sure a new request is made.
-func (auth *Auth) Login(w http.ResponseWriter, r *http.Request, user *AuthUser) error {
+func (auth *Auth) Login(w http.ResponseWriter, r *http.Request, user *AuthUser, method LoginMethod) error {
 	sess := auth.session(r)
 	sess.Values[server.SessionUserKey] = user.User.User
+	sess.Values[server.SessionMethodKey] = string(method)
+	delete(sess.Values, passkeyUserKey)
+	delete(sess.Values, passkeySessionKey)
 	if err := sess.Save(r, w); err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
@@ -174,15 +222,30 @@ func (auth *Auth) Logout(w http.ResponseWriter, r *http.Request) error {
 
 //go:embed "login.html"
 var loginHTML []byte
-var loginTemplate = templating.ParseForm(
+var loginTemplate = templating.Parse[loginContext](
 	"login.html", loginHTML, form.FormTemplate,
 
 	templating.Title("Login Required"),
 	templating.Assets(assets.AssetsUser),
 )
 
+type loginContext struct {
+	templating.FormContext
+
+	// SSOURL and SSOTitle describe the login via single sign-on.
+	// SSOURL is empty when single sign-on is disabled.
+	SSOURL   string
+	SSOTitle string
+}
+
 var errLoginFailed = errors.New("login failed")
 
+// loginResult is the result of a successful login form.
+type loginResult struct {
+	User    *AuthUser
+	Passkey bool // the user still has to use a passkey to complete the login
+}
+
 // authLogin implements a view to login a user.
 func (auth *Auth) authLogin(ctx context.Context) http.Handler {
 	tpl := loginTemplate.Prepare(
@@ -195,7 +258,7 @@ func (auth *Auth) authLogin(ctx context.Context) http.Handler {
 		},
 	)
 
-	return &form.Form[*AuthUser]{
+	return &form.Form[loginResult]{
 		Fields: []field.Field{
 			{Name: "username", Type: field.Text, Autocomplete: field.Username, Label: "Username"},
 			{Name: "password", Type: field.Password, Autocomplete: field.CurrentPassword, EmptyOnError: true, Label: "Password"},
@@ -208,43 +271,72 @@ func (auth *Auth) authLogin(ctx context.Context) http.Handler {
 			if ctx.Err != nil {
 				ctx.Err = fmt.Errorf("%w: %w", errLoginFailed, ctx.Err)
 			}
-			return tpl.Context(r, templating.NewFormContext(ctx))
+			return tpl.Context(r, loginContext{
+				FormContext: templating.NewFormContext(ctx),
+				SSOURL:      auth.ssoURL(r.URL.Query().Get("next")),
+				SSOTitle:    component.GetStill(auth).Config.OIDC.Title,
+			})
 		},
 		LogTemplateError: tpl.LogTemplateError,
 
-		Validate: func(r *http.Request, values map[string]string) (*AuthUser, error) {
+		Validate: func(r *http.Request, values map[string]string) (result loginResult, err error) {
 			username, password, passcode := values["username"], values["password"], values["otp"]
 
 			// make sure that the user exists
-			user, err := auth.User(ctx, username)
+			result.User, err = auth.User(ctx, username)
 			if err != nil {
-				return nil, err
+				return result, err
 			}
 
-			// check the password and totp
-			err = user.CheckCredentials(ctx, []byte(password), passcode)
+			// a passcode is only optional when the user has a passkey instead
+			if passcode == "" || !result.User.IsTOTPEnabled() {
+				result.Passkey, err = auth.dependencies.Passkeys.Has(ctx, result.User.User.User)
+				if err != nil {
+					return result, err
+				}
+			}
+
+			// check the password, and the totp unless a passkey is used
+			if result.Passkey {
+				err = result.User.CheckPassword(ctx, []byte(password))
+			} else {
+				err = result.User.CheckCredentials(ctx, []byte(password), passcode)
+			}
 			if err != nil {
-				return nil, err
+				return result, err
 			}
-			return user, nil
+			return result, nil
 		},
 
-		Skip: func(r *http.Request) (user *AuthUser, skip bool) {
+		Skip: func(r *http.Request) (result loginResult, skip bool) {
 			user, err := auth.UserOfSession(r)
-			return user, err == nil && user != nil
+			return loginResult{User: user}, err == nil && user != nil
 		},
 
-		Success: func(user *AuthUser, _ map[string]string, w http.ResponseWriter, r *http.Request) error {
-			if err := auth.Login(w, r, user); err != nil {
-				return err
-			}
-
+		Success: func(result loginResult, _ map[string]string, w http.ResponseWriter, r *http.Request) error {
 			// get the destination
 			next := r.URL.Query().Get("next")
 			if next == "" || next[0] != '/' {
 				next = "/"
 			}
 
+			// the user still has to use their passkey
+			if result.Passkey {
+				if err := auth.setPendingUser(w, r, result.User); err != nil {
+					return err
+				}
+				http.Redirect(w, r, "/auth/passkey?next="+url.QueryEscape(next), http.StatusSeeOther)
+				return nil
+			}
+
+			method := LoginPassword
+			if result.User.IsTOTPEnabled() {
+				method = LoginTOTP
+			}
+			if err := auth.Login(w, r, result.User, method); err != nil {
+				return err
+			}
+
 			// and redirect to it!
 			http.Redirect(w, r, next, http.StatusSeeOther)
 
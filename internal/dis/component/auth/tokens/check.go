@@ -1,16 +1,21 @@
 //spellchecker:words tokens
 package tokens
 
-//spellchecker:words errors http strings slices github wisski distillery internal component models pkglib errorsx
+//spellchecker:words context errors http strings slices time github wisski distillery internal component models wdlog pkglib errorsx
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"slices"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
 	"go.tkw01536.de/pkglib/errorsx"
 )
 
@@ -21,6 +26,7 @@ const (
 
 // TokenOf returns the token header found in the given request.
 // If r is nil, or there is no token, returns nil.
+// Expired tokens are treated as if there was no token.
 // Error is only set if there is an error accessing the table that stores tokens.
 func (tok *Tokens) TokenOf(r *http.Request) (*models.Token, error) {
 	if r == nil {
@@ -55,13 +61,72 @@ func (tok *Tokens) TokenOf(r *http.Request) (*models.Token, error) {
 		return nil, nil
 	}
 
+	// expired tokens can no longer be used
+	now := time.Now()
+	if tokenObj.Expired(now) {
+		return nil, nil
+	}
+
+	// record that the token was used
+	if err := tok.use(r.Context(), &tokenObj, now, remoteIP(r)); err != nil {
+		wdlog.Of(r.Context()).Warn(
+			"failed to record token use",
+			"token", tokenObj.TokenID,
+			"error", err,
+		)
+	}
+
 	// and return the token object
 	return &tokenObj, nil
 }
 
+// useInterval is the minimal time between two recorded uses of the same token from the same address.
+// It avoids writing to the database on every scope check.
+const useInterval = time.Minute
+
+// use records that token was used at the given time from the given ip address.
+func (tok *Tokens) use(ctx context.Context, token *models.Token, now time.Time, ip string) error {
+	if token.LastUsed != nil && token.LastIP == ip && now.Sub(*token.LastUsed) < useInterval {
+		return nil
+	}
+
+	table, err := tok.table(ctx)
+	if err != nil {
+		return err
+	}
+
+	token.LastUsed = &now
+	token.LastIP = ip
+	if err := table.Model(&models.Token{}).Where(&models.Token{Pk: token.Pk}).Select("used", "ip").Updates(token).Error; err != nil {
+		return fmt.Errorf("failed to update token: %w", err)
+	}
+	return nil
+}
+
+// remoteIP returns the ip address the request originated from.
+// Requests are forwarded by the reverse proxy, which appends the address it received the request from to the X-Forwarded-For header.
+func remoteIP(r *http.Request) string {
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		last := forwarded[len(forwarded)-1]
+		if index := strings.LastIndex(last, ","); index >= 0 {
+			last = last[index+1:]
+		}
+		if ip := strings.TrimSpace(last); ip != "" {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 var ErrNoToken = errors.New("no token")
 
 // Check checks if there is a token in the given request and if this request has an appropriate token with the appropriate scope.
+// For scopes that take an instance slug as a parameter, the token must also permit that instance.
 //
 // If the token is found and has the requested token, returns true, nil.
 // If there is a token found, but the specific scope is not set, returns false, nil.
@@ -70,22 +135,36 @@ var ErrNoToken = errors.New("no token")
 //
 // Note that the scope may require an parameter to be validated.
 // This validation should take place in the appropriate ScopeProvider; which should recursively invoke this method.
-func (tok *Tokens) Check(r *http.Request, scope component.Scope) (bool, error) {
+func (tok *Tokens) Check(r *http.Request, scope component.ScopeInfo, param string) (bool, error) {
 	// get the token object from the request
 	tokenObj, err := tok.TokenOf(r)
 	if tokenObj == nil {
 		return false, errorsx.Combine(ErrNoToken, err)
 	}
 
-	// TODO: Do we need this function?
+	return Permits(tokenObj, scope, param), nil
+}
+
+// Permits checks if the given token permits the given scope with the given parameter.
+// A non-empty parameter is the slug of an instance, and must be permitted by the token.
+//
+// Tokens bound to specific instances are denied scopes without a parameter,
+// unless the scope explicitly allows them, see [component.ScopeInfo.InstanceTokens].
+func Permits(tokenObj *models.Token, scope component.ScopeInfo, param string) bool {
+	if param == "" && !scope.InstanceTokens && tokenObj.GetInstances() != nil {
+		return false
+	}
+	if param != "" && !tokenObj.PermitsInstance(param) {
+		return false
+	}
 
 	// get the scopes
 	scopes := tokenObj.GetScopes()
 	if scopes == nil {
 		// all scopes (implicitly)
-		return true, nil
+		return true
 	}
 
 	// else check if they are contained
-	return slices.Contains(scopes, string(scope)), nil
+	return slices.Contains(scopes, string(scope.Scope))
 }
@@ -0,0 +1,48 @@
+package tokens_test
+
+import (
+	"testing"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/tokens"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+func TestPermits(t *testing.T) {
+	t.Parallel()
+
+	var (
+		instanceScope = component.ScopeInfo{Scope: "instance.test", TakesParam: true}
+		globalScope   = component.ScopeInfo{Scope: "global.test"}
+		validScope    = component.ScopeInfo{Scope: "valid.test", InstanceTokens: true}
+	)
+
+	unbound := models.Token{AllScopes: true}
+	bound := models.Token{AllScopes: true}
+	if err := bound.SetInstances([]string{"example"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		Name  string
+		Token *models.Token
+		Scope component.ScopeInfo
+		Param string
+		Want  bool
+	}{
+		{"unbound token, instance scope", &unbound, instanceScope, "other", true},
+		{"unbound token, global scope", &unbound, globalScope, "", true},
+		{"bound token, permitted instance", &bound, instanceScope, "example", true},
+		{"bound token, other instance", &bound, instanceScope, "other", false},
+		{"bound token, global scope", &bound, globalScope, "", false},
+		{"bound token, scope allowing instance tokens", &bound, validScope, "", true},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tokens.Permits(tt.Token, tt.Scope, tt.Param); got != tt.Want {
+				t.Errorf("Permits() = %v, want %v", got, tt.Want)
+			}
+		})
+	}
+}
@@ -1,12 +1,13 @@
 //spellchecker:words tokens
 package tokens
 
-//spellchecker:words context crypto rand strings github wisski distillery internal component models pkglib password gorm
+//spellchecker:words context crypto rand strings time github wisski distillery internal component models pkglib password gorm
 import (
 	"context"
 	"crypto/rand"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
@@ -27,6 +28,7 @@ type Tokens struct {
 var (
 	_ component.UserDeleteHook = (*Tokens)(nil)
 	_ component.Table          = (*Tokens)(nil)
+	_ component.Cronable       = (*Tokens)(nil)
 )
 
 func (tok *Tokens) TableInfo() component.TableInfo {
@@ -107,16 +109,21 @@ func NewToken() (string, error) {
 }
 
 // Add adds a new token, unless it already exists.
-// The token is granted scopes with .SetScopes(scopes).
-func (tok *Tokens) Add(ctx context.Context, user string, description string, scopes []string) (*models.Token, error) {
+// The token is granted scopes with .SetScopes(scopes), and restricted to instances with .SetInstances(instances).
+// If expires is not nil, the token can no longer be used after the given time.
+func (tok *Tokens) Add(ctx context.Context, user string, description string, scopes []string, instances []string, expires *time.Time) (*models.Token, error) {
 	// create a new token and set the scopes
 	mk := models.Token{
 		User:        user,
 		Description: description,
+		Expires:     expires,
 	}
 	if err := mk.SetScopes(scopes); err != nil {
 		return nil, fmt.Errorf("failed to set scopes: %w", err)
 	}
+	if err := mk.SetInstances(instances); err != nil {
+		return nil, fmt.Errorf("failed to set instances: %w", err)
+	}
 
 	// generate a new id for the token
 	{
@@ -160,3 +167,20 @@ func (tok *Tokens) Remove(ctx context.Context, user, id string) error {
 	// and do the delete
 	return table.Where("user = ? AND id = ?", user, id).Delete(&models.Token{}).Error
 }
+
+func (tok *Tokens) TaskName() string {
+	return "token cleanup"
+}
+
+// Cron removes all tokens that have expired.
+func (tok *Tokens) Cron(ctx context.Context) error {
+	table, err := tok.table(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := table.Where("expires IS NOT NULL AND expires <= ?", time.Now()).Delete(&models.Token{}).Error; err != nil {
+		return fmt.Errorf("failed to delete expired tokens: %w", err)
+	}
+	return nil
+}
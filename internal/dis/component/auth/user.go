@@ -87,6 +87,10 @@ func (auth *Auth) User(ctx context.Context, name string) (au *AuthUser, err erro
 // CreateUser creates a new user and returns it.
 // The user is not associated to any WissKIs, and has no password set.
 func (auth *Auth) CreateUser(ctx context.Context, name string) (user *AuthUser, err error) {
+	defer func() {
+		auth.dependencies.Audit.Record(ctx, "user.create", "", map[string]string{"user": name}, err)
+	}()
+
 	user = &AuthUser{
 		User: models.User{
 			User: name,
@@ -319,7 +323,11 @@ func (au *AuthUser) MakeRegular(ctx context.Context) error {
 }
 
 // Save saves the given user in the database.
-func (au *AuthUser) Save(ctx context.Context) error {
+func (au *AuthUser) Save(ctx context.Context) (err error) {
+	defer func() {
+		au.auth.dependencies.Audit.Record(ctx, "user.save", "", au.auditParams(), err)
+	}()
+
 	table, err := sql.OpenInterface[models.User](ctx, au.auth.dependencies.SQL, au.auth)
 	if err != nil {
 		return fmt.Errorf("failed to query table: %w", err)
@@ -335,7 +343,11 @@ func (au *AuthUser) Save(ctx context.Context) error {
 }
 
 // Delete deletes the user from the database.
-func (au *AuthUser) Delete(ctx context.Context) error {
+func (au *AuthUser) Delete(ctx context.Context) (err error) {
+	defer func() {
+		au.auth.dependencies.Audit.Record(ctx, "user.delete", "", map[string]string{"user": au.User.User}, err)
+	}()
+
 	// run all the user delete hooks
 	for _, c := range au.auth.dependencies.UserDeleteHooks {
 		if err := c.OnUserDelete(ctx, &au.User); err != nil {
@@ -353,3 +365,13 @@ func (au *AuthUser) Delete(ctx context.Context) error {
 	}
 	return nil
 }
+
+// auditParams returns the parameters recorded in the audit log when this user is saved.
+func (au *AuthUser) auditParams() map[string]any {
+	return map[string]any{
+		"user":    au.User.User,
+		"admin":   au.IsAdmin(),
+		"enabled": au.IsEnabled(),
+		"totp":    au.IsTOTPEnabled(),
+	}
+}
@@ -40,13 +40,23 @@ type Snapshotable interface {
 	Snapshot(wisski models.Instance, context *StagingContext) error
 }
 
+// Deduplicator stores files in deduplicated form, instead of copying them into the staging directory.
+type Deduplicator interface {
+	// Add stores the file or directory tree at src under the absolute staging path dst.
+	// onAdd is called with the destination path of each file added.
+	Add(ctx context.Context, dst, src string, onAdd func(dst string)) error
+}
+
 // NewStagingContext returns a new [StagingContext].
-func NewStagingContext(ctx context.Context, progress io.Writer, path string, manifest chan<- string) *StagingContext {
+//
+// If dedup is not nil, files and directories copied using the context are passed to it instead of being copied.
+func NewStagingContext(ctx context.Context, progress io.Writer, path string, manifest chan<- string, dedup Deduplicator) *StagingContext {
 	return &StagingContext{
 		ctx:      ctx,
 		progress: progress,
 		path:     path,
 		manifest: manifest,
+		dedup:    dedup,
 	}
 }
 
@@ -58,6 +68,7 @@ type StagingContext struct {
 	progress io.Writer     // writer to direct progress to
 	path     string        // path to send files to
 	manifest chan<- string // channel the manifest is sent to
+	dedup    Deduplicator  // optional deduplicator to store copied files in
 }
 
 func (bc *StagingContext) sendPath(path string) {
@@ -142,6 +153,7 @@ func (sc *StagingContext) AddDirectory(path string, op func(context.Context) err
 }
 
 // CopyFile copies a file from src to dst.
+// If the context has a [Deduplicator], the file is passed to it instead.
 func (sc *StagingContext) CopyFile(dst, src string) error {
 	if err, ok := sc.ctxdone(); ok {
 		return err
@@ -151,6 +163,14 @@ func (sc *StagingContext) CopyFile(dst, src string) error {
 	if err != nil {
 		return err
 	}
+	if sc.dedup != nil {
+		if err := sc.dedup.Add(sc.ctx, dstPath, src, nil); err != nil {
+			return fmt.Errorf("failed to deduplicate file: %w", err)
+		}
+		sc.sendPath(dst)
+		return nil
+	}
+
 	sc.sendPath(dst)
 	if err := umaskfree.CopyFile(dstPath, src); err != nil {
 		return fmt.Errorf("failed to copy file: %w", err)
@@ -159,6 +179,7 @@ func (sc *StagingContext) CopyFile(dst, src string) error {
 }
 
 // CopyDirectory copies a directory from src to dst.
+// If the context has a [Deduplicator], the directory is passed to it instead.
 func (sc *StagingContext) CopyDirectory(dst, src string) error {
 	if err, ok := sc.ctxdone(); ok {
 		return err
@@ -169,6 +190,13 @@ func (sc *StagingContext) CopyDirectory(dst, src string) error {
 		return err
 	}
 
+	if sc.dedup != nil {
+		if err := sc.dedup.Add(sc.ctx, dstPath, src, sc.sendPath); err != nil {
+			return fmt.Errorf("failed to deduplicate directory: %w", err)
+		}
+		return nil
+	}
+
 	if err := umaskfree.CopyDirectory(dstPath, src, func(dst, src string) {
 		sc.sendPath(dst)
 	}); err != nil {
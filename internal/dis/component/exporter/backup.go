@@ -109,6 +109,7 @@ func (backup *Backup) run(ctx context.Context, progress io.Writer, exporter *Exp
 						writer,
 						filepath.Join(backup.Description.Dest, bc.BackupName()),
 						manifest,
+						nil,
 					),
 				)
 			},
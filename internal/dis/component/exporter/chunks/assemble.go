@@ -0,0 +1,101 @@
+//spellchecker:words chunks
+package chunks
+
+//spellchecker:words context errors path filepath pkglib errorsx
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"go.tkw01536.de/pkglib/errorsx"
+)
+
+var (
+	errUnsafePath      = errors.New("index contains unsafe path")
+	errUnsupportedFile = errors.New("index contains unsupported file")
+)
+
+// Assemble writes the files described by index into the existing directory dst.
+// Files are confined to dst; paths escaping it result in an error.
+//
+// onFile, when not nil, is called for each file being written.
+func (store *Store) Assemble(ctx context.Context, index Index, dst string, onFile func(rel string, dst string)) (count int64, e error) {
+	// open the destination as a root, to prevent escaping it
+	root, err := os.OpenRoot(dst)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open destination: %w", err)
+	}
+	defer errorsx.Close(root, &e, "destination root")
+
+	for _, name := range index.paths() {
+		if err := ctx.Err(); err != nil {
+			return count, fmt.Errorf("context cancelled: %w", err)
+		}
+
+		file := index.Files[name]
+
+		// clean up the name, and make sure it is local
+		name = path.Clean(name)
+		if name == "." {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return count, fmt.Errorf("%w: %q", errUnsafePath, name)
+		}
+		relpath := filepath.FromSlash(name)
+
+		if onFile != nil {
+			onFile(relpath, filepath.Join(dst, relpath))
+		}
+
+		// make sure the parent exists
+		if parent := filepath.Dir(relpath); parent != "." {
+			if err := root.MkdirAll(parent, os.ModePerm); err != nil {
+				return count, fmt.Errorf("failed to create directory %q: %w", parent, err)
+			}
+		}
+
+		switch {
+		case file.Mode.IsDir():
+			err := root.Mkdir(relpath, file.Mode.Perm())
+			if err != nil && !errors.Is(err, os.ErrExist) {
+				return count, fmt.Errorf("failed to create directory %q: %w", relpath, err)
+			}
+		case file.Mode&os.ModeSymlink != 0:
+			if err := root.Symlink(file.Link, relpath); err != nil {
+				return count, fmt.Errorf("failed to create symlink %q: %w", relpath, err)
+			}
+		case file.Mode.IsRegular():
+			n, err := store.assembleFile(ctx, root, relpath, file)
+			count += n
+			if err != nil {
+				return count, fmt.Errorf("failed to assemble file %q: %w", relpath, err)
+			}
+		default:
+			return count, fmt.Errorf("%w: %q", errUnsupportedFile, name)
+		}
+	}
+
+	return count, nil
+}
+
+func (store *Store) assembleFile(ctx context.Context, root *os.Root, relpath string, file File) (count int64, e error) {
+	handle, err := root.OpenFile(relpath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, file.Mode.Perm())
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer errorsx.Close(handle, &e, "file")
+
+	count, err = store.writeChunks(ctx, handle, file.Chunks)
+	if err != nil {
+		return count, err
+	}
+
+	if err := root.Chtimes(relpath, file.ModTime, file.ModTime); err != nil {
+		return count, fmt.Errorf("failed to set modification time: %w", err)
+	}
+	return count, nil
+}
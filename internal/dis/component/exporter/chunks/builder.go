@@ -0,0 +1,273 @@
+//spellchecker:words chunks
+package chunks
+
+//spellchecker:words context crypto sha256 encoding errors path filepath sync pkglib errorsx
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.tkw01536.de/pkglib/errorsx"
+)
+
+// Builder builds a new index from files on disk.
+//
+// Files that are unchanged with respect to the parent index reuse the chunks of the parent without being read.
+// All other files are split into content-defined chunks, and only chunks not yet in the store are written.
+//
+// A Builder holds a shared lock on the store, preventing chunks from being collected while it is in use.
+// It must be closed once no longer needed.
+//
+// A Builder is safe for concurrent use.
+type Builder struct {
+	store *Store
+	root  string   // directory that paths in the index are relative to
+	lock  *os.File // shared lock on the store
+
+	parent Index
+
+	m     sync.Mutex
+	index Index
+	stats Stats
+}
+
+// Stats holds statistics about building an index.
+type Stats struct {
+	Files   int // number of regular files in the index
+	Reused  int // number of files reused from the parent without reading them
+	Chunks  int // number of chunks read
+	Written int // number of chunks newly written to the store
+
+	WrittenBytes int64 // number of bytes newly written to the store
+}
+
+// NewBuilder creates a new builder for an index with paths relative to root.
+// If parent is not empty, files are compared against the index with that name.
+//
+// NewBuilder waits for a concurrent [Store.Collect] to finish.
+func (store *Store) NewBuilder(root string, parent string) (_ *Builder, e error) {
+	lock, err := store.lock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if e != nil {
+			e = errorsx.Combine(e, lock.Close())
+		}
+	}()
+
+	builder := &Builder{
+		store: store,
+		root:  root,
+		lock:  lock,
+		index: Index{Parent: parent, Files: make(map[string]File)},
+	}
+
+	if parent != "" {
+		builder.parent, err = store.ReadIndex(parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parent index: %w", err)
+		}
+	}
+	return builder, nil
+}
+
+var errOutsideRoot = errors.New("path is outside of the root directory")
+
+// Add adds the file or directory tree at src to the index.
+// Dst is the absolute path the file would have been copied to, and must be inside the root directory.
+//
+// onAdd is called with the destination path of each file added, and may be nil.
+func (builder *Builder) Add(ctx context.Context, dst, src string, onAdd func(dst string)) error {
+	rel, err := filepath.Rel(builder.root, dst)
+	if err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("%w: %q", errOutsideRoot, dst)
+	}
+
+	if err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context cancelled: %w", err)
+		}
+
+		suffix, err := filepath.Rel(src, path)
+		if err != nil {
+			return fmt.Errorf("failed to compute relative path: %w", err)
+		}
+		if err := builder.addFile(ctx, filepath.ToSlash(filepath.Join(rel, suffix)), path); err != nil {
+			return &fs.PathError{Op: "add", Path: path, Err: err}
+		}
+		if onAdd != nil {
+			onAdd(filepath.Join(dst, suffix))
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to add files: %w", err)
+	}
+	return nil
+}
+
+// addFile adds the single file at src to the index.
+func (builder *Builder) addFile(ctx context.Context, rel, src string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	file := File{
+		Mode:    info.Mode(),
+		ModTime: info.ModTime().UTC(),
+	}
+	switch {
+	case info.Mode().IsDir():
+		// nothing to store
+	case info.Mode()&fs.ModeSymlink != 0:
+		file.Link, err = os.Readlink(src)
+		if err != nil {
+			return fmt.Errorf("failed to read link: %w", err)
+		}
+	case info.Mode().IsRegular():
+		file.Size = info.Size()
+
+		builder.m.Lock()
+		old, ok := builder.parent.Files[rel]
+		builder.m.Unlock()
+
+		// unchanged files can reuse the chunks of the parent
+		if ok && old.unchanged(file) && old.Digest != "" && builder.hasChunks(old.Chunks) {
+			file.Chunks = old.Chunks
+			file.Digest = old.Digest
+
+			builder.m.Lock()
+			builder.stats.Files++
+			builder.stats.Reused++
+			builder.m.Unlock()
+			break
+		}
+
+		file.Chunks, file.Digest, err = builder.chunk(ctx, src)
+		if err != nil {
+			return err
+		}
+
+		builder.m.Lock()
+		builder.stats.Files++
+		builder.m.Unlock()
+	default:
+		// sockets, devices and the like are skipped
+		return nil
+	}
+
+	builder.m.Lock()
+	defer builder.m.Unlock()
+	builder.index.Files[rel] = file
+	return nil
+}
+
+// hasChunks checks that all the given chunks still exist in the store.
+func (builder *Builder) hasChunks(hashes []string) bool {
+	for _, hash := range hashes {
+		if ok, err := builder.store.hasChunk(hash); !ok || err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// chunk splits the file at src into chunks, and writes them to the store.
+// Returns the hashes of the chunks, and the digest of the entire file.
+func (builder *Builder) chunk(ctx context.Context, src string) (hashes []string, digest string, e error) {
+	file, err := os.Open(src) // #nosec G304 -- intended
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer errorsx.Close(file, &e, "file")
+
+	sum := sha256.New()
+	chunker := newChunker(file)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, "", fmt.Errorf("context cancelled: %w", err)
+		}
+
+		data, err := chunker.Next()
+		if errors.Is(err, io.EOF) {
+			return hashes, hex.EncodeToString(sum.Sum(nil)), nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		_, _ = sum.Write(data) // never returns an error
+
+		hash, written, err := builder.store.putChunk(data)
+		if err != nil {
+			return nil, "", err
+		}
+		hashes = append(hashes, hash)
+
+		builder.m.Lock()
+		builder.stats.Chunks++
+		if written {
+			builder.stats.Written++
+			builder.stats.WrittenBytes += int64(len(data))
+		}
+		builder.m.Unlock()
+	}
+}
+
+// Stats returns statistics about the files added so far.
+func (builder *Builder) Stats() Stats {
+	builder.m.Lock()
+	defer builder.m.Unlock()
+
+	return builder.stats
+}
+
+// Digests returns the digests of the regular files added so far, by slash-separated path relative to the root.
+func (builder *Builder) Digests() map[string]string {
+	builder.m.Lock()
+	defer builder.m.Unlock()
+
+	digests := make(map[string]string, len(builder.index.Files))
+	for name, file := range builder.index.Files {
+		if file.Mode.IsRegular() {
+			digests[name] = file.Digest
+		}
+	}
+	return digests
+}
+
+// Commit writes the index under the given name, and returns the path it was written to.
+// The builder must not be used afterwards, except for closing it.
+func (builder *Builder) Commit(name string) (string, error) {
+	builder.m.Lock()
+	defer builder.m.Unlock()
+
+	return builder.store.writeIndex(name, builder.index)
+}
+
+// Close releases the lock held on the store.
+// Chunks written by the builder that are not referenced by a committed index may be collected afterwards.
+func (builder *Builder) Close() error {
+	builder.m.Lock()
+	defer builder.m.Unlock()
+
+	if builder.lock == nil {
+		return nil
+	}
+	err := builder.lock.Close()
+	builder.lock = nil
+	if err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+//spellchecker:words chunks
+package chunks
+
+//spellchecker:words bufio errors
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// MinChunkSize is the minimal size of a chunk, except for the last chunk of a file.
+	MinChunkSize = 512 * 1024 // 512 KiB
+
+	// chunkMask determines where chunks end.
+	// A chunk ends once the low bits of the rolling hash are all zero, which yields an average chunk size of about 1 MiB.
+	chunkMask = 1<<19 - 1
+)
+
+// gear holds the random values the rolling hash is computed from.
+// Changing them moves chunk boundaries, which stops existing chunks from being reused.
+var gear = func() (table [256]uint64) {
+	// splitmix64 with a fixed seed
+	state := uint64(0x5749_534b_495f_4443) // "WISKI_DC"
+	for i := range table {
+		state += 0x9e37_79b9_7f4a_7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58_476d_1ce4_e5b9
+		z = (z ^ (z >> 27)) * 0x94d0_49bb_1331_11eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// chunker splits a stream into content-defined chunks.
+//
+// Chunk boundaries are determined by a rolling hash over the content, rather than by fixed offsets.
+// Inserting or removing data thus only changes the chunks around the modification,
+// and all later chunks remain the same.
+type chunker struct {
+	reader *bufio.Reader
+	buffer []byte
+}
+
+func newChunker(reader io.Reader) *chunker {
+	return &chunker{
+		reader: bufio.NewReaderSize(reader, 64*1024),
+		buffer: make([]byte, ChunkSize),
+	}
+}
+
+// Next returns the next chunk of the stream.
+// The returned slice is only valid until the next call to Next.
+//
+// Once the stream is exhausted, returns [io.EOF].
+func (c *chunker) Next() ([]byte, error) {
+	// the first bytes never end a chunk, so there is no need to hash them
+	n, err := io.ReadFull(c.reader, c.buffer[:MinChunkSize])
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return c.buffer[:n], nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var hash uint64
+	for n < ChunkSize {
+		b, err := c.reader.ReadByte()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		c.buffer[n] = b
+		n++
+
+		hash = hash<<1 + gear[b]
+		if hash&chunkMask == 0 {
+			break
+		}
+	}
+	return c.buffer[:n], nil
+}
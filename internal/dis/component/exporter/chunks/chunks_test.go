@@ -0,0 +1,225 @@
+package chunks_test
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/chunks"
+)
+
+func TestIncremental(t *testing.T) {
+	t.Parallel()
+
+	store := &chunks.Store{Dir: t.TempDir()}
+	src := t.TempDir()
+
+	large := bytes.Repeat([]byte("0123456789"), chunks.ChunkSize/5) // two distinct chunks
+	files := map[string][]byte{
+		"data/large.bin":       large,
+		"data/nested/a.txt":    []byte("a"),
+		"data/nested/b.txt":    []byte("b"),
+		"sql/database.sql":     []byte("CREATE TABLE t;"),
+		"data/empty/.keep":     {},
+		"data/nested/copy.bin": large,
+	}
+	for name, content := range files {
+		writeFile(t, filepath.Join(src, name), content)
+	}
+	if err := os.Symlink("nested/a.txt", filepath.Join(src, "data", "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	// build the first snapshot
+	first := build(t, store, src, "")
+	if stats := first.Stats(); stats.Reused != 0 || stats.Written != 5 {
+		t.Errorf("first snapshot: got %+v, want no reused files and 5 written chunks", stats)
+	}
+	if _, err := first.Commit("first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// change one file, remove another one
+	files["data/nested/a.txt"] = []byte("changed")
+	writeFile(t, filepath.Join(src, "data", "nested", "a.txt"), files["data/nested/a.txt"])
+	if err := os.Remove(filepath.Join(src, "data", "nested", "b.txt")); err != nil {
+		t.Fatal(err)
+	}
+	delete(files, "data/nested/b.txt")
+
+	// build the second snapshot
+	second := build(t, store, src, "first")
+	if stats := second.Stats(); stats.Reused != 4 || stats.Written != 1 {
+		t.Errorf("second snapshot: got %+v, want 4 reused files and 1 written chunk", stats)
+	}
+	if _, err := second.Commit("second"); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// assemble the second snapshot
+	assemble(t, store, "second", files)
+
+	// remove the first snapshot and collect chunks
+	if err := store.DeleteIndex("first"); err != nil {
+		t.Fatal(err)
+	}
+	count, err := store.Collect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("Collect: removed %d chunks, want 2", count)
+	}
+
+	// and the second snapshot can still be assembled
+	assemble(t, store, "second", files)
+
+	// corrupting a chunk is detected by verification
+	index, err := store.ReadIndex("second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Verify(t.Context(), index, nil); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	hash := index.Files["sql/database.sql"].Chunks[0]
+	if err := os.WriteFile(filepath.Join(store.Dir, "data", hash[:2], hash), []byte("corrupted"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Verify(t.Context(), index, nil); err == nil {
+		t.Error("Verify: corrupted chunk not detected")
+	}
+}
+
+func TestIncremental_shifted(t *testing.T) {
+	t.Parallel()
+
+	store := &chunks.Store{Dir: t.TempDir()}
+	src := t.TempDir()
+
+	random := make([]byte, 4*chunks.ChunkSize)
+	_, _ = rand.NewChaCha8([32]byte{}).Read(random) // deterministic test data
+	writeFile(t, filepath.Join(src, "data", "random.bin"), random)
+	writeFile(t, filepath.Join(src, "sql", "database.sql"), []byte("CREATE TABLE t;"))
+
+	first := build(t, store, src, "")
+	if _, err := first.Commit("first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// insert some bytes at the start, shifting all the content
+	writeFile(t, filepath.Join(src, "data", "random.bin"), append([]byte("inserted"), random...))
+
+	second := build(t, store, src, "first")
+	if err := second.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := second.Stats(); stats.Chunks < 8 || stats.Written > 1 {
+		t.Errorf("shifted snapshot: got %+v, want at least 8 chunks and at most 1 written chunk", stats)
+	}
+}
+
+func TestCollect_busy(t *testing.T) {
+	t.Parallel()
+
+	store := &chunks.Store{Dir: t.TempDir()}
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "data", "file.txt"), []byte("content"))
+	writeFile(t, filepath.Join(src, "sql", "database.sql"), []byte("CREATE TABLE t;"))
+
+	// chunks of an index that is still being built are not collected
+	builder := build(t, store, src, "")
+	if _, err := store.Collect(t.Context()); !errors.Is(err, chunks.ErrBusy) {
+		t.Errorf("Collect during build: got error %v, want %v", err, chunks.ErrBusy)
+	}
+
+	// once the builder is closed without committing, they are
+	if err := builder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	count, err := store.Collect(t.Context())
+	if err != nil {
+		t.Fatalf("Collect after build: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Collect after build: removed %d chunks, want 2", count)
+	}
+}
+
+func build(t *testing.T, store *chunks.Store, src string, parent string) *chunks.Builder {
+	t.Helper()
+
+	// make the build relative to a staging directory, like the exporter does
+	staging := t.TempDir()
+	builder, err := store.NewBuilder(staging, parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.Add(t.Context(), filepath.Join(staging, "data"), filepath.Join(src, "data"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.Add(t.Context(), filepath.Join(staging, "sql", "database.sql"), filepath.Join(src, "sql", "database.sql"), nil); err != nil {
+		t.Fatal(err)
+	}
+	return builder
+}
+
+func assemble(t *testing.T, store *chunks.Store, name string, files map[string][]byte) {
+	t.Helper()
+
+	index, err := store.ReadIndex(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := t.TempDir()
+	if _, err := store.Assemble(t.Context(), index, dst, nil); err != nil {
+		t.Fatalf("Assemble: %v", err)
+	}
+
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("Assemble: %v", err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Assemble: %q has wrong content", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "data", "nested", "b.txt")); !os.IsNotExist(err) {
+		t.Errorf("Assemble: removed file exists")
+	}
+	if target, err := os.Readlink(filepath.Join(dst, "data", "link")); err != nil || target != "nested/a.txt" {
+		t.Errorf("Assemble: got link %q (%v), want %q", target, err, "nested/a.txt")
+	}
+}
+
+func writeFile(t *testing.T, path string, content []byte) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// make sure that modification times differ between writes
+	modified := time.Now().Add(time.Duration(len(content)) * time.Second)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,440 @@
+// Package chunks implements a content-addressed store used for incremental snapshots.
+//
+// Files are split into chunks, which are stored under the sha256 hash of their content.
+// Chunk boundaries depend on the content, so that data shifted by an insertion still deduplicates.
+// An [Index] records the chunks that make up each file of a snapshot.
+// Chunks are shared between all indexes, so unchanged content is only stored once.
+//
+//spellchecker:words chunks
+package chunks
+
+//spellchecker:words compress gzip context crypto sha256 encoding json errors path filepath slices strings syscall time pkglib errorsx umaskfree
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.tkw01536.de/pkglib/errorsx"
+	"go.tkw01536.de/pkglib/fsx/umaskfree"
+)
+
+// ChunkSize is the maximal size of a single chunk.
+const ChunkSize = 4 * 1024 * 1024 // 4 MiB
+
+// IndexExtension is the extension of index files.
+const IndexExtension = ".index.json.gz"
+
+// Store is a content-addressed store of chunks and indexes inside a local directory.
+//
+// Building an index and collecting unused chunks exclude each other using a lock file inside the directory.
+// This also holds across processes.
+type Store struct {
+	Dir string
+}
+
+// ErrBusy is returned by [Store.Collect] when an index is being built concurrently.
+var ErrBusy = errors.New("chunk store is in use by a running snapshot")
+
+// lock acquires a lock on the store, either shared or exclusive.
+// When the lock is held elsewhere, shared locks wait for it to be released, while exclusive locks return [ErrBusy].
+//
+// The lock is released by closing the returned file.
+func (store *Store) lock(exclusive bool) (_ *os.File, e error) {
+	if err := umaskfree.MkdirAll(store.Dir, umaskfree.DefaultDirPerm); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	// #nosec G304 -- path is not user controlled
+	file, err := os.OpenFile(filepath.Join(store.Dir, "lock"), os.O_CREATE|os.O_RDONLY, umaskfree.DefaultFilePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer func() {
+		if e != nil {
+			e = errorsx.Combine(e, file.Close())
+		}
+	}()
+
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX | syscall.LOCK_NB
+	}
+	// #nosec G115 -- file descriptors fit into an int
+	err = syscall.Flock(int(file.Fd()), how)
+	if exclusive && errors.Is(err, syscall.EWOULDBLOCK) {
+		return nil, ErrBusy
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock store: %w", err)
+	}
+	return file, nil
+}
+
+// chunkDir returns the directory holding all chunks.
+func (store *Store) chunkDir() string {
+	return filepath.Join(store.Dir, "data")
+}
+
+// IndexDir returns the directory holding all indexes.
+func (store *Store) IndexDir() string {
+	return filepath.Join(store.Dir, "index")
+}
+
+// IndexPath returns the path to the index with the given name.
+func (store *Store) IndexPath(name string) string {
+	return filepath.Join(store.IndexDir(), name+IndexExtension)
+}
+
+// chunkPath returns the path to the chunk with the given hash.
+func (store *Store) chunkPath(hash string) string {
+	return filepath.Join(store.chunkDir(), hash[:2], hash)
+}
+
+var errInvalidHash = errors.New("invalid chunk hash")
+
+// checkHash checks that hash is a valid chunk hash.
+func checkHash(hash string) error {
+	if len(hash) != 2*sha256.Size {
+		return fmt.Errorf("%w: %q", errInvalidHash, hash)
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return fmt.Errorf("%w: %q", errInvalidHash, hash)
+	}
+	return nil
+}
+
+// hasChunk checks if the chunk with the given hash exists.
+func (store *Store) hasChunk(hash string) (bool, error) {
+	_, err := os.Stat(store.chunkPath(hash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to stat chunk: %w", err)
+	}
+	return true, nil
+}
+
+// putChunk stores data as a chunk, unless a chunk with the same content already exists.
+// Returns the hash of the chunk, and if it was newly written.
+func (store *Store) putChunk(data []byte) (hash string, written bool, e error) {
+	sum := sha256.Sum256(data)
+	hash = hex.EncodeToString(sum[:])
+
+	exists, err := store.hasChunk(hash)
+	if err != nil {
+		return "", false, err
+	}
+	if exists {
+		return hash, false, nil
+	}
+
+	dir := filepath.Dir(store.chunkPath(hash))
+	if err := umaskfree.MkdirAll(dir, umaskfree.DefaultDirPerm); err != nil {
+		return "", false, fmt.Errorf("failed to create chunk directory: %w", err)
+	}
+
+	// write into a temporary file first, so that chunks are never partially written
+	file, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create chunk: %w", err)
+	}
+	defer func() {
+		if e != nil {
+			e = errorsx.Combine(e, os.Remove(file.Name()))
+		}
+	}()
+
+	_, err = file.Write(data)
+	err = errorsx.Combine(err, file.Close())
+	if err != nil {
+		return "", false, fmt.Errorf("failed to write chunk: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), store.chunkPath(hash)); err != nil {
+		return "", false, fmt.Errorf("failed to move chunk into place: %w", err)
+	}
+	return hash, true, nil
+}
+
+// Index describes the files of a snapshot.
+type Index struct {
+	// Parent is the name of the index this index was built from, if any.
+	Parent string `json:"parent,omitempty"`
+
+	// Files holds the files in the index by slash-separated relative path.
+	Files map[string]File `json:"files"`
+}
+
+// File is a single file inside an index.
+type File struct {
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"modtime"`
+	Size    int64       `json:"size"`
+
+	Link   string   `json:"link,omitempty"`   // link target for symlinks
+	Chunks []string `json:"chunks,omitempty"` // hashes of the content of regular files
+	Digest string   `json:"digest,omitempty"` // sha256 hash of the entire content of regular files
+}
+
+// unchanged checks if other describes the same file as file, without comparing content.
+func (file File) unchanged(other File) bool {
+	return file.Mode == other.Mode && file.Size == other.Size && file.ModTime.Equal(other.ModTime) && file.Link == other.Link
+}
+
+var (
+	errIndexOpen   = errors.New("failed to open index")
+	errIndexDecode = errors.New("failed to decode index")
+	errIndexName   = errors.New("invalid index name")
+)
+
+// ReadIndex reads the index with the given name.
+func (store *Store) ReadIndex(name string) (Index, error) {
+	if !filepath.IsLocal(name) || strings.ContainsRune(name, filepath.Separator) {
+		return Index{}, fmt.Errorf("%w: %q", errIndexName, name)
+	}
+	return ReadIndexFile(store.IndexPath(name))
+}
+
+// ReadIndexFile reads the index stored at path.
+func ReadIndexFile(path string) (_ Index, e error) {
+	file, err := os.Open(path) // #nosec G304 -- intended
+	if err != nil {
+		return Index{}, fmt.Errorf("%w: %w", errIndexOpen, err)
+	}
+	defer errorsx.Close(file, &e, "index")
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return Index{}, fmt.Errorf("%w: %w", errIndexDecode, err)
+	}
+	defer errorsx.Close(reader, &e, "gzip reader")
+
+	var index Index
+	if err := json.NewDecoder(reader).Decode(&index); err != nil {
+		return Index{}, fmt.Errorf("%w: %w", errIndexDecode, err)
+	}
+	return index, nil
+}
+
+// writeIndex writes the index with the given name.
+func (store *Store) writeIndex(name string, index Index) (path string, e error) {
+	if err := umaskfree.MkdirAll(store.IndexDir(), umaskfree.DefaultDirPerm); err != nil {
+		return "", fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	path = store.IndexPath(name)
+	file, err := umaskfree.Create(path, umaskfree.DefaultFilePerm)
+	if err != nil {
+		return "", fmt.Errorf("failed to create index: %w", err)
+	}
+	defer errorsx.Close(file, &e, "index")
+
+	writer := gzip.NewWriter(file)
+	defer errorsx.Close(writer, &e, "gzip writer")
+
+	if err := json.NewEncoder(writer).Encode(index); err != nil {
+		return "", fmt.Errorf("failed to encode index: %w", err)
+	}
+	return path, nil
+}
+
+// IndexInfo describes an index in the store.
+type IndexInfo struct {
+	Name     string
+	Path     string
+	Modified time.Time
+}
+
+// Indexes lists all indexes in the store.
+func (store *Store) Indexes() ([]IndexInfo, error) {
+	entries, err := os.ReadDir(store.IndexDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index directory: %w", err)
+	}
+
+	infos := make([]IndexInfo, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), IndexExtension)
+		if entry.IsDir() || !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entry info: %w", err)
+		}
+		infos = append(infos, IndexInfo{
+			Name:     name,
+			Path:     filepath.Join(store.IndexDir(), entry.Name()),
+			Modified: info.ModTime(),
+		})
+	}
+	return infos, nil
+}
+
+// DeleteIndex removes the index with the given name.
+// Chunks are only removed by [Store.Collect].
+func (store *Store) DeleteIndex(name string) error {
+	if err := os.Remove(store.IndexPath(name)); err != nil {
+		return fmt.Errorf("failed to remove index: %w", err)
+	}
+	return nil
+}
+
+// Collect removes all chunks that are not referenced by any index.
+// Returns the number of removed chunks.
+//
+// Chunks written by an index that is still being built are not referenced yet.
+// Collect thus returns [ErrBusy] without removing anything while any [Builder] of the store has not been closed.
+func (store *Store) Collect(ctx context.Context) (count int, e error) {
+	lock, err := store.lock(true)
+	if err != nil {
+		return 0, err
+	}
+	defer errorsx.Close(lock, &e, "lock")
+
+	infos, err := store.Indexes()
+	if err != nil {
+		return 0, err
+	}
+
+	// mark all the chunks that are still in use
+	used := make(map[string]struct{})
+	for _, info := range infos {
+		index, err := ReadIndexFile(info.Path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read index %q: %w", info.Name, err)
+		}
+		for _, file := range index.Files {
+			for _, hash := range file.Chunks {
+				used[hash] = struct{}{}
+			}
+		}
+	}
+
+	// and sweep all the others
+	err = filepath.WalkDir(store.chunkDir(), func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == store.chunkDir() {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context cancelled: %w", err)
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if _, ok := used[d.Name()]; ok {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove chunk: %w", err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to collect chunks: %w", err)
+	}
+	return count, nil
+}
+
+// paths returns the paths of all files in index, such that directories come before their contents.
+func (index Index) paths() []string {
+	paths := make([]string, 0, len(index.Files))
+	for path := range index.Files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// readChunk reads the chunk with the given hash, and verifies its content.
+func (store *Store) readChunk(hash string) ([]byte, error) {
+	if err := checkHash(hash); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(store.chunkPath(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != hash {
+		return nil, fmt.Errorf("%w: %q has been modified", errInvalidHash, hash)
+	}
+	return data, nil
+}
+
+// writeChunks writes the content of the given chunks into dst.
+func (store *Store) writeChunks(ctx context.Context, dst io.Writer, hashes []string) (count int64, err error) {
+	for _, hash := range hashes {
+		if err := ctx.Err(); err != nil {
+			return count, fmt.Errorf("context cancelled: %w", err)
+		}
+
+		data, err := store.readChunk(hash)
+		if err != nil {
+			return count, err
+		}
+
+		n, err := dst.Write(data)
+		count += int64(n)
+		if err != nil {
+			return count, fmt.Errorf("failed to write chunk: %w", err)
+		}
+	}
+	return count, nil
+}
+
+var errDigestMismatch = errors.New("content does not match digest")
+
+// Verify checks that the content of every regular file in index can be read from the store,
+// and matches the recorded digest of the file, if any.
+//
+// onFile, when not nil, is called for each file being verified.
+// Problems with individual files are joined into the returned error.
+func (store *Store) Verify(ctx context.Context, index Index, onFile func(rel string)) error {
+	var errs []error
+	for _, name := range index.paths() {
+		file := index.Files[name]
+		if !file.Mode.IsRegular() {
+			continue
+		}
+
+		if onFile != nil {
+			onFile(name)
+		}
+
+		sum := sha256.New()
+		if _, err := store.writeChunks(ctx, sum, file.Chunks); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			errs = append(errs, fmt.Errorf("%q: %w", name, err))
+			continue
+		}
+		if file.Digest != "" && hex.EncodeToString(sum.Sum(nil)) != file.Digest {
+			errs = append(errs, fmt.Errorf("%q: %w", name, errDigestMismatch))
+		}
+	}
+	return errors.Join(errs...)
+}
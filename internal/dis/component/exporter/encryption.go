@@ -0,0 +1,134 @@
+//spellchecker:words exporter
+package exporter
+
+//spellchecker:words bufio bytes errors filippo github wisski distillery internal component pkglib errorsx umaskfree
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"filippo.io/age"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/pkg/targz"
+	"go.tkw01536.de/pkglib/errorsx"
+	"go.tkw01536.de/pkglib/fsx/umaskfree"
+)
+
+// EncryptedExtension is appended to the name of automatically named encrypted archives.
+const EncryptedExtension = ".age"
+
+// ageHeader is the first line of every age-encrypted file.
+const ageHeader = "age-encryption.org/v1\n"
+
+// ErrNoIdentity is returned when decrypting an archive without any identity.
+var ErrNoIdentity = errors.New("archive is encrypted, but no identity to decrypt it was configured or provided")
+
+// Recipients returns the recipients new archives are encrypted to.
+// When encryption is not enabled, returns no recipients.
+func (exporter *Exporter) Recipients() ([]age.Recipient, error) {
+	config := component.GetStill(exporter).Config.Encryption
+
+	recipients := make([]age.Recipient, len(config.Recipients))
+	for i, key := range config.Recipients {
+		recipient, err := age.ParseX25519Recipient(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse recipient %q: %w", key, err)
+		}
+		recipients[i] = recipient
+	}
+	return recipients, nil
+}
+
+// Identities returns the identities read from the configured identity file, followed by the given identities.
+func (exporter *Exporter) Identities(extra ...age.Identity) ([]age.Identity, error) {
+	path := component.GetStill(exporter).Config.Encryption.IdentityFile
+	if path == "" {
+		return extra, nil
+	}
+
+	identities, err := ReadIdentities(path)
+	if err != nil {
+		return nil, err
+	}
+	return append(identities, extra...), nil
+}
+
+// ReadIdentities reads age identities from the file at path.
+func ReadIdentities(path string) (_ []age.Identity, e error) {
+	file, err := os.Open(path) // #nosec G304 -- intended
+	if err != nil {
+		return nil, fmt.Errorf("failed to open identity file: %w", err)
+	}
+	defer errorsx.Close(file, &e, "identity file")
+
+	identities, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identity file: %w", err)
+	}
+	return identities, nil
+}
+
+// writeArchive packages src into a new 'tar.gz' archive at dst.
+// If recipients are given, the archive is encrypted to them.
+func writeArchive(dst, src string, recipients []age.Recipient, onCopy func(rel string, src string)) (count int64, e error) {
+	if len(recipients) == 0 {
+		count, err := targz.Package(dst, src, onCopy)
+		if err != nil {
+			return count, fmt.Errorf("failed to package archive: %w", err)
+		}
+		return count, nil
+	}
+
+	archive, err := umaskfree.Create(dst, umaskfree.DefaultFilePerm)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer errorsx.Close(archive, &e, "archive file")
+
+	encrypted, err := age.Encrypt(archive, recipients...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt archive: %w", err)
+	}
+	defer errorsx.Close(encrypted, &e, "encrypted stream")
+
+	count, err = targz.Write(encrypted, src, onCopy)
+	if err != nil {
+		return count, fmt.Errorf("failed to package archive: %w", err)
+	}
+	return count, nil
+}
+
+// IsEncrypted checks if the stream read by reader starts with an age header.
+// The reader is not advanced.
+func IsEncrypted(reader *bufio.Reader) bool {
+	header, _ := reader.Peek(len(ageHeader)) // a short stream is never encrypted
+	return bytes.Equal(header, []byte(ageHeader))
+}
+
+// Decrypt returns a reader for the decrypted content of src.
+// If src is not encrypted, it is returned as is.
+//
+// Encrypted content is decrypted using the configured identities and the given ones.
+func (exporter *Exporter) Decrypt(src io.Reader, extra ...age.Identity) (io.Reader, error) {
+	reader := bufio.NewReader(src)
+	if !IsEncrypted(reader) {
+		return reader, nil
+	}
+
+	identities, err := exporter.Identities(extra...)
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return nil, ErrNoIdentity
+	}
+
+	decrypted, err := age.Decrypt(reader, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt archive: %w", err)
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,72 @@
+//spellchecker:words exporter
+package exporter
+
+//spellchecker:words errors maps slices pkglib errorsx
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"go.tkw01536.de/pkglib/errorsx"
+)
+
+var (
+	errExportFailed = errors.New("export failed or is incomplete")
+	errExportPanic  = errors.New("export panicked")
+)
+
+// panicError turns a value stored in an ErrPanic field into an error.
+func panicError(value any) error {
+	if err, ok := value.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%w: %v", errExportPanic, value)
+}
+
+// partErrors returns the non-nil errors in errs, sorted by name and prefixed with the name.
+func partErrors(errs map[string]error) error {
+	var result error
+	for _, name := range slices.Sorted(maps.Keys(errs)) {
+		if err := errs[name]; err != nil {
+			result = errorsx.Combine(result, fmt.Errorf("%q: %w", name, err))
+		}
+	}
+	return result
+}
+
+// Err returns an error if creating the snapshot failed, or any of its parts failed.
+// It returns nil if the snapshot is complete.
+func (snapshot Snapshot) Err() (err error) {
+	if snapshot.ErrPanic != nil {
+		err = errorsx.Combine(err, panicError(snapshot.ErrPanic))
+	}
+	if snapshot.ErrStop != nil {
+		err = errorsx.Combine(err, fmt.Errorf("failed to stop instance: %w", snapshot.ErrStop))
+	}
+	if snapshot.ErrStart != nil {
+		err = errorsx.Combine(err, fmt.Errorf("failed to start instance: %w", snapshot.ErrStart))
+	}
+	return errorsx.Combine(err, partErrors(snapshot.Errors))
+}
+
+// Err returns an error if creating the backup failed, or any of its components or instance snapshots failed.
+// It returns nil if the backup is complete.
+func (backup Backup) Err() (err error) {
+	if backup.ErrPanic != nil {
+		err = errorsx.Combine(err, panicError(backup.ErrPanic))
+	}
+	if backup.ConfigFileErr != nil {
+		err = errorsx.Combine(err, fmt.Errorf("failed to backup configuration file: %w", backup.ConfigFileErr))
+	}
+	if backup.InstanceListErr != nil {
+		err = errorsx.Combine(err, fmt.Errorf("failed to list instances: %w", backup.InstanceListErr))
+	}
+	err = errorsx.Combine(err, partErrors(backup.ComponentErrors))
+	for _, snapshot := range backup.InstanceSnapshots {
+		if serr := snapshot.Err(); serr != nil {
+			err = errorsx.Combine(err, fmt.Errorf("snapshot %q: %w", snapshot.Instance.Slug, serr))
+		}
+	}
+	return err
+}
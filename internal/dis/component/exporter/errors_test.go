@@ -0,0 +1,82 @@
+package exporter_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+var errTest = errors.New("test error")
+
+func TestSnapshotErr(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		Name     string
+		Snapshot exporter.Snapshot
+		WantErr  bool
+	}{
+		{
+			Name: "complete snapshot",
+			Snapshot: exporter.Snapshot{
+				Errors: map[string]error{"data": nil, "sql": nil},
+			},
+			WantErr: false,
+		},
+		{
+			Name:     "instance could not be locked",
+			Snapshot: exporter.Snapshot{ErrPanic: errTest},
+			WantErr:  true,
+		},
+		{
+			Name:     "panic",
+			Snapshot: exporter.Snapshot{ErrPanic: "something went wrong"},
+			WantErr:  true,
+		},
+		{
+			Name: "failed part",
+			Snapshot: exporter.Snapshot{
+				Errors: map[string]error{"data": nil, "sql": errTest},
+			},
+			WantErr: true,
+		},
+		{
+			Name:     "instance could not be started",
+			Snapshot: exporter.Snapshot{ErrStart: errTest},
+			WantErr:  true,
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.Snapshot.Err()
+			if (err != nil) != tt.WantErr {
+				t.Errorf("Err() = %v, want error %v", err, tt.WantErr)
+			}
+		})
+	}
+}
+
+func TestBackupErr(t *testing.T) {
+	t.Parallel()
+
+	complete := exporter.Backup{
+		ComponentErrors:   map[string]error{"config": nil},
+		InstanceSnapshots: []exporter.Snapshot{{}},
+	}
+	if err := complete.Err(); err != nil {
+		t.Errorf("complete backup: Err() = %v, want nil", err)
+	}
+
+	failed := exporter.Backup{
+		InstanceSnapshots: []exporter.Snapshot{
+			{},
+			{Instance: models.Instance{Slug: "broken"}, ErrPanic: errTest},
+		},
+	}
+	if err := failed.Err(); !errors.Is(err, errTest) {
+		t.Errorf("backup with failed snapshot: Err() = %v, want %v", err, errTest)
+	}
+}
@@ -1,7 +1,7 @@
 //spellchecker:words exporter
 package exporter
 
-//spellchecker:words crypto rand errors path filepath time github wisski distillery internal component exporter logger instances passwordx pkglib umaskfree password
+//spellchecker:words crypto rand errors path filepath time github wisski distillery internal component exporter logger instances meta passwordx pkglib umaskfree password
 import (
 	"crypto/rand"
 	"errors"
@@ -13,6 +13,7 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/logger"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/meta"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
 	"github.com/FAU-CDI/wisski-distillery/internal/passwordx"
 	"go.tkw01536.de/pkglib/fsx"
@@ -27,6 +28,7 @@ type Exporter struct {
 		SQL            *sql.SQL
 		Instances      *instances.Instances
 		ExporterLogger *logger.Logger
+		Meta           *meta.Meta
 
 		Snapshotable []component.Snapshotable
 		Backupable   []component.Backupable
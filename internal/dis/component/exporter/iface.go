@@ -1,7 +1,7 @@
 //spellchecker:words exporter
 package exporter
 
-//spellchecker:words context errors path filepath github wisski distillery internal component models logging targz pkglib collection umaskfree status
+//spellchecker:words context errors path filepath github wisski distillery internal component exporter chunks storage models logging pkglib collection errorsx umaskfree status
 import (
 	"context"
 	"errors"
@@ -12,11 +12,13 @@ import (
 	"path/filepath"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/chunks"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/storage"
 	"github.com/FAU-CDI/wisski-distillery/internal/models"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
 	"github.com/FAU-CDI/wisski-distillery/pkg/logging"
-	"github.com/FAU-CDI/wisski-distillery/pkg/targz"
 	"go.tkw01536.de/pkglib/collection"
+	"go.tkw01536.de/pkglib/errorsx"
 	"go.tkw01536.de/pkglib/fsx/umaskfree"
 	"go.tkw01536.de/pkglib/status"
 )
@@ -40,6 +42,14 @@ type ExportTask struct {
 	// To generate a backup, leave this to be nil.
 	Instance *wisski.WissKI
 
+	// Incremental stores the snapshot in the content-addressed chunk store instead of an archive.
+	// Files that did not change since the previous incremental snapshot of the instance are not copied again,
+	// and only new chunks of changed files are stored.
+	//
+	// Incremental is only supported for snapshots, and cannot be combined with Dest or StagingOnly.
+	// Incremental snapshots are always kept on the local disk, and are refused when encryption recipients are configured.
+	Incremental bool
+
 	// BackupDescriptions and SnapshotDescriptions further specitfy options for the export.
 	// The Dest parameter is ignored, and updated automatically.
 	BackupDescription   BackupDescription
@@ -53,6 +63,10 @@ type export interface {
 	ReportPlain(w io.Writer) error
 	// ReportMachine writes a machine readable report summary into w
 	ReportMachine(w io.Writer) error
+	// Err returns an error if the export failed, or is incomplete
+	Err() error
+
+	computeDigests(root string, stored map[string]string) error
 }
 
 // Parts lists all available snapshot parts.
@@ -67,6 +81,8 @@ const (
 
 // MakeExport performs an export task as described by flags.
 // Output is directed to the provided io.
+//
+// If the export itself fails or is incomplete, it is still stored and a non-nil error is returned.
 func (exporter *Exporter) MakeExport(ctx context.Context, progress io.Writer, task ExportTask) (err error) {
 	// extract parameters
 	Title := "Backup"
@@ -76,6 +92,20 @@ func (exporter *Exporter) MakeExport(ctx context.Context, progress io.Writer, ta
 		Slug = task.Instance.Slug
 	}
 
+	if task.Incremental && (task.Instance == nil || task.StagingOnly || task.Dest != "") {
+		return errIncrementalOptions
+	}
+
+	// determine who to encrypt archives to
+	recipients, err := exporter.Recipients()
+	if err != nil {
+		return fmt.Errorf("failed to read encryption recipients: %w", err)
+	}
+	if task.Incremental && len(recipients) > 0 {
+		return errIncrementalEncrypted
+	}
+	encrypt := !task.StagingOnly && len(recipients) > 0
+
 	// determine target paths
 	if _, err := logging.LogMessage(progress, "Determining target paths"); err != nil {
 		return fmt.Errorf("failed to log message: %w", err)
@@ -92,8 +122,12 @@ func (exporter *Exporter) MakeExport(ctx context.Context, progress io.Writer, ta
 			return err
 		}
 	}
-	if !task.StagingOnly && archivePath == "" {
+
+	if !task.StagingOnly && !task.Incremental && archivePath == "" {
 		archivePath = exporter.NewArchivePath(Slug)
+		if encrypt {
+			archivePath += EncryptedExtension
+		}
 	}
 	_, _ = fmt.Fprintf(progress, "Staging Directory: %s\n", stagingDir)
 	_, _ = fmt.Fprintf(progress, "Archive Path:      %s\n", archivePath)
@@ -117,11 +151,33 @@ func (exporter *Exporter) MakeExport(ctx context.Context, progress io.Writer, ta
 		}()
 	}
 
+	// incremental snapshots store files in the chunk store
+	var builder *chunks.Builder
+	if task.Incremental {
+		builder, err = exporter.newIncrementalBuilder(ctx, progress, stagingDir, Slug)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err = errorsx.Combine(err, builder.Close())
+		}()
+		task.SnapshotDescription.Incremental = true
+		task.SnapshotDescription.dedup = builder
+	}
+
 	// create the actual snapshot or backup
 	// write out the report
 	// and retain a log entry
+	//
+	// A failed or incomplete export is still stored, but reported to the caller once it has been.
 	var entry models.Export
-	_ = logging.LogOperation(func() error {
+	var exportErr error
+	defer func() {
+		if exportErr != nil {
+			err = errorsx.Combine(err, fmt.Errorf("%w: %w", errExportFailed, exportErr))
+		}
+	}()
+	exportErr = logging.LogOperation(func() error {
 		var export export
 		if task.Instance == nil {
 			task.BackupDescription.Dest = stagingDir
@@ -135,6 +191,16 @@ func (exporter *Exporter) MakeExport(ctx context.Context, progress io.Writer, ta
 
 		// create a log entry
 		entry = export.LogEntry()
+		failure := export.Err()
+
+		// record the digests of all files
+		var stored map[string]string
+		if builder != nil {
+			stored = builder.Digests()
+		}
+		if err := export.computeDigests(stagingDir, stored); err != nil {
+			return errorsx.Combine(failure, err)
+		}
 
 		// write the machine report
 		{
@@ -143,11 +209,11 @@ func (exporter *Exporter) MakeExport(ctx context.Context, progress io.Writer, ta
 
 			report, err := umaskfree.Create(reportPath, umaskfree.DefaultFilePerm)
 			if err != nil {
-				return fmt.Errorf("failed to create report file: %w", err)
+				return errorsx.Combine(failure, fmt.Errorf("failed to create report file: %w", err))
 			}
 
 			if err := export.ReportMachine(report); err != nil {
-				return fmt.Errorf("failed to generate report: %w", err)
+				return errorsx.Combine(failure, fmt.Errorf("failed to generate report: %w", err))
 			}
 		}
 
@@ -158,15 +224,15 @@ func (exporter *Exporter) MakeExport(ctx context.Context, progress io.Writer, ta
 
 			report, err := umaskfree.Create(reportPath, umaskfree.DefaultFilePerm)
 			if err != nil {
-				return fmt.Errorf("failed to create file: %w", err)
+				return errorsx.Combine(failure, fmt.Errorf("failed to create file: %w", err))
 			}
 
 			if err := export.ReportPlain(report); err != nil {
-				return fmt.Errorf("failed to generate report: %w", err)
+				return errorsx.Combine(failure, fmt.Errorf("failed to generate report: %w", err))
 			}
 		}
 
-		return nil
+		return failure
 	}, progress, "Generating %s", Title)
 
 	// if we only requested staging
@@ -187,6 +253,10 @@ func (exporter *Exporter) MakeExport(ctx context.Context, progress io.Writer, ta
 		return nil
 	}
 
+	if task.Incremental {
+		return exporter.commitIncremental(ctx, progress, builder, stagingDir, entry)
+	}
+
 	if err := logging.LogOperation(func() error {
 		var count int64
 		defer func() { _, _ = fmt.Fprintf(progress, "Wrote %d byte(s) to %s\n", count, archivePath) }()
@@ -195,27 +265,63 @@ func (exporter *Exporter) MakeExport(ctx context.Context, progress io.Writer, ta
 		st.Start()
 		defer st.Stop()
 
-		count, err = targz.Package(archivePath, stagingDir, func(dst, src string) {
+		if encrypt {
+			_, _ = fmt.Fprintf(progress, "Encrypting archive to %d recipient(s)\n", len(recipients))
+		} else {
+			recipients = nil
+		}
+
+		count, err = writeArchive(archivePath, stagingDir, recipients, func(dst, src string) {
 			st.Set(0, dst)
 		})
-
-		if err != nil {
-			return fmt.Errorf("failed to package archive: %w", err)
-		}
-		return nil
+		return err
 	}, progress, "Writing archive"); err != nil {
 		return fmt.Errorf("failed to write archive: %w", err)
 	}
 
+	entry.Path = archivePath
+	entry.Packed = true
+	entry.Target = storage.LocalName
+	entry.Encrypted = encrypt
+
+	entry.Digest, err = FileDigest(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to compute archive digest: %w", err)
+	}
+	_, _ = fmt.Fprintf(progress, "Archive Digest: %s\n", entry.Digest)
+
+	// store archives at automatically generated paths in the configured target.
+	// if this fails, the archive remains in the local archive directory.
+	var errStore error
+	if task.Dest == "" {
+		errStore = logging.LogOperation(func() error {
+			target, err := exporter.Target()
+			if err != nil {
+				return err
+			}
+
+			location, err := target.Upload(ctx, archivePath, filepath.Base(archivePath))
+			if err != nil {
+				return fmt.Errorf("failed to upload archive: %w", err)
+			}
+			_, _ = fmt.Fprintf(progress, "Stored archive in %s target at %s\n", target.Name(), location)
+
+			entry.Path = location
+			entry.Target = target.Name()
+			return nil
+		}, progress, "Storing archive")
+	}
+
 	if _, err := logging.LogMessage(progress, "Writing Log Entry"); err != nil {
 		return fmt.Errorf("failed to log message: %w", err)
 	}
 
-	entry.Path = archivePath
-	entry.Packed = true
-
 	if err := exporter.dependencies.ExporterLogger.Add(ctx, entry); err != nil {
 		return fmt.Errorf("failed to log backup: %w", err)
 	}
+
+	if errStore != nil {
+		return fmt.Errorf("failed to store archive: %w", errStore)
+	}
 	return nil
 }
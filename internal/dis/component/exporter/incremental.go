@@ -0,0 +1,206 @@
+//spellchecker:words exporter
+package exporter
+
+//spellchecker:words context errors path filepath strings github wisski distillery internal component exporter chunks storage models logging pkglib status
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/chunks"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/storage"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/pkg/logging"
+	"go.tkw01536.de/pkglib/status"
+)
+
+// ChunksPath returns the path to the content-addressed store holding incremental snapshots.
+func (exporter *Exporter) ChunksPath() string {
+	return filepath.Join(exporter.Path(), "chunks")
+}
+
+// Chunks returns the content-addressed store holding incremental snapshots.
+func (exporter *Exporter) Chunks() *chunks.Store {
+	return &chunks.Store{Dir: exporter.ChunksPath()}
+}
+
+var errIncrementalOptions = errors.New("incremental exports must be snapshots, and cannot use a destination or staging only")
+
+var errIncrementalEncrypted = errors.New("incremental snapshots cannot be encrypted: remove the encryption recipients or take a regular snapshot")
+
+// newIncrementalBuilder creates a builder for a new incremental snapshot of the instance with the given slug.
+// Files are compared against the most recent incremental snapshot of the same instance, if any.
+func (exporter *Exporter) newIncrementalBuilder(ctx context.Context, progress io.Writer, stagingDir string, slug string) (*chunks.Builder, error) {
+	exports, err := exporter.dependencies.ExporterLogger.For(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list previous snapshots: %w", err)
+	}
+
+	// find the most recent incremental snapshot
+	var previous models.Export
+	for _, export := range exports {
+		if export.Incremental && export.Created.After(previous.Created) {
+			previous = export
+		}
+	}
+
+	var parent string
+	if previous.Incremental {
+		parent = IncrementalName(previous)
+	}
+
+	if parent != "" {
+		_, _ = fmt.Fprintf(progress, "Previous Snapshot: %s\n", parent)
+	} else {
+		_, _ = fmt.Fprintln(progress, "Previous Snapshot: none, storing all files")
+	}
+
+	builder, err := exporter.Chunks().NewBuilder(stagingDir, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index builder: %w", err)
+	}
+	return builder, nil
+}
+
+// IncrementalName returns the name of the index of an incremental snapshot.
+func IncrementalName(export models.Export) string {
+	return strings.TrimSuffix(filepath.Base(export.Path), chunks.IndexExtension)
+}
+
+// commitIncremental stores the remaining files of the staging directory in the chunk store,
+// writes the index of the snapshot, and adds entry to the log.
+func (exporter *Exporter) commitIncremental(ctx context.Context, progress io.Writer, builder *chunks.Builder, stagingDir string, entry models.Export) error {
+	var indexPath string
+	if err := logging.LogOperation(func() error {
+		st := status.NewWithCompat(progress, 1)
+		st.Start()
+		defer st.Stop()
+
+		if err := builder.Add(ctx, stagingDir, stagingDir, func(dst string) {
+			st.Set(0, dst)
+		}); err != nil {
+			return fmt.Errorf("failed to store files: %w", err)
+		}
+
+		var err error
+		indexPath, err = builder.Commit(filepath.Base(stagingDir))
+		if err != nil {
+			return fmt.Errorf("failed to write index: %w", err)
+		}
+
+		stats := builder.Stats()
+		_, _ = fmt.Fprintf(progress, "Stored %d file(s), %d unchanged\n", stats.Files, stats.Reused)
+		_, _ = fmt.Fprintf(progress, "Wrote %d new chunk(s) of %d, %d byte(s)\n", stats.Written, stats.Chunks, stats.WrittenBytes)
+		_, _ = fmt.Fprintf(progress, "Wrote index %s\n", indexPath)
+		return nil
+	}, progress, "Storing incremental snapshot"); err != nil {
+		return fmt.Errorf("failed to store incremental snapshot: %w", err)
+	}
+
+	if _, err := logging.LogMessage(progress, "Writing Log Entry"); err != nil {
+		return fmt.Errorf("failed to log message: %w", err)
+	}
+
+	entry.Path = indexPath
+	entry.Packed = false
+	entry.Incremental = true
+	entry.Target = storage.LocalName
+
+	digest, err := FileDigest(indexPath)
+	if err != nil {
+		return fmt.Errorf("failed to compute index digest: %w", err)
+	}
+	entry.Digest = digest
+
+	if err := exporter.dependencies.ExporterLogger.Add(ctx, entry); err != nil {
+		return fmt.Errorf("failed to log snapshot: %w", err)
+	}
+	return nil
+}
+
+// assembleIncremental assembles the incremental snapshot with the index at path into a new temporary directory inside the staging area.
+// If assembling fails, the caller is responsible for removing the returned directory.
+func (exporter *Exporter) assembleIncremental(ctx context.Context, progress io.Writer, path string) (dir string, e error) {
+	index, err := chunks.ReadIndexFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read index: %w", err)
+	}
+
+	dir, err = os.MkdirTemp(exporter.StagingPath(), "restore-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	e = logging.LogOperation(func() error {
+		var count int64
+		defer func() { _, _ = fmt.Fprintf(progress, "Assembled %d byte(s) into %s\n", count, dir) }()
+
+		st := status.NewWithCompat(progress, 1)
+		st.Start()
+		defer st.Stop()
+
+		count, err = exporter.Chunks().Assemble(ctx, index, dir, func(rel, dst string) {
+			st.Set(0, rel)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to assemble snapshot: %w", err)
+		}
+		return nil
+	}, progress, "Assembling incremental snapshot %s", path)
+	return dir, e
+}
+
+// pruneIncremental removes untracked incremental snapshots that are too old, and removes chunks no longer referenced.
+// Tracked incremental snapshots are pruned like any other export.
+func (exporter *Exporter) pruneIncremental(ctx context.Context, progress io.Writer, dryRun bool) error {
+	store := exporter.Chunks()
+
+	exports, err := exporter.dependencies.ExporterLogger.Log(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read export log: %w", err)
+	}
+	tracked := make(map[string]struct{})
+	for _, export := range exports {
+		if export.Incremental {
+			tracked[IncrementalName(export)] = struct{}{}
+		}
+	}
+
+	infos, err := store.Indexes()
+	if err != nil {
+		return fmt.Errorf("failed to list incremental snapshots: %w", err)
+	}
+
+	for _, info := range infos {
+		if _, ok := tracked[info.Name]; ok || !exporter.ShouldPrune(info.Modified) {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(progress, "Removing untracked incremental snapshot %s cause it is too old\n", info.Name)
+		if dryRun {
+			continue
+		}
+		if err := store.DeleteIndex(info.Name); err != nil {
+			return fmt.Errorf("failed to remove incremental snapshot: %w", err)
+		}
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	count, err := store.Collect(ctx)
+	if errors.Is(err, chunks.ErrBusy) {
+		_, _ = fmt.Fprintln(progress, "Not removing unused chunks, a snapshot is in progress")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove unused chunks: %w", err)
+	}
+	_, _ = fmt.Fprintf(progress, "Removed %d unused chunk(s)\n", count)
+	return nil
+}
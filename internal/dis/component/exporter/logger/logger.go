@@ -1,10 +1,12 @@
 //spellchecker:words logger
 package logger
 
-//spellchecker:words context github wisski distillery internal component models status wdlog pkglib collection
+//spellchecker:words context errors time github wisski distillery internal component models status wdlog pkglib collection
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
@@ -46,43 +48,101 @@ func (log *Logger) For(ctx context.Context, slug string) (exports []models.Expor
 	}), nil
 }
 
+// ErrExportMissing is the problem recorded for entries whose export no longer exists.
+var ErrExportMissing = errors.New("export is missing")
+
 // Log retrieves and cleans up all entries in the snapshot log.
+// Only entries of exports that still exist are returned.
+//
+// Entries that no longer exist are removed, unless they record a digest.
+// Those are kept and flagged as missing instead, see [Logger.Missing].
 func (log *Logger) Log(ctx context.Context) ([]models.Export, error) {
+	exports, _, err := log.entries(ctx)
+	return exports, err
+}
+
+// Missing returns the entries of the snapshot log whose export no longer exists, but that record a digest.
+// These are flagged with [ErrExportMissing] as their problem.
+// They are kept until they are pruned by the exporter.
+func (log *Logger) Missing(ctx context.Context) ([]models.Export, error) {
+	_, missing, err := log.entries(ctx)
+	return missing, err
+}
+
+// entries retrieves and cleans up all entries in the snapshot log.
+// It returns the entries of exports that exist, and the entries that are flagged as missing.
+func (log *Logger) entries(ctx context.Context) (exports []models.Export, missing []models.Export, err error) {
 	table, err := sql.OpenInterface[models.Export](ctx, log.dependencies.SQL, log)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open interface: %w", err)
+		return nil, nil, fmt.Errorf("failed to open interface: %w", err)
 	}
 
 	// find all the exports
-	exports, err := table.Find(ctx)
+	all, err := table.Find(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve existing exports: %w", err)
+		return nil, nil, fmt.Errorf("failed to retrieve existing exports: %w", err)
 	}
 
-	// partition out the exports that no longer exist!
-	parts := collection.Partition(exports, func(s models.Export) bool {
-		exists, err := s.Exists()
-		if err == nil {
-			return exists
+	var gone []uint
+	for _, export := range all {
+		exists, err := export.Exists()
+		if err != nil {
+			wdlog.Of(ctx).Error(
+				"unable to check if export exists, skipping pruning",
+				"error", err,
+				"pk", export.Pk,
+			)
+			exists = true
 		}
 
-		wdlog.Of(ctx).Error(
-			"unable to check if export exists, skipping pruning",
-			"error", err,
-			"pk", s.Pk,
-		)
-		return true
-	})
-
-	// delete the parts which no longer exist
-	if len(parts[false]) > 0 {
-		pks := collection.MapSlice(parts[false], func(s models.Export) uint { return s.Pk })
-		if _, err := table.Where("pk in ?", pks).Delete(ctx); err != nil {
-			return nil, fmt.Errorf("failed to remove old export entries: %w", err)
+		flagged := export.Problem == ErrExportMissing.Error()
+		switch {
+		case exists && !flagged:
+			exports = append(exports, export)
+		case !exists && export.Digest == "":
+			gone = append(gone, export.Pk)
+		default:
+			// keep exports with a digest, so that they are reported as missing until they are pruned.
+			// remote exports are flagged by verification, and local ones here.
+			if !flagged {
+				now := time.Now().UTC()
+				export.Verified = &now
+				export.Problem = ErrExportMissing.Error()
+				if _, err := table.Where("pk = ?", export.Pk).Select("verified", "problem").Updates(ctx, export); err != nil {
+					return nil, nil, fmt.Errorf("failed to flag export entry as missing: %w", err)
+				}
+			}
+			missing = append(missing, export)
 		}
 	}
 
-	return parts[true], nil
+	// delete the entries which no longer exist
+	if len(gone) > 0 {
+		if _, err := table.Where("pk in ?", gone).Delete(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to remove old export entries: %w", err)
+		}
+	}
+
+	return exports, missing, nil
+}
+
+// ErrExportNotFound is returned by [Logger.Get] when an entry does not exist.
+var ErrExportNotFound = errors.New("export log entry not found")
+
+// Get retrieves the entry with the given primary key from the export log.
+// If the entry does not exist, or its export is missing, returns [ErrExportNotFound].
+func (log *Logger) Get(ctx context.Context, pk uint) (models.Export, error) {
+	exports, err := log.Log(ctx)
+	if err != nil {
+		return models.Export{}, err
+	}
+
+	for _, export := range exports {
+		if export.Pk == pk {
+			return export, nil
+		}
+	}
+	return models.Export{}, ErrExportNotFound
 }
 
 // AddToExportLog adds the provided export to the log.
@@ -98,6 +158,42 @@ func (log *Logger) Add(ctx context.Context, export models.Export) error {
 	return nil
 }
 
+// Remove removes the entries stored at the given paths in the given target from the log.
+func (log *Logger) Remove(ctx context.Context, target string, paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	table, err := sql.OpenInterface[models.Export](ctx, log.dependencies.SQL, log)
+	if err != nil {
+		return fmt.Errorf("failed to open interface: %w", err)
+	}
+
+	targets := []string{target}
+	if (models.Export{Target: target}).IsLocal() {
+		targets = []string{"", target}
+	}
+
+	if _, err := table.Where("target in ? AND path in ?", targets, paths).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to remove export entries: %w", err)
+	}
+	return nil
+}
+
+// SetVerification records the result of verifying the entry with the given primary key.
+// An empty problem indicates that the entry was verified successfully.
+func (log *Logger) SetVerification(ctx context.Context, pk uint, verified time.Time, problem string) error {
+	table, err := sql.OpenInterface[models.Export](ctx, log.dependencies.SQL, log)
+	if err != nil {
+		return fmt.Errorf("failed to open interface: %w", err)
+	}
+
+	if _, err := table.Where("pk = ?", pk).Select("verified", "problem").Updates(ctx, models.Export{Verified: &verified, Problem: problem}); err != nil {
+		return fmt.Errorf("failed to update export entry: %w", err)
+	}
+	return nil
+}
+
 // Fetch writes the SnapshotLog into the given observation.
 func (logger *Logger) Fetch(ctx context.Context, flags component.FetcherFlags, target *status.Distillery) (err error) {
 	target.Backups, err = logger.For(ctx, "")
@@ -1,13 +1,25 @@
 //spellchecker:words exporter
 package exporter
 
-//spellchecker:words path filepath
+//spellchecker:words crypto sha256 encoding path filepath pkglib errorsx
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
 	"path/filepath"
+
+	"go.tkw01536.de/pkglib/errorsx"
 )
 
 type WithManifest struct {
 	Manifest []string
+
+	// Digests holds the hex-encoded sha256 digests of all regular files in the export.
+	// Keys are slash-separated paths relative to the root of the export.
+	Digests map[string]string `json:"Digests,omitempty"`
 }
 
 func (wm *WithManifest) handleManifest(dest string) (chan<- string, func()) {
@@ -33,3 +45,51 @@ func (wm *WithManifest) handleManifest(dest string) (chan<- string, func()) {
 		<-done
 	}
 }
+
+// computeDigests computes the digests of all regular files inside root.
+// Digests of files that are not stored inside root, such as those of incremental snapshots, are taken from stored.
+func (wm *WithManifest) computeDigests(root string, stored map[string]string) error {
+	wm.Digests = make(map[string]string, len(stored))
+	for name, digest := range stored {
+		wm.Digests[name] = digest
+	}
+
+	if err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path for %q: %w", path, err)
+		}
+
+		digest, err := FileDigest(path)
+		if err != nil {
+			return err
+		}
+		wm.Digests[filepath.ToSlash(rel)] = digest
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to compute digests: %w", err)
+	}
+	return nil
+}
+
+// FileDigest returns the hex-encoded sha256 digest of the file at path.
+func FileDigest(path string) (digest string, e error) {
+	file, err := os.Open(path) // #nosec G304 -- intended
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer errorsx.Close(file, &e, "file")
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
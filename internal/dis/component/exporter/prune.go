@@ -1,16 +1,21 @@
 //spellchecker:words exporter
 package exporter
 
-//spellchecker:words context path filepath time github wisski distillery internal component
+//spellchecker:words context errors path filepath slices time github wisski distillery internal component exporter storage models
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/storage"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
 )
 
 // ShouldPrune determines if a file with the provided modification time should be
@@ -19,43 +24,118 @@ func (exporter *Exporter) ShouldPrune(modtime time.Time) bool {
 	return time.Since(modtime) > component.GetStill(exporter).Config.MaxBackupAge
 }
 
-// Prune prunes all old exports.
-// TODO: Don't call this automatically!
-func (exporter *Exporter) PruneExports(ctx context.Context, progress io.Writer) error {
-	sPath := exporter.ArchivePath()
-
-	// list all the files
-	entries, err := os.ReadDir(sPath)
+// PrunePlan evaluates the retention policies against the export log.
+//
+// Exports of each instance (and system backups) are evaluated against their retention policy.
+// If no policy is set, exports older than the maximum backup age are pruned.
+// Exports stored at a custom destination are not managed by the distillery, and never included.
+func (exporter *Exporter) PrunePlan(ctx context.Context) ([]models.RetentionDecision, error) {
+	exports, err := exporter.dependencies.ExporterLogger.Log(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to read achive path: %w", err)
+		return nil, fmt.Errorf("failed to read export log: %w", err)
 	}
 
-	for _, entry := range entries {
-		// skip directories
-		if entry.IsDir() {
+	// group the managed exports by slug
+	var slugs []string
+	groups := make(map[string][]models.Export)
+	for _, export := range exports {
+		if !exporter.isManaged(export) {
 			continue
 		}
+		if _, ok := groups[export.Slug]; !ok {
+			slugs = append(slugs, export.Slug)
+		}
+		groups[export.Slug] = append(groups[export.Slug], export)
+	}
+	slices.Sort(slugs)
 
-		// grab info about the file
-		info, err := entry.Info()
+	decisions := make([]models.RetentionDecision, 0, len(exports))
+	for _, slug := range slugs {
+		policy, err := exporter.RetentionPolicy(ctx, slug)
 		if err != nil {
-			return fmt.Errorf("failed to get entry info: %w", err)
+			return nil, err
 		}
 
-		// check if it should be pruned!
-		if !exporter.ShouldPrune(info.ModTime()) {
-			continue
+		// evaluate the policy, unless it isn't set
+		group := policy.Evaluate(groups[slug])
+		if policy.IsZero() {
+			maxAge := component.GetStill(exporter).Config.MaxBackupAge.String()
+			for i := range group {
+				group[i].Keep = !exporter.ShouldPrune(group[i].Export.Created)
+				if group[i].Keep {
+					group[i].Reasons = []string{"younger than " + maxAge}
+				} else {
+					group[i].Reasons = []string{"older than " + maxAge}
+				}
+			}
+		}
+		decisions = append(decisions, group...)
+	}
+
+	return decisions, nil
+}
+
+// isManaged checks if the given export is stored in a location managed by the exporter.
+func (exporter *Exporter) isManaged(export models.Export) bool {
+	if export.Incremental || !export.IsLocal() {
+		return true
+	}
+	rel, err := filepath.Rel(exporter.Path(), export.Path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// PruneExports prunes old exports according to [Exporter.PrunePlan].
+// Log entries of missing exports are removed once they are older than the maximum backup age.
+// Archives that are not in the export log are pruned from the local archive directory and the configured storage target
+// once they are older than the maximum backup age.
+//
+// When dryRun is true, only prints what would be pruned.
+func (exporter *Exporter) PruneExports(ctx context.Context, progress io.Writer, dryRun bool) error {
+	decisions, err := exporter.PrunePlan(ctx)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		_, _ = fmt.Fprintln(progress, "Dry run, nothing will be removed")
+	}
+
+	for _, decision := range decisions {
+		export := decision.Export
+
+		name := export.Slug
+		if name == "" {
+			name = "backup"
 		}
+		_, _ = fmt.Fprintf(progress, "%s %s %s: %s\n", name, export.Created.Format(time.RFC3339), export.Path, decision.Reason())
 
-		// assemble path, and then remove the file!
-		path := filepath.Join(sPath, entry.Name())
-		_, _ = fmt.Fprintf(progress, "Removing %s cause it is older than %d days\n", path, component.GetStill(exporter).Config.MaxBackupAge)
+		if decision.Keep || dryRun {
+			continue
+		}
 
-		if err := os.Remove(path); err != nil {
-			return fmt.Errorf("failed to remove snapshot: %w", err)
+		if err := exporter.removeExport(ctx, export); err != nil {
+			return fmt.Errorf("failed to remove export %q: %w", export.Path, err)
+		}
+		if err := exporter.dependencies.ExporterLogger.Remove(ctx, export.Target, export.Path); err != nil {
+			return fmt.Errorf("failed to remove log entry: %w", err)
 		}
 	}
 
+	// prune entries of exports that went missing
+	if err := exporter.pruneMissing(ctx, progress, dryRun); err != nil {
+		return err
+	}
+
+	// prune archives that aren't tracked in the log
+	if err := exporter.pruneUntracked(ctx, progress, dryRun); err != nil {
+		return err
+	}
+
+	// prune incremental snapshots
+	if err := exporter.pruneIncremental(ctx, progress, dryRun); err != nil {
+		return err
+	}
+
 	// prune the snapshot log!
 	_, err = exporter.dependencies.ExporterLogger.Log(ctx)
 	if err != nil {
@@ -63,3 +143,106 @@ func (exporter *Exporter) PruneExports(ctx context.Context, progress io.Writer)
 	}
 	return nil
 }
+
+// removeExport removes the data belonging to an export.
+func (exporter *Exporter) removeExport(ctx context.Context, export models.Export) error {
+	switch {
+	case export.Incremental:
+		err := exporter.Chunks().DeleteIndex(IncrementalName(export))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	case export.IsLocal() && !export.Packed:
+		if err := os.RemoveAll(export.Path); err != nil {
+			return fmt.Errorf("failed to remove staging directory: %w", err)
+		}
+		return nil
+	}
+
+	target, err := exporter.TargetOf(export.Target)
+	if err != nil {
+		return err
+	}
+	err = target.Delete(ctx, export.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, storage.ErrNotExist) {
+		return fmt.Errorf("failed to remove archive: %w", err)
+	}
+	return nil
+}
+
+// pruneMissing removes log entries flagged as missing once they are older than the maximum backup age.
+// Until then, they are kept so that the missing export is reported.
+func (exporter *Exporter) pruneMissing(ctx context.Context, progress io.Writer, dryRun bool) error {
+	missing, err := exporter.dependencies.ExporterLogger.Missing(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read missing exports: %w", err)
+	}
+
+	for _, export := range missing {
+		if !exporter.ShouldPrune(export.Created) {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(progress, "Removing entry of missing %s cause it is older than %s\n", export.Path, component.GetStill(exporter).Config.MaxBackupAge)
+		if dryRun {
+			continue
+		}
+
+		// remove whatever may be left of exports the distillery manages
+		if exporter.isManaged(export) {
+			if err := exporter.removeExport(ctx, export); err != nil {
+				return fmt.Errorf("failed to remove export %q: %w", export.Path, err)
+			}
+		}
+		if err := exporter.dependencies.ExporterLogger.Remove(ctx, export.Target, export.Path); err != nil {
+			return fmt.Errorf("failed to remove log entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// pruneUntracked removes archives older than the maximum backup age that are not tracked in the export log.
+func (exporter *Exporter) pruneUntracked(ctx context.Context, progress io.Writer, dryRun bool) error {
+	exports, err := exporter.dependencies.ExporterLogger.Log(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read export log: %w", err)
+	}
+
+	targets, err := exporter.Targets()
+	if err != nil {
+		return fmt.Errorf("failed to get storage targets: %w", err)
+	}
+
+	for _, target := range targets {
+		tracked := make(map[string]struct{})
+		for _, export := range exports {
+			if export.Target == target.Name() || (export.IsLocal() && target.Name() == storage.LocalName) {
+				tracked[export.Path] = struct{}{}
+			}
+		}
+
+		// list all the archives
+		objects, err := target.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list archives in %s target: %w", target.Name(), err)
+		}
+
+		for _, object := range objects {
+			if _, ok := tracked[object.Location]; ok || !exporter.ShouldPrune(object.Modified) {
+				continue
+			}
+
+			_, _ = fmt.Fprintf(progress, "Removing untracked %s from %s target cause it is older than %s\n", object.Location, target.Name(), component.GetStill(exporter).Config.MaxBackupAge)
+			if dryRun {
+				continue
+			}
+
+			if err := target.Delete(ctx, object.Location); err != nil {
+				return fmt.Errorf("failed to remove archive: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
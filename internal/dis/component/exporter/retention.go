@@ -0,0 +1,48 @@
+//spellchecker:words exporter
+package exporter
+
+//spellchecker:words context errors github wisski distillery internal component meta models
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/meta"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+// retentionPolicy is the metadata key holding the retention policy.
+// Policies of instances are stored in the storage of the instance, the policy for backups in the global storage.
+const retentionPolicy = meta.TypedKey[models.RetentionPolicy]("retention")
+
+// RetentionPolicy returns the retention policy for exports of the instance with the given slug.
+// An empty slug refers to system backups.
+// When no policy is set, returns the zero policy.
+func (exporter *Exporter) RetentionPolicy(ctx context.Context, slug string) (models.RetentionPolicy, error) {
+	policy, err := retentionPolicy.Get(ctx, exporter.dependencies.Meta.Storage(slug))
+	if errors.Is(err, meta.ErrMetadatumNotSet) {
+		return models.RetentionPolicy{}, nil
+	}
+	if err != nil {
+		return models.RetentionPolicy{}, fmt.Errorf("failed to get retention policy: %w", err)
+	}
+	return policy, nil
+}
+
+// SetRetentionPolicy sets the retention policy for exports of the instance with the given slug.
+// An empty slug refers to system backups.
+// Setting the zero policy removes the policy, and falls back to the maximum backup age.
+func (exporter *Exporter) SetRetentionPolicy(ctx context.Context, slug string, policy models.RetentionPolicy) error {
+	storage := exporter.dependencies.Meta.Storage(slug)
+	if policy.IsZero() {
+		if err := retentionPolicy.Delete(ctx, storage); err != nil {
+			return fmt.Errorf("failed to remove retention policy: %w", err)
+		}
+		return nil
+	}
+
+	if err := retentionPolicy.Set(ctx, storage, policy); err != nil {
+		return fmt.Errorf("failed to set retention policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// Package scheduler implements taking snapshots of instances according to their schedule.
+//
+//spellchecker:words scheduler
+package scheduler
+
+//spellchecker:words context errors sync atomic time github wisski distillery internal component exporter instances models wdlog pkglib errorsx lazy
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
+	"go.tkw01536.de/pkglib/errorsx"
+	"go.tkw01536.de/pkglib/lazy"
+)
+
+// Scheduler takes snapshots of instances that have a snapshot schedule.
+//
+// Snapshots take much longer than other cron tasks.
+// They are thus taken by a background worker, see [Scheduler.Start], which the cron task only triggers.
+type Scheduler struct {
+	component.Base
+	dependencies struct {
+		Exporter  *exporter.Exporter
+		Instances *instances.Instances
+	}
+
+	trigger lazy.Lazy[chan struct{}]
+	running atomic.Bool
+}
+
+var (
+	_ component.Cronable = (*Scheduler)(nil)
+)
+
+func (*Scheduler) TaskName() string {
+	return "scheduled snapshots"
+}
+
+// triggers returns the channel used to trigger the worker.
+func (scheduler *Scheduler) triggers() chan struct{} {
+	return scheduler.trigger.Get(func() chan struct{} { return make(chan struct{}, 1) })
+}
+
+var errWorkerNotRunning = errors.New("scheduled snapshot worker is not running")
+
+// Cron triggers the worker to take a snapshot of every instance whose schedule is due.
+// It does not wait for the snapshots to be taken.
+//
+// If the worker is still busy, it checks the schedules again once it is done.
+func (scheduler *Scheduler) Cron(ctx context.Context) error {
+	if !scheduler.running.Load() {
+		return errWorkerNotRunning
+	}
+
+	select {
+	case scheduler.triggers() <- struct{}{}:
+	default:
+		// already triggered
+	}
+	return nil
+}
+
+// Start starts the worker taking scheduled snapshots in the background.
+// The worker runs until ctx is cancelled.
+//
+// A snapshot that is in progress when ctx is cancelled is finished, but no further instances are snapshotted.
+// The returned channel is closed once the worker has stopped.
+func (scheduler *Scheduler) Start(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	scheduler.running.Store(true)
+
+	go func() {
+		defer close(done)
+		defer scheduler.running.Store(false)
+
+		for {
+			select {
+			case <-scheduler.triggers():
+			case <-ctx.Done():
+				return
+			}
+
+			if err := scheduler.RunDue(ctx); err != nil {
+				wdlog.Of(ctx).Error(
+					"failed to take scheduled snapshots",
+					"error", err,
+				)
+			}
+		}
+	}()
+
+	return done
+}
+
+// RunDue takes a snapshot of every instance whose schedule is due.
+// Instances are snapshotted one after the other, and no further instances are snapshotted once ctx is cancelled.
+func (scheduler *Scheduler) RunDue(ctx context.Context) error {
+	all, err := scheduler.dependencies.Instances.All(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	var errs error
+	for _, instance := range all {
+		if err := ctx.Err(); err != nil {
+			return errorsx.Combine(errs, fmt.Errorf("context cancelled: %w", err))
+		}
+
+		schedule, err := instance.Schedule().Get(ctx)
+		if err != nil {
+			errs = errorsx.Combine(errs, fmt.Errorf("%q: %w", instance.Slug, err))
+			continue
+		}
+		state, err := instance.Schedule().State(ctx)
+		if err != nil {
+			errs = errorsx.Combine(errs, fmt.Errorf("%q: %w", instance.Slug, err))
+			continue
+		}
+
+		if !schedule.Due(state.LastRun, time.Now()) {
+			continue
+		}
+
+		if err := scheduler.Run(ctx, io.Discard, instance, schedule); err != nil {
+			errs = errorsx.Combine(errs, fmt.Errorf("%q: %w", instance.Slug, err))
+		}
+	}
+	return errs
+}
+
+// Run takes a snapshot of instance as described by schedule, and records the outcome in the state of the schedule.
+// The returned error is nil if the snapshot succeeded.
+func (scheduler *Scheduler) Run(ctx context.Context, progress io.Writer, instance *wisski.WissKI, schedule models.SnapshotSchedule) error {
+	state, err := instance.Schedule().State(ctx)
+	if err != nil {
+		return err
+	}
+
+	state.LastRun = time.Now()
+	if err := instance.Schedule().SetState(ctx, state); err != nil {
+		return err
+	}
+
+	// Cancelling a snapshot halfway would leave the instance stopped, so it is not cancelled.
+	snapshotErr := scheduler.dependencies.Exporter.MakeExport(context.WithoutCancel(ctx), progress, exporter.ExportTask{
+		Instance:    instance,
+		Incremental: schedule.Incremental,
+
+		SnapshotDescription: exporter.SnapshotDescription{
+			Parts: schedule.Parts,
+		},
+	})
+
+	state.Finish(snapshotErr)
+	if err := instance.Schedule().SetState(context.WithoutCancel(ctx), state); err != nil {
+		return errorsx.Combine(snapshotErr, err)
+	}
+
+	if snapshotErr != nil {
+		return fmt.Errorf("failed to take scheduled snapshot: %w", snapshotErr)
+	}
+	return nil
+}
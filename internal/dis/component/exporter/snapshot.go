@@ -30,6 +30,11 @@ type SnapshotDescription struct {
 	Keepalive bool   // should we keep the instance alive while making the snapshot?
 
 	Parts []string // SnapshotName()s of the components to include.
+
+	// Incremental indicates that files were stored in the chunk store, see [ExportTask.Incremental].
+	Incremental bool
+
+	dedup component.Deduplicator // deduplicator for copied files, set for incremental snapshots
 }
 
 // Snapshot represents the result of generating a snapshot.
@@ -62,11 +67,14 @@ type Snapshot struct {
 	partsStopped []component.Snapshotable `json:"-"`
 }
 
+// snapshotEstimate is the estimated time making a snapshot takes.
+const snapshotEstimate = 15 * time.Minute
+
 // Snapshot creates a new snapshot of this instance into dest.
 func (exporter *Exporter) NewSnapshot(ctx context.Context, instance *wisski.WissKI, progress io.Writer, desc SnapshotDescription) (snapshot Snapshot) {
 	// #nosec G104
 	logging.LogMessage(progress, "Locking instance") //nolint:errcheck // no way to report error
-	if err := instance.Locker().TryLock(ctx); err != nil {
+	if err := instance.Locker().TryLock(ctx, "A snapshot of the instance is being made.", snapshotEstimate); err != nil {
 		_, _ = fmt.Fprintln(progress, err)
 		_, _ = fmt.Fprintln(progress, "Aborting snapshot creation")
 
@@ -213,6 +221,7 @@ func (snapshot *Snapshot) makeParts(ctx context.Context, progress io.Writer, _ *
 					writer,
 					filepath.Join(snapshot.Description.Dest, sc.SnapshotName()),
 					manifest,
+					snapshot.Description.dedup,
 				),
 			)
 		},
@@ -24,6 +24,7 @@ type snapshotJSON struct {
 
 	Logs     map[string]string `json:"Logs,omitempty"`
 	Manifest []string          `json:"Manifest,omitempty"`
+	Digests  map[string]string `json:"Digests,omitempty"`
 }
 
 var errFailedToMarshalSnapshotJSON = errors.New("failed to marshal snapshot json")
@@ -36,6 +37,7 @@ func (s Snapshot) MarshalJSON() ([]byte, error) {
 		EndTime:     s.EndTime,
 		Logs:        s.Logs,
 		Manifest:    s.Manifest,
+		Digests:     s.Digests,
 	}
 
 	// marshal all the error fields as json strings.
@@ -82,8 +84,8 @@ func (s *Snapshot) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("%w: %w", errFailedToUnmarshalSnapshotJSON, err)
 	}
 
-	// unmarshal all the fields, but skip the error fields.
-	// this prevents errors while unmarshalling interfaces.
+	// unmarshal all the fields.
+	// errors are restored from their messages only, as the original types are lost.
 
 	s.Description = j.Description
 	s.Instance = j.Instance
@@ -91,6 +93,32 @@ func (s *Snapshot) UnmarshalJSON(data []byte) error {
 	s.EndTime = j.EndTime
 	s.Logs = j.Logs
 	s.Manifest = j.Manifest
+	s.Digests = j.Digests
+
+	s.ErrPanic = unmarshalError(j.ErrPanic)
+	s.ErrStart = unmarshalError(j.ErrStart)
+	s.ErrStop = unmarshalError(j.ErrStop)
+	if len(j.Errors) > 0 {
+		s.Errors = make(map[string]error, len(j.Errors))
+		for k, v := range j.Errors {
+			s.Errors[k] = unmarshalError(v)
+		}
+	}
 
 	return nil
 }
+
+// unmarshalError unmarshals an error previously marshaled using [marshalString].
+// If the message is empty, returns nil.
+// If the message is not a string, returns a generic error.
+func unmarshalError(message json.RawMessage) error {
+	if len(message) == 0 || string(message) == "null" {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(message, &text); err != nil {
+		return errors.New("unknown error") //nolint:err113 // message is restored from report
+	}
+	return errors.New(text) //nolint:err113 // message is restored from report
+}
@@ -0,0 +1,235 @@
+//spellchecker:words exporter
+package exporter
+
+//spellchecker:words context encoding json errors path filepath strings filippo github wisski distillery internal component exporter chunks models logging targz pkglib errorsx status
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/chunks"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/pkg/logging"
+	"github.com/FAU-CDI/wisski-distillery/pkg/targz"
+	"go.tkw01536.de/pkglib/errorsx"
+	"go.tkw01536.de/pkglib/fsx"
+	"go.tkw01536.de/pkglib/status"
+)
+
+// StagedSnapshot is a snapshot that is available as an unpacked directory on disk.
+// It must be closed after use, see [StagedSnapshot.Close].
+type StagedSnapshot struct {
+	// Path is the directory holding the contents of the snapshot.
+	Path string
+
+	// Snapshot is the validated report of the snapshot.
+	Snapshot Snapshot
+
+	// temporary indicates that Path was created by staging, and should be removed on close.
+	temporary bool
+}
+
+// Close releases any resources associated with this staged snapshot.
+// Temporary directories created while unpacking are removed.
+func (staged *StagedSnapshot) Close() error {
+	if !staged.temporary || staged.Path == "" {
+		return nil
+	}
+	if err := os.RemoveAll(staged.Path); err != nil {
+		return fmt.Errorf("failed to remove staging directory: %w", err)
+	}
+	staged.Path = ""
+	return nil
+}
+
+var (
+	errStageUnknownSource = errors.New("snapshot source is neither a directory, a '.tar.gz' or '.age' archive nor an incremental snapshot index")
+	errStageNotSnapshot   = errors.New("export is not an instance snapshot")
+)
+
+// StageExport stages the snapshot recorded in the provided export log entry.
+// Archives stored in a remote target are downloaded first.
+// See [Exporter.Stage].
+func (exporter *Exporter) StageExport(ctx context.Context, progress io.Writer, entry models.Export, identities ...age.Identity) (_ *StagedSnapshot, e error) {
+	if entry.Slug == "" {
+		return nil, errStageNotSnapshot
+	}
+	if entry.IsLocal() {
+		return exporter.Stage(ctx, progress, entry.Path, identities...)
+	}
+
+	// download remote archives first
+	var archive string
+	if err := logging.LogOperation(func() (err error) {
+		archive, err = exporter.Fetch(ctx, progress, entry)
+		return err
+	}, progress, "Fetching archive from %s target", entry.Target); err != nil {
+		return nil, fmt.Errorf("failed to fetch archive: %w", err)
+	}
+	defer func() {
+		e = errorsx.Combine(e, os.Remove(archive))
+	}()
+
+	return exporter.Stage(ctx, progress, archive, identities...)
+}
+
+// Stage makes the snapshot at source available for restoring.
+//
+// Source may either be an unpacked snapshot directory, a packed '.tar.gz' archive, or the index of an incremental snapshot.
+// Archives and incremental snapshots are unpacked into a fresh temporary directory within the staging area.
+// In all cases, the report of the snapshot is read and validated.
+//
+// Encrypted archives are decrypted using the configured identity, and any of the given identities.
+//
+// The caller must close the returned snapshot.
+func (exporter *Exporter) Stage(ctx context.Context, progress io.Writer, source string, identities ...age.Identity) (_ *StagedSnapshot, e error) {
+	staged := new(StagedSnapshot)
+	defer func() {
+		if e != nil {
+			e = errorsx.Combine(e, staged.Close())
+		}
+	}()
+
+	isDirectory, err := fsx.IsDirectory(source, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check source: %w", err)
+	}
+	switch {
+	case isDirectory:
+		staged.Path = source
+	case strings.HasSuffix(source, ".tar.gz") || strings.HasSuffix(source, EncryptedExtension):
+		staged.temporary = true
+		staged.Path, err = exporter.unpackArchive(ctx, progress, source, identities)
+		if err != nil {
+			return nil, err
+		}
+	case strings.HasSuffix(source, chunks.IndexExtension):
+		staged.temporary = true
+		staged.Path, err = exporter.assembleIncremental(ctx, progress, source)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &fs.PathError{Op: "stage", Path: source, Err: errStageUnknownSource}
+	}
+
+	if _, err := logging.LogMessage(progress, "Validating snapshot report"); err != nil {
+		return nil, fmt.Errorf("failed to log message: %w", err)
+	}
+	staged.Snapshot, err = ReadSnapshotReport(staged.Path)
+	if err != nil {
+		return nil, err
+	}
+	return staged, nil
+}
+
+// unpackArchive unpacks the (possibly encrypted) archive at path into a new temporary directory inside the staging area.
+// If unpacking fails, the caller is responsible for removing the returned directory.
+func (exporter *Exporter) unpackArchive(ctx context.Context, progress io.Writer, path string, identities []age.Identity) (dir string, e error) {
+	archive, err := os.Open(path) // #nosec G304 -- intended
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer errorsx.Close(archive, &e, "archive")
+
+	reader, err := exporter.Decrypt(archive, identities...)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err = os.MkdirTemp(exporter.StagingPath(), "restore-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	e = logging.LogOperation(func() error {
+		var count int64
+		defer func() { _, _ = fmt.Fprintf(progress, "Unpacked %d byte(s) into %s\n", count, dir) }()
+
+		st := status.NewWithCompat(progress, 1)
+		st.Start()
+		defer st.Stop()
+
+		count, err = targz.Unpack(dir, contextReader{ctx: ctx, Reader: reader}, func(rel, dst string) {
+			st.Set(0, rel)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to unpack archive: %w", err)
+		}
+		return nil
+	}, progress, "Unpacking archive %s", path)
+	return dir, e
+}
+
+// contextReader is an [io.Reader] that stops reading once ctx is cancelled.
+//
+//nolint:containedctx
+type contextReader struct {
+	ctx context.Context
+	io.Reader
+}
+
+func (cr contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, fmt.Errorf("context cancelled: %w", err)
+	}
+	n, err := cr.Reader.Read(p)
+	return n, err //nolint:wrapcheck // must return io.EOF unwrapped
+}
+
+var (
+	errReportOpen       = errors.New("failed to open snapshot report")
+	errReportDecode     = errors.New("failed to decode snapshot report")
+	errReportInvalid    = errors.New("invalid snapshot report")
+	errReportNoInstance = errors.New("report does not describe an instance snapshot")
+	errReportNoParts    = errors.New("report does not list any parts")
+	errReportPanic      = errors.New("snapshot creation panicked")
+	errReportPartFailed = errors.New("snapshot part failed")
+)
+
+// ReadSnapshotReport reads and validates the machine-readable report of the snapshot in dir.
+// See [Snapshot.Validate].
+func ReadSnapshotReport(dir string) (s Snapshot, e error) {
+	reportPath := filepath.Join(dir, ReportMachinePath)
+	reportFile, err := os.Open(reportPath) // #nosec G304 -- intended
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%w: %w", errReportOpen, err)
+	}
+	defer errorsx.Close(reportFile, &e, "report file")
+
+	var snapshot Snapshot
+	if err := json.NewDecoder(reportFile).Decode(&snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("%w: %w", errReportDecode, err)
+	}
+
+	if err := snapshot.Validate(); err != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// Validate checks that this snapshot describes a complete snapshot of an instance.
+func (snapshot Snapshot) Validate() error {
+	if snapshot.Instance.Slug == "" {
+		return fmt.Errorf("%w: %w", errReportInvalid, errReportNoInstance)
+	}
+	if len(snapshot.Description.Parts) == 0 {
+		return fmt.Errorf("%w: %w", errReportInvalid, errReportNoParts)
+	}
+	if snapshot.ErrPanic != nil {
+		return fmt.Errorf("%w: %w: %v", errReportInvalid, errReportPanic, snapshot.ErrPanic)
+	}
+	for _, part := range snapshot.Description.Parts {
+		if err := snapshot.Errors[part]; err != nil {
+			return fmt.Errorf("%w: %w %q: %w", errReportInvalid, errReportPartFailed, part, err)
+		}
+	}
+	return nil
+}
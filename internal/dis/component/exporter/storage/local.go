@@ -0,0 +1,96 @@
+//spellchecker:words storage
+package storage
+
+//spellchecker:words context errors path filepath pkglib umaskfree
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"go.tkw01536.de/pkglib/fsx/umaskfree"
+)
+
+// LocalName is the name of the [Local] target.
+const LocalName = "local"
+
+// Local is a target that stores archives inside a local directory.
+// Locations are absolute paths to the archive files.
+type Local struct {
+	Dir string
+}
+
+var _ Target = (*Local)(nil)
+
+func (*Local) Name() string {
+	return LocalName
+}
+
+// Upload moves src into the directory.
+// If src already resides at the destination, it is left untouched.
+func (local *Local) Upload(ctx context.Context, src string, name string) (string, error) {
+	dst := filepath.Join(local.Dir, name)
+	if src == dst {
+		return dst, nil
+	}
+
+	// try renaming first, and fall back to copying across devices
+	if err := os.Rename(src, dst); err == nil {
+		return dst, nil
+	}
+	if err := umaskfree.CopyFile(dst, src); err != nil {
+		return "", fmt.Errorf("failed to copy archive: %w", err)
+	}
+	if err := os.Remove(src); err != nil {
+		return "", fmt.Errorf("failed to remove source file: %w", err)
+	}
+	return dst, nil
+}
+
+func (local *Local) Open(ctx context.Context, location string) (io.ReadCloser, error) {
+	file, err := os.Open(location) // #nosec G304 -- intended
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w", ErrNotExist, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	return file, nil
+}
+
+func (local *Local) List(ctx context.Context) ([]Object, error) {
+	entries, err := os.ReadDir(local.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive directory: %w", err)
+	}
+
+	objects := make([]Object, 0, len(entries))
+	for _, entry := range entries {
+		// skip directories
+		if entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entry info: %w", err)
+		}
+
+		objects = append(objects, Object{
+			Location: filepath.Join(local.Dir, entry.Name()),
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+		})
+	}
+	return objects, nil
+}
+
+func (local *Local) Delete(ctx context.Context, location string) error {
+	if err := os.Remove(location); err != nil {
+		return fmt.Errorf("failed to remove archive: %w", err)
+	}
+	return nil
+}
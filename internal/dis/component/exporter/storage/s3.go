@@ -0,0 +1,340 @@
+//spellchecker:words storage
+package storage
+
+//spellchecker:words bytes context encoding errors http strconv strings time pkglib errorsx
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.tkw01536.de/pkglib/errorsx"
+)
+
+// S3Name is the name of the [S3] target.
+const S3Name = "s3"
+
+// DefaultPartSize is the default size of parts used for multipart uploads.
+const DefaultPartSize = 64 * 1024 * 1024 // 64 MiB
+
+// S3 is a target that stores archives in a bucket of an S3-compatible object storage.
+// Locations are object keys within the bucket.
+//
+// Requests use path-style addressing and AWS Signature Version 4.
+type S3 struct {
+	// Endpoint is the base url of the object storage, e.g. "https://s3.eu-central-1.amazonaws.com".
+	Endpoint *url.URL
+
+	Region string // region to sign requests for
+	Bucket string // bucket to store archives in
+	Prefix string // prefix for object keys
+
+	AccessKey string
+	SecretKey string
+
+	// PartSize is the size of parts for multipart uploads.
+	// Files larger than this are uploaded in several parts.
+	// When zero, [DefaultPartSize] is used.
+	PartSize int64
+
+	// Client is the client used to make requests.
+	// When nil, [DefaultS3Client] is used.
+	Client *http.Client
+}
+
+// DefaultS3Client is the client used by [S3] when no client is set.
+//
+// Transferring an archive may take arbitrarily long, so the duration of a request as a whole is not limited.
+// Instead, establishing a connection and waiting for a response to a request that has been sent are.
+var DefaultS3Client = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   30 * time.Second,
+		ResponseHeaderTimeout: 5 * time.Minute, // completing a multipart upload may take a while
+		ExpectContinueTimeout: 1 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		ForceAttemptHTTP2:     true,
+	},
+}
+
+var _ Target = (*S3)(nil)
+
+func (*S3) Name() string {
+	return S3Name
+}
+
+var (
+	errS3Request  = errors.New("s3 request failed")
+	errS3Response = errors.New("unexpected s3 response")
+)
+
+// s3Error is the error document returned by S3.
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do signs and performs a request for the given object key.
+// An empty key addresses the bucket itself.
+//
+// When the response does not have the expected status code, the body is closed and an error is returned.
+func (s3 *S3) do(ctx context.Context, method string, key string, query url.Values, body io.Reader, size int64, payloadHash string, expect int) (_ *http.Response, e error) {
+	u := *s3.Endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s3.Bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = awsEscape(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errS3Request, err)
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	s3.sign(req, payloadHash, time.Now())
+
+	client := s3.Client
+	if client == nil {
+		client = DefaultS3Client
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errS3Request, err)
+	}
+	if res.StatusCode == expect {
+		return res, nil
+	}
+	defer errorsx.Close(res.Body, &e, "response body")
+
+	var doc s3Error
+	if err := xml.NewDecoder(io.LimitReader(res.Body, 64*1024)).Decode(&doc); err != nil || doc.Code == "" {
+		return nil, fmt.Errorf("%w: %s %s: %s", errS3Response, method, key, res.Status)
+	}
+	if doc.Code == "NoSuchKey" {
+		return nil, fmt.Errorf("%w: %s", ErrNotExist, key)
+	}
+	return nil, fmt.Errorf("%w: %s %s: %s: %s (%s)", errS3Response, method, key, res.Status, doc.Code, doc.Message)
+}
+
+// Upload uploads the file at src into the bucket.
+// Files larger than the part size use a multipart upload.
+//
+// Once the size of the uploaded object has been verified, src is removed.
+func (s3 *S3) Upload(ctx context.Context, src string, name string) (string, error) {
+	key := s3.Prefix + name
+
+	size, err := s3.upload(ctx, src, key)
+	if err != nil {
+		return "", err
+	}
+	if err := s3.verify(ctx, key, size); err != nil {
+		return "", err
+	}
+
+	if err := os.Remove(src); err != nil {
+		return "", fmt.Errorf("failed to remove source file: %w", err)
+	}
+	return key, nil
+}
+
+// upload uploads the file at src to the given key, and returns its size.
+func (s3 *S3) upload(ctx context.Context, src string, key string) (_ int64, e error) {
+	file, err := os.Open(src) // #nosec G304 -- intended
+	if err != nil {
+		return 0, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer errorsx.Close(file, &e, "archive")
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat archive: %w", err)
+	}
+
+	partSize := s3.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+
+	if info.Size() <= partSize {
+		res, err := s3.do(ctx, http.MethodPut, key, nil, io.NewSectionReader(file, 0, info.Size()), info.Size(), unsignedPayload, http.StatusOK)
+		if err != nil {
+			return 0, err
+		}
+		if err := res.Body.Close(); err != nil {
+			return 0, fmt.Errorf("failed to close response body: %w", err)
+		}
+		return info.Size(), nil
+	}
+
+	if err := s3.uploadMultipart(ctx, key, file, info.Size(), partSize); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+var errS3SizeMismatch = errors.New("uploaded object has unexpected size")
+
+// verify checks that the object with the given key exists and has the given size.
+func (s3 *S3) verify(ctx context.Context, key string, size int64) error {
+	res, err := s3.do(ctx, http.MethodHead, key, nil, nil, 0, emptyPayload, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("failed to verify upload: %w", err)
+	}
+	if err := res.Body.Close(); err != nil {
+		return fmt.Errorf("failed to close response body: %w", err)
+	}
+	if res.ContentLength != size {
+		return fmt.Errorf("%w: %s has %d byte(s), expected %d", errS3SizeMismatch, key, res.ContentLength, size)
+	}
+	return nil
+}
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []completePart `xml:"Part"`
+}
+
+type completePart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+var errS3NoUploadID = errors.New("s3 did not return an upload id")
+
+func (s3 *S3) uploadMultipart(ctx context.Context, key string, file io.ReaderAt, size, partSize int64) (e error) {
+	res, err := s3.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0, emptyPayload, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	var initiate initiateMultipartUploadResult
+	err = xml.NewDecoder(res.Body).Decode(&initiate)
+	err = errorsx.Combine(err, res.Body.Close())
+	if err != nil {
+		return fmt.Errorf("failed to decode multipart upload: %w", err)
+	}
+	if initiate.UploadID == "" {
+		return errS3NoUploadID
+	}
+
+	// abort the upload if anything goes wrong
+	defer func() {
+		if e == nil {
+			return
+		}
+		res, err := s3.do(context.WithoutCancel(ctx), http.MethodDelete, key, url.Values{"uploadId": {initiate.UploadID}}, nil, 0, emptyPayload, http.StatusNoContent)
+		if err != nil {
+			e = errorsx.Combine(e, err)
+			return
+		}
+		e = errorsx.Combine(e, res.Body.Close())
+	}()
+
+	var complete completeMultipartUpload
+	for offset, number := int64(0), 1; offset < size; offset, number = offset+partSize, number+1 {
+		length := min(partSize, size-offset)
+		res, err := s3.do(ctx, http.MethodPut, key, url.Values{
+			"partNumber": {strconv.Itoa(number)},
+			"uploadId":   {initiate.UploadID},
+		}, io.NewSectionReader(file, offset, length), length, unsignedPayload, http.StatusOK)
+		if err != nil {
+			return fmt.Errorf("failed to upload part %d: %w", number, err)
+		}
+		if err := res.Body.Close(); err != nil {
+			return fmt.Errorf("failed to close response body: %w", err)
+		}
+		complete.Parts = append(complete.Parts, completePart{PartNumber: number, ETag: res.Header.Get("ETag")})
+	}
+
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		return fmt.Errorf("failed to encode multipart upload: %w", err)
+	}
+	res, err = s3.do(ctx, http.MethodPost, key, url.Values{"uploadId": {initiate.UploadID}}, bytes.NewReader(body), int64(len(body)), hashPayload(body), http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	if err := res.Body.Close(); err != nil {
+		return fmt.Errorf("failed to close response body: %w", err)
+	}
+	return nil
+}
+
+func (s3 *S3) Open(ctx context.Context, location string) (io.ReadCloser, error) {
+	res, err := s3.do(ctx, http.MethodGet, location, nil, nil, 0, emptyPayload, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s3 *S3) List(ctx context.Context) ([]Object, error) {
+	var objects []Object
+
+	query := url.Values{"list-type": {"2"}}
+	if s3.Prefix != "" {
+		query.Set("prefix", s3.Prefix)
+	}
+	for {
+		res, err := s3.do(ctx, http.MethodGet, "", query, nil, 0, emptyPayload, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		err = errorsx.Combine(err, res.Body.Close())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object list: %w", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, Object{
+				Location: content.Key,
+				Size:     content.Size,
+				Modified: content.LastModified,
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (s3 *S3) Delete(ctx context.Context, location string) error {
+	res, err := s3.do(ctx, http.MethodDelete, location, nil, nil, 0, emptyPayload, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	if err := res.Body.Close(); err != nil {
+		return fmt.Errorf("failed to close response body: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+//spellchecker:words storage
+package storage
+
+//spellchecker:words crypto hmac sha256 encoding slices strings time
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	emptyPayload    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" // sha256 of the empty string
+
+	signAlgorithm = "AWS4-HMAC-SHA256"
+	signService   = "s3"
+	signTerminal  = "aws4_request"
+	signHeaders   = "host;x-amz-content-sha256;x-amz-date"
+)
+
+// hashPayload returns the hex-encoded sha256 hash of payload.
+func hashPayload(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// sign signs req using AWS Signature Version 4.
+// The url of req must already be in canonical form, see [S3.do].
+func (s3 *S3) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	stamp := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + stamp + "\n",
+		signHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s3.Region + "/" + signService + "/" + signTerminal
+	toSign := signAlgorithm + "\n" + stamp + "\n" + scope + "\n" + hashPayload([]byte(canonical))
+
+	key := []byte("AWS4" + s3.SecretKey)
+	for _, part := range []string{date, s3.Region, signService, signTerminal} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", signAlgorithm+" Credential="+s3.AccessKey+"/"+scope+", SignedHeaders="+signHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query in the canonical form required for signing.
+// Keys are sorted, and keys and values are escaped with [awsEscape].
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		values := slices.Clone(query[key])
+		slices.Sort(values)
+		for _, value := range values {
+			if builder.Len() > 0 {
+				builder.WriteByte('&')
+			}
+			builder.WriteString(awsEscape(key, true))
+			builder.WriteByte('=')
+			builder.WriteString(awsEscape(value, true))
+		}
+	}
+	return builder.String()
+}
+
+// awsEscape percent-encodes all bytes of s except for unreserved characters.
+// Slashes are only encoded if slash is true.
+func awsEscape(s string, slash bool) string {
+	const hexUpper = "0123456789ABCDEF"
+
+	var builder strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			builder.WriteByte(c)
+		case c == '/' && !slash:
+			builder.WriteByte(c)
+		default:
+			builder.WriteByte('%')
+			builder.WriteByte(hexUpper[c>>4])
+			builder.WriteByte(hexUpper[c&15])
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,272 @@
+package storage_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/storage"
+)
+
+func TestS3(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(newFakeS3("archives", "access"))
+	defer server.Close()
+
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := &storage.S3{
+		Endpoint:  endpoint,
+		Region:    "us-east-1",
+		Bucket:    "archives",
+		Prefix:    "distillery/",
+		AccessKey: "access",
+		SecretKey: "secret",
+		PartSize:  4,
+		Client:    server.Client(),
+	}
+
+	dir := t.TempDir()
+	contents := map[string]string{
+		"small.tar.gz": "abc",
+		"large.tar.gz": "this is uploaded in several parts",
+		"third.tar.gz": "",
+	}
+	for name, content := range contents {
+		src := filepath.Join(dir, name)
+		if err := os.WriteFile(src, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		location, err := target.Upload(t.Context(), src, name)
+		if err != nil {
+			t.Fatalf("Upload(%q): %v", name, err)
+		}
+		if want := "distillery/" + name; location != want {
+			t.Errorf("Upload(%q): got location %q, want %q", name, location, want)
+		}
+		if _, err := os.Stat(src); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Upload(%q): source file was not removed: %v", name, err)
+		}
+	}
+
+	objects, err := target.List(t.Context())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	got := make([]string, len(objects))
+	for i, object := range objects {
+		got[i] = object.Location
+		name := strings.TrimPrefix(object.Location, "distillery/")
+		if object.Size != int64(len(contents[name])) {
+			t.Errorf("List: %q has size %d, want %d", object.Location, object.Size, len(contents[name]))
+		}
+	}
+	want := []string{"distillery/large.tar.gz", "distillery/small.tar.gz", "distillery/third.tar.gz"}
+	if !slices.Equal(got, want) {
+		t.Errorf("List: got %v, want %v", got, want)
+	}
+
+	for name, content := range contents {
+		reader, err := target.Open(t.Context(), "distillery/"+name)
+		if err != nil {
+			t.Fatalf("Open(%q): %v", name, err)
+		}
+		data, err := io.ReadAll(reader)
+		if err := reader.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("Open(%q): got %q, want %q", name, data, content)
+		}
+	}
+
+	if err := target.Delete(t.Context(), "distillery/large.tar.gz"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := target.Open(t.Context(), "distillery/large.tar.gz"); !errors.Is(err, storage.ErrNotExist) {
+		t.Errorf("Open after Delete: got error %v, want %v", err, storage.ErrNotExist)
+	}
+}
+
+// fakeS3 is a minimal in-memory stand-in for an S3-compatible object storage with a single bucket.
+// It checks that requests are signed with the right access key, but does not verify signatures.
+type fakeS3 struct {
+	bucket    string
+	accessKey string
+
+	m       sync.Mutex
+	objects map[string]fakeObject
+	uploads map[string]map[int][]byte
+}
+
+type fakeObject struct {
+	data     []byte
+	modified time.Time
+}
+
+func newFakeS3(bucket, accessKey string) *fakeS3 {
+	return &fakeS3{
+		bucket:    bucket,
+		accessKey: accessKey,
+		objects:   make(map[string]fakeObject),
+		uploads:   make(map[string]map[int][]byte),
+	}
+}
+
+func (fake *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fake.m.Lock()
+	defer fake.m.Unlock()
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential="+fake.accessKey+"/") || r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		fake.error(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != fake.bucket {
+		fake.error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	query := r.URL.Query()
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		fake.list(w, query)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		id := strconv.Itoa(len(fake.uploads) + 1)
+		fake.uploads[id] = make(map[int][]byte)
+		fake.xml(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			UploadID string   `xml:"UploadId"`
+		}{UploadID: id})
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := fake.uploads[query.Get("uploadId")]
+		number, err := strconv.Atoi(query.Get("partNumber"))
+		if !ok || err != nil {
+			fake.error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		parts[number], _ = io.ReadAll(r.Body)
+		w.Header().Set("ETag", fmt.Sprintf("%q", "part"+strconv.Itoa(number)))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts, ok := fake.uploads[query.Get("uploadId")]
+		if !ok {
+			fake.error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		var complete struct {
+			Parts []struct {
+				PartNumber int `xml:"PartNumber"`
+			} `xml:"Part"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&complete); err != nil {
+			fake.error(w, http.StatusBadRequest, "MalformedXML")
+			return
+		}
+		var data []byte
+		for _, part := range complete.Parts {
+			data = append(data, parts[part.PartNumber]...)
+		}
+		delete(fake.uploads, query.Get("uploadId"))
+		fake.objects[key] = fakeObject{data: data, modified: time.Now()}
+		fake.xml(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Key     string   `xml:"Key"`
+		}{Key: key})
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(fake.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		fake.objects[key] = fakeObject{data: data, modified: time.Now()}
+	case r.Method == http.MethodHead:
+		object, ok := fake.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+	case r.Method == http.MethodGet:
+		object, ok := fake.objects[key]
+		if !ok {
+			fake.error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		_, _ = w.Write(object.data)
+	case r.Method == http.MethodDelete:
+		delete(fake.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		fake.error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// list lists objects two at a time, to exercise pagination.
+func (fake *fakeS3) list(w http.ResponseWriter, query url.Values) {
+	type content struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int       `xml:"Size"`
+	}
+	var result struct {
+		XMLName               xml.Name  `xml:"ListBucketResult"`
+		Contents              []content `xml:"Contents"`
+		IsTruncated           bool      `xml:"IsTruncated"`
+		NextContinuationToken string    `xml:"NextContinuationToken,omitempty"`
+	}
+
+	keys := make([]string, 0, len(fake.objects))
+	for key := range fake.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) && key > query.Get("continuation-token") {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	if len(keys) > 2 {
+		keys = keys[:2]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[1]
+	}
+	for _, key := range keys {
+		object := fake.objects[key]
+		result.Contents = append(result.Contents, content{Key: key, LastModified: object.modified, Size: len(object.data)})
+	}
+	fake.xml(w, result)
+}
+
+func (fake *fakeS3) error(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{Code: code, Message: code})
+}
+
+func (fake *fakeS3) xml(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(value)
+}
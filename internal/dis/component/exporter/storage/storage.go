@@ -0,0 +1,48 @@
+// Package storage implements targets that exported archives can be stored in.
+//
+//spellchecker:words storage
+package storage
+
+//spellchecker:words context time
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Target is a location that exported archives are stored in.
+//
+// Archives are addressed by a location.
+// The format of a location depends on the target, and is recorded in the export log.
+type Target interface {
+	// Name returns the name of this target.
+	// It is recorded in the export log alongside the location of each archive.
+	Name() string
+
+	// Upload stores the local file at src as an archive with the given name.
+	// It returns the location of the stored archive.
+	//
+	// Once the archive has been stored, src is removed unless it is the stored archive itself.
+	// On error, src is left in place.
+	Upload(ctx context.Context, src string, name string) (location string, err error)
+
+	// Open opens the archive at the given location for reading.
+	Open(ctx context.Context, location string) (io.ReadCloser, error)
+
+	// List lists all archives stored in this target.
+	List(ctx context.Context) ([]Object, error)
+
+	// Delete removes the archive at the given location.
+	Delete(ctx context.Context, location string) error
+}
+
+// Object describes an archive stored in a target.
+type Object struct {
+	Location string    // location of the archive within the target
+	Size     int64     // size in bytes
+	Modified time.Time // last modification time
+}
+
+// ErrNotExist is returned by [Target.Open] when an archive does not exist.
+var ErrNotExist = errors.New("archive does not exist")
@@ -0,0 +1,110 @@
+//spellchecker:words exporter
+package exporter
+
+//spellchecker:words context errors github wisski distillery internal component exporter storage models pkglib errorsx
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/storage"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"go.tkw01536.de/pkglib/errorsx"
+	"go.tkw01536.de/pkglib/fsx/umaskfree"
+)
+
+var (
+	errUnknownTarget    = errors.New("unknown storage target")
+	errS3NotConfigured  = errors.New("s3 storage target is not configured: endpoint and bucket are required")
+	errFetchLocalExport = errors.New("export is stored locally")
+)
+
+// LocalTarget returns the target representing the local archive directory.
+func (exporter *Exporter) LocalTarget() *storage.Local {
+	return &storage.Local{Dir: exporter.ArchivePath()}
+}
+
+// Target returns the target new archives are stored in, as determined by the configuration.
+func (exporter *Exporter) Target() (storage.Target, error) {
+	return exporter.TargetOf(component.GetStill(exporter).Config.Storage.Target)
+}
+
+// Targets returns the local target, and the configured target if it differs.
+func (exporter *Exporter) Targets() ([]storage.Target, error) {
+	target, err := exporter.Target()
+	if err != nil {
+		return nil, err
+	}
+	if target.Name() == storage.LocalName {
+		return []storage.Target{target}, nil
+	}
+	return []storage.Target{exporter.LocalTarget(), target}, nil
+}
+
+// TargetOf returns the target with the given name.
+// The empty name refers to the local target.
+func (exporter *Exporter) TargetOf(name string) (storage.Target, error) {
+	switch name {
+	case "", storage.LocalName:
+		return exporter.LocalTarget(), nil
+	case storage.S3Name:
+		config := component.GetStill(exporter).Config.Storage.S3
+		if config.Endpoint.String() == "" || config.Bucket == "" {
+			return nil, errS3NotConfigured
+		}
+		return &storage.S3{
+			Endpoint:  (*url.URL)(config.Endpoint),
+			Region:    config.Region,
+			Bucket:    config.Bucket,
+			Prefix:    config.Prefix,
+			AccessKey: config.AccessKey,
+			SecretKey: config.SecretKey,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownTarget, name)
+	}
+}
+
+// Fetch downloads the archive of a remotely stored export into a new file in the staging area.
+// The caller is responsible for removing the returned file.
+func (exporter *Exporter) Fetch(ctx context.Context, progress io.Writer, entry models.Export) (path string, e error) {
+	if entry.IsLocal() {
+		return "", errFetchLocalExport
+	}
+
+	target, err := exporter.TargetOf(entry.Target)
+	if err != nil {
+		return "", err
+	}
+
+	reader, err := target.Open(ctx, entry.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer errorsx.Close(reader, &e, "archive")
+
+	file, err := os.CreateTemp(exporter.StagingPath(), "fetch-*.tar.gz")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		e = errorsx.Combine(e, file.Close())
+		if e != nil {
+			e = errorsx.Combine(e, os.Remove(file.Name()))
+		}
+	}()
+	if err := file.Chmod(umaskfree.DefaultFilePerm); err != nil {
+		return "", fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	count, err := io.Copy(file, reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to download archive: %w", err)
+	}
+	_, _ = fmt.Fprintf(progress, "Downloaded %d byte(s) from %s target\n", count, target.Name())
+	return file.Name(), nil
+}
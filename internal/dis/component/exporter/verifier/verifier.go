@@ -0,0 +1,348 @@
+// Package verifier implements verifying the integrity of exports.
+//
+//spellchecker:words verifier
+package verifier
+
+//spellchecker:words archive compress gzip context crypto sha256 encoding json errors path filepath slices time filippo github wisski distillery internal component exporter chunks logger storage models pkglib errorsx
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"filippo.io/age"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/chunks"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/logger"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/storage"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"go.tkw01536.de/pkglib/errorsx"
+)
+
+// Verifier verifies that exports are still stored, readable, and match the digests recorded when they were made.
+type Verifier struct {
+	component.Base
+	dependencies struct {
+		Exporter       *exporter.Exporter
+		ExporterLogger *logger.Logger
+	}
+}
+
+var (
+	_ component.Cronable = (*Verifier)(nil)
+)
+
+var (
+	errArchiveDigest  = errors.New("archive does not match recorded digest")
+	errIndexDigest    = errors.New("index does not match recorded digest")
+	errNoReport       = errors.New("export does not contain a report")
+	errFileMissing    = errors.New("file is missing")
+	errFileDigest     = errors.New("file does not match recorded digest")
+	errUnsafeFileName = errors.New("report contains unsafe file name")
+)
+
+// Verify verifies a single export.
+// Returns nil if the export is intact, and an error describing the problem otherwise.
+//
+// Exports made before digests were recorded are only checked for being readable.
+// The content of encrypted archives is only checked if an identity is configured or given;
+// otherwise only the digest of the archive itself is compared.
+func (verifier *Verifier) Verify(ctx context.Context, export models.Export, identities ...age.Identity) error {
+	switch {
+	case export.Incremental:
+		return verifier.verifyIncremental(ctx, export)
+	case export.Packed:
+		return verifier.verifyArchive(ctx, export, identities)
+	default:
+		return verifier.verifyDirectory(export)
+	}
+}
+
+// VerifyExports verifies the given exports, and records the results in the export log.
+// Progress is written to progress, and the updated exports are returned.
+// Identities are used to decrypt encrypted archives, see [Verifier.Verify].
+func (verifier *Verifier) VerifyExports(ctx context.Context, progress io.Writer, exports []models.Export, identities ...age.Identity) ([]models.Export, error) {
+	results := make([]models.Export, 0, len(exports))
+	for _, export := range exports {
+		_, _ = fmt.Fprintf(progress, "Verifying %s: ", export.Path)
+
+		err := verifier.Verify(ctx, export, identities...)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			_, _ = fmt.Fprintln(progress, "cancelled")
+			return results, fmt.Errorf("context cancelled: %w", ctxErr)
+		}
+
+		verified := time.Now().UTC()
+		export.Verified = &verified
+		export.Problem = ""
+		if err != nil {
+			export.Problem = err.Error()
+			_, _ = fmt.Fprintln(progress, export.Problem)
+		} else {
+			_, _ = fmt.Fprintln(progress, "ok")
+		}
+
+		if err := verifier.dependencies.ExporterLogger.SetVerification(ctx, export.Pk, verified, export.Problem); err != nil {
+			return results, fmt.Errorf("failed to record verification: %w", err)
+		}
+		results = append(results, export)
+	}
+	return results, nil
+}
+
+// Problems returns all exports in the log for which the last verification found a problem, including missing exports.
+func (verifier *Verifier) Problems(ctx context.Context) ([]models.Export, error) {
+	exports, err := verifier.dependencies.ExporterLogger.Log(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export log: %w", err)
+	}
+	missing, err := verifier.dependencies.ExporterLogger.Missing(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read missing exports: %w", err)
+	}
+	exports = slices.DeleteFunc(exports, func(export models.Export) bool { return export.Problem == "" })
+	return append(exports, missing...), nil
+}
+
+func (*Verifier) TaskName() string {
+	return "export verification"
+}
+
+// Cron verifies all exports that have not been verified within the configured verification interval.
+// Exports that have never been verified are verified first.
+//
+// Missing exports are verified as well, so that they are no longer flagged once they reappear.
+func (verifier *Verifier) Cron(ctx context.Context) error {
+	interval := component.GetStill(verifier).Config.Storage.VerifyInterval
+
+	exports, err := verifier.dependencies.ExporterLogger.Log(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read export log: %w", err)
+	}
+	missing, err := verifier.dependencies.ExporterLogger.Missing(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read missing exports: %w", err)
+	}
+	exports = append(exports, missing...)
+
+	exports = slices.DeleteFunc(exports, func(export models.Export) bool {
+		return export.Verified != nil && time.Since(*export.Verified) < interval
+	})
+	slices.SortStableFunc(exports, func(a, b models.Export) int {
+		switch {
+		case a.Verified == nil && b.Verified == nil:
+			return 0
+		case a.Verified == nil:
+			return -1
+		case b.Verified == nil:
+			return 1
+		default:
+			return a.Verified.Compare(*b.Verified)
+		}
+	})
+
+	_, err = verifier.VerifyExports(ctx, io.Discard, exports)
+	return err
+}
+
+// verifyArchive verifies a (possibly encrypted) packed archive stored in a storage target.
+func (verifier *Verifier) verifyArchive(ctx context.Context, export models.Export, identities []age.Identity) (e error) {
+	target, err := verifier.dependencies.Exporter.TargetOf(export.Target)
+	if err != nil {
+		return fmt.Errorf("failed to get storage target: %w", err)
+	}
+
+	reader, err := target.Open(ctx, export.Path)
+	if errors.Is(err, storage.ErrNotExist) {
+		return logger.ErrExportMissing
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer errorsx.Close(reader, &e, "archive")
+
+	sum := sha256.New()
+	tee := io.TeeReader(reader, sum)
+
+	// without an identity, only the digest of the archive can be checked
+	plain, err := verifier.dependencies.Exporter.Decrypt(tee, identities...)
+	contents := !errors.Is(err, exporter.ErrNoIdentity)
+	if err != nil && contents {
+		return fmt.Errorf("failed to decrypt archive: %w", err)
+	}
+
+	var (
+		digests map[string]string
+		report  []byte
+	)
+	if contents {
+		digests, report, err = readArchive(plain)
+		if err != nil {
+			return err
+		}
+	}
+
+	// read any trailing data, so that the digest covers the entire archive
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	if export.Digest != "" && hex.EncodeToString(sum.Sum(nil)) != export.Digest {
+		return errArchiveDigest
+	}
+	if !contents {
+		return nil
+	}
+
+	return compareDigests(report, func(name string) (string, error) {
+		digest, ok := digests[name]
+		if !ok {
+			return "", errFileMissing
+		}
+		return digest, nil
+	})
+}
+
+// readArchive reads a 'tar.gz' stream, and computes the digests of all regular files.
+// Also returns the content of the machine-readable report, if any.
+func readArchive(src io.Reader) (digests map[string]string, report []byte, e error) {
+	zipHandle, err := gzip.NewReader(src)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer errorsx.Close(zipHandle, &e, "zip handle")
+
+	digests = make(map[string]string)
+
+	tarHandle := tar.NewReader(zipHandle)
+	for {
+		header, err := tarHandle.Next()
+		if errors.Is(err, io.EOF) {
+			return digests, report, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read tar header: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+
+		sum := sha256.New()
+		var dst io.Writer = sum
+
+		var buffer bytes.Buffer
+		if name == exporter.ReportMachinePath {
+			dst = io.MultiWriter(sum, &buffer)
+		}
+
+		if _, err := io.Copy(dst, tarHandle); err != nil { // #nosec G110 -- only hashed, never written to disk
+			return nil, nil, fmt.Errorf("failed to read %q: %w", name, err)
+		}
+
+		digests[name] = hex.EncodeToString(sum.Sum(nil))
+		if name == exporter.ReportMachinePath {
+			report = buffer.Bytes()
+		}
+	}
+}
+
+// verifyDirectory verifies an unpacked export on the local disk.
+func (verifier *Verifier) verifyDirectory(export models.Export) error {
+	report, err := os.ReadFile(filepath.Join(export.Path, exporter.ReportMachinePath))
+	if errors.Is(err, fs.ErrNotExist) {
+		if _, err := os.Stat(export.Path); errors.Is(err, fs.ErrNotExist) {
+			return logger.ErrExportMissing
+		}
+		return errNoReport
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read report: %w", err)
+	}
+
+	return compareDigests(report, func(name string) (string, error) {
+		digest, err := exporter.FileDigest(filepath.Join(export.Path, filepath.FromSlash(name)))
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", errFileMissing
+		}
+		return digest, err
+	})
+}
+
+// verifyIncremental verifies an incremental snapshot inside the chunk store.
+func (verifier *Verifier) verifyIncremental(ctx context.Context, export models.Export) error {
+	digest, err := exporter.FileDigest(export.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return logger.ErrExportMissing
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	if export.Digest != "" && digest != export.Digest {
+		return errIndexDigest
+	}
+
+	index, err := chunks.ReadIndexFile(export.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+
+	// the files of the index record their own digests
+	if err := verifier.dependencies.Exporter.Chunks().Verify(ctx, index, nil); err != nil {
+		return fmt.Errorf("failed to verify chunks: %w", err)
+	}
+	return nil
+}
+
+// compareDigests compares the digests recorded in the given machine-readable report against the actual digests.
+// digest is called to compute the actual digest of each file.
+//
+// Reports without digests are accepted.
+func compareDigests(report []byte, digest func(name string) (string, error)) error {
+	if report == nil {
+		return errNoReport
+	}
+
+	var recorded struct {
+		Digests map[string]string `json:"Digests"`
+	}
+	if err := json.Unmarshal(report, &recorded); err != nil {
+		return fmt.Errorf("failed to read report: %w", err)
+	}
+
+	names := make([]string, 0, len(recorded.Digests))
+	for name := range recorded.Digests {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var errs []error
+	for _, name := range names {
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			errs = append(errs, fmt.Errorf("%w: %q", errUnsafeFileName, name))
+			continue
+		}
+
+		got, err := digest(name)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%q: %w", name, err))
+		case got != recorded.Digests[name]:
+			errs = append(errs, fmt.Errorf("%q: %w", name, errFileDigest))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,275 @@
+// Package health implements health checks of the distillery and its instances, and notifications about their state.
+//
+//spellchecker:words health
+package health
+
+//spellchecker:words context errors slices strings sync time github wisski distillery internal component instances meta server cron sql triplestore web models wdlog pkglib errorsx
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/meta"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/cron"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/triplestore"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/web"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+	"go.tkw01536.de/pkglib/errorsx"
+)
+
+// Health periodically evaluates health checks, and sends notifications when their state changes.
+type Health struct {
+	component.Base
+	dependencies struct {
+		Instances   *instances.Instances
+		Cron        *cron.Cron
+		SQL         *sql.SQL
+		Triplestore *triplestore.Triplestore
+		Web         *web.Web
+		Meta        *meta.Meta
+	}
+}
+
+var (
+	_ component.Cronable = (*Health)(nil)
+)
+
+const taskName = "health checks"
+
+func (*Health) TaskName() string {
+	return taskName
+}
+
+// Kinds of checks.
+const (
+	KindBarrel      = "barrel"
+	KindDrupalCron  = "drupal_cron"
+	KindCronTask    = "cron_task"
+	KindSQL         = "sql"
+	KindTriplestore = "triplestore"
+)
+
+const (
+	// pingTimeout is the maximal time a health check of the sql database or triplestore may take.
+	pingTimeout = 10 * time.Second
+
+	// notifyTimeout is the maximal time sending a notification may take.
+	notifyTimeout = time.Minute
+)
+
+var stateKey = meta.TypedKey[map[string]models.CheckState]("health")
+
+// Cron evaluates all checks, and notifies about changes in their state.
+func (health *Health) Cron(ctx context.Context) error {
+	cfg := component.GetStill(health).Config.Alerts
+
+	previous, err := health.State(ctx)
+	if err != nil {
+		return err
+	}
+
+	next, events := Evaluate(previous, health.Check(ctx), time.Now(), cfg.Repeat)
+
+	// if notifications could not be delivered at all, keep the previous state.
+	// This causes them to be sent again on the next run.
+	notifyErr := health.Notify(ctx, events)
+	if errors.Is(notifyErr, errNotDelivered) {
+		return notifyErr
+	}
+
+	if err := stateKey.Set(ctx, health.dependencies.Meta.Storage(""), next); err != nil {
+		return errorsx.Combine(notifyErr, fmt.Errorf("failed to store health state: %w", err))
+	}
+	return notifyErr
+}
+
+// State returns the state of all checks as of the last evaluation.
+func (health *Health) State(ctx context.Context) (map[string]models.CheckState, error) {
+	state, err := stateKey.Get(ctx, health.dependencies.Meta.Storage(""))
+	if errors.Is(err, meta.ErrMetadatumNotSet) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get health state: %w", err)
+	}
+	return state, nil
+}
+
+// Notifiers returns the notifiers configured for the distillery.
+func (health *Health) Notifiers() (notifiers []Notifier) {
+	cfg := component.GetStill(health).Config
+	source := cfg.HTTP.PrimaryDomain
+
+	if cfg.Alerts.SMTP.Enabled() {
+		notifiers = append(notifiers, Mailer{Config: cfg.Alerts.SMTP, Source: source})
+	}
+	for _, url := range cfg.Alerts.Webhooks {
+		notifiers = append(notifiers, Webhook{URL: url, Source: source})
+	}
+	return notifiers
+}
+
+var errNotDelivered = errors.New("notification could not be delivered by any notifier")
+
+// Notify sends a notification about events to all configured notifiers.
+// If no notifier delivers the notification, the returned error wraps errNotDelivered.
+func (health *Health) Notify(ctx context.Context, events []Event) error {
+	notifiers := health.Notifiers()
+	if len(events) == 0 || len(notifiers) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	errs := make([]error, len(notifiers))
+	var wg sync.WaitGroup
+	wg.Add(len(notifiers))
+	for i, notifier := range notifiers {
+		go func() {
+			defer wg.Done()
+			errs[i] = notifier.Notify(ctx, events)
+		}()
+	}
+	wg.Wait()
+
+	var failed int
+	var err error
+	for _, e := range errs {
+		if e != nil {
+			failed++
+			err = errorsx.Combine(err, e)
+		}
+	}
+	if failed == len(notifiers) {
+		return fmt.Errorf("%w: %w", errNotDelivered, err)
+	}
+	return err
+}
+
+// Check evaluates all enabled checks and returns their results, sorted by name.
+func (health *Health) Check(ctx context.Context) []Result {
+	disabled := component.GetStill(health).Config.Alerts.Disabled
+	enabled := func(kind string) bool { return !slices.Contains(disabled, kind) }
+
+	var results []Result
+	if enabled(KindSQL) || enabled(KindTriplestore) {
+		results = append(results, health.checkDatabases(ctx, enabled(KindSQL), enabled(KindTriplestore))...)
+	}
+	if enabled(KindCronTask) {
+		results = append(results, health.checkCronTasks()...)
+	}
+	if enabled(KindBarrel) || enabled(KindDrupalCron) {
+		results = append(results, health.checkInstances(ctx, enabled(KindBarrel), enabled(KindDrupalCron))...)
+	}
+
+	slices.SortFunc(results, func(a, b Result) int {
+		return strings.Compare(a.Check, b.Check)
+	})
+	return results
+}
+
+func (health *Health) checkDatabases(ctx context.Context, checkSQL, checkTS bool) (results []Result) {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	if checkSQL {
+		results = append(results, pingResult(KindSQL, health.dependencies.SQL.Ping(ctx)))
+	}
+	if checkTS {
+		results = append(results, pingResult(KindTriplestore, health.dependencies.Triplestore.Ping(ctx)))
+	}
+	return results
+}
+
+func pingResult(check string, err error) Result {
+	if err != nil {
+		return Result{Check: check, Failing: true, Message: err.Error()}
+	}
+	return Result{Check: check}
+}
+
+// checkCronTasks checks that the last run of every cron task succeeded.
+// Because cron tasks run concurrently, this reports the state as of the previous cron run.
+func (health *Health) checkCronTasks() (results []Result) {
+	for _, run := range health.dependencies.Cron.LastRuns() {
+		if run.Task == taskName {
+			continue
+		}
+
+		result := Result{Check: KindCronTask + ":" + run.Task}
+		if run.Failed {
+			result.Failing = true
+			result.Message = fmt.Sprintf("last run started at %s failed", run.Start.Format(time.RFC3339))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// checkInstances checks that the barrel of every instance is running, and that drupal cron ran recently.
+// Instances that are locked or stopped deliberately are not considered failing.
+func (health *Health) checkInstances(ctx context.Context, barrel, drupalCron bool) []Result {
+	all, err := health.dependencies.Instances.All(ctx)
+	if err != nil {
+		wdlog.Of(ctx).Error(
+			"failed to list instances for health checks",
+			"error", err,
+		)
+		return nil
+	}
+
+	maxAge := component.GetStill(health).Config.Alerts.CronMaxAge
+
+	results := make([][]Result, len(all))
+	var wg sync.WaitGroup
+	wg.Add(len(all))
+	for i, instance := range all {
+		go func() {
+			defer wg.Done()
+
+			running, err := instance.Barrel().Running(ctx)
+			if barrel {
+				result := Result{Check: KindBarrel + ":" + instance.Slug}
+				switch {
+				case err != nil:
+					result.Failing = true
+					result.Message = fmt.Sprintf("failed to check if instance is running: %s", err)
+				case !running && !instance.Locker().Locked(ctx) && !health.dependencies.Web.InMaintenance(instance.Slug):
+					result.Failing = true
+					result.Message = "instance is not running"
+				}
+				results[i] = append(results[i], result)
+			}
+
+			if !drupalCron || err != nil || !running {
+				return
+			}
+
+			result := Result{Check: KindDrupalCron + ":" + instance.Slug}
+			last, err := instance.Drush().LastCron(ctx, nil)
+			switch {
+			case err != nil:
+				result.Failing = true
+				result.Message = fmt.Sprintf("failed to determine last cron run: %s", err)
+			case last.IsZero() || last.Unix() <= 0:
+				// cron never ran, e.g. because the instance was just provisioned
+			case time.Since(last) > maxAge:
+				result.Failing = true
+				result.Message = fmt.Sprintf("cron last ran at %s", last.Format(time.RFC3339))
+			}
+			results[i] = append(results[i], result)
+		}()
+	}
+	wg.Wait()
+
+	return slices.Concat(results...)
+}
@@ -0,0 +1,200 @@
+//spellchecker:words health
+package health
+
+//spellchecker:words bytes context crypto encoding json errors http mime smtp strings time github wisski distillery internal config
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/config"
+)
+
+// Notifier sends notifications about events.
+type Notifier interface {
+	// Notify sends a single notification about all of the given events.
+	Notify(ctx context.Context, events []Event) error
+}
+
+// Summarize returns a subject line and a plain text body describing events.
+// source identifies the distillery the events originate from.
+func Summarize(source string, events []Event) (subject, body string) {
+	var failing, recovered int
+	var builder strings.Builder
+	for _, event := range events {
+		switch event.Status {
+		case StatusFailing:
+			failing++
+			fmt.Fprintf(&builder, "FAILING   %s: %s (since %s)\n", event.Check, event.Message, event.Since.Format(time.RFC3339))
+		case StatusRecovered:
+			recovered++
+			fmt.Fprintf(&builder, "RECOVERED %s\n", event.Check)
+		}
+	}
+
+	switch {
+	case failing > 0 && recovered > 0:
+		subject = fmt.Sprintf("[%s] %d check(s) failing, %d check(s) recovered", source, failing, recovered)
+	case failing > 0:
+		subject = fmt.Sprintf("[%s] %d check(s) failing", source, failing)
+	default:
+		subject = fmt.Sprintf("[%s] %d check(s) recovered", source, recovered)
+	}
+	return subject, builder.String()
+}
+
+// Mailer sends notifications via e-mail.
+type Mailer struct {
+	Config config.SMTPConfig
+	Source string // see [Summarize]
+}
+
+var errMailerDisabled = errors.New("no smtp server or recipients configured")
+
+func (mailer Mailer) Notify(ctx context.Context, events []Event) error {
+	if !mailer.Config.Enabled() {
+		return errMailerDisabled
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", mailer.Config.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, mailer.Config.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: mailer.Config.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+	if mailer.Config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", mailer.Config.Username, mailer.Config.Password, mailer.Config.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(mailer.Config.From); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, to := range mailer.Config.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("failed to add recipient %q: %w", to, err)
+		}
+	}
+
+	data, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if _, err := data.Write(mailer.message(events)); err != nil {
+		_ = data.Close()
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := data.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	if err := client.Quit(); err != nil {
+		return fmt.Errorf("failed to end smtp session: %w", err)
+	}
+	return nil
+}
+
+// message returns the e-mail to send for events, including headers.
+func (mailer Mailer) message(events []Event) []byte {
+	subject, body := Summarize(mailer.Source, events)
+
+	var message bytes.Buffer
+	headers := [][2]string{
+		{"From", mailer.Config.From},
+		{"To", strings.Join(mailer.Config.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+	}
+	for _, header := range headers {
+		message.WriteString(header[0] + ": " + header[1] + "\r\n")
+	}
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return message.Bytes()
+}
+
+// Webhook sends notifications by posting JSON to a url.
+// The payload contains a "text" field, making it compatible with Slack and Matrix webhooks.
+type Webhook struct {
+	URL    string
+	Source string // see [Summarize]
+
+	// Client is used to make requests.
+	// When nil, uses [http.DefaultClient].
+	Client *http.Client
+}
+
+// WebhookPayload is the payload posted to a [Webhook].
+type WebhookPayload struct {
+	Text   string  `json:"text"`
+	Source string  `json:"source"`
+	Events []Event `json:"events"`
+}
+
+var errWebhookStatus = errors.New("webhook returned unexpected status")
+
+func (webhook Webhook) Notify(ctx context.Context, events []Event) error {
+	subject, body := Summarize(webhook.Source, events)
+	payload, err := json.Marshal(WebhookPayload{
+		Text:   subject + "\n" + body,
+		Source: webhook.Source,
+		Events: events,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := webhook.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("%w: %s", errWebhookStatus, res.Status)
+	}
+	return nil
+}
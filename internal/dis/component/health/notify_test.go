@@ -0,0 +1,198 @@
+//spellchecker:words health
+package health_test
+
+//spellchecker:words context encoding json http httptest strconv strings testing time textproto github wisski distillery internal config component health
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/config"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/health"
+)
+
+var testEvents = []health.Event{
+	{Check: "barrel:example", Status: health.StatusFailing, Message: "instance is not running", Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	{Check: "sql", Status: health.StatusRecovered, Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+}
+
+// smtpMessage is a message received by an smtpSink.
+type smtpMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+// smtpSink starts a minimal local smtp server accepting a single message.
+// It returns the port it listens on, and a channel the received message is sent to.
+func smtpSink(t *testing.T) (port uint16, messages <-chan smtpMessage) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	received := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		var message smtpMessage
+		text := textproto.NewConn(conn)
+		_ = text.PrintfLine("220 localhost ready")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+
+			verb := strings.ToUpper(strings.Fields(line + " ")[0])
+			switch verb {
+			case "EHLO", "HELO":
+				_ = text.PrintfLine("250 localhost")
+			case "MAIL":
+				message.From = strings.Trim(strings.TrimPrefix(line[len("MAIL"):], " FROM:"), "<>")
+				_ = text.PrintfLine("250 OK")
+			case "RCPT":
+				message.To = append(message.To, strings.Trim(strings.TrimPrefix(line[len("RCPT"):], " TO:"), "<>"))
+				_ = text.PrintfLine("250 OK")
+			case "DATA":
+				_ = text.PrintfLine("354 go ahead")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				message.Data = string(data)
+				_ = text.PrintfLine("250 OK")
+			case "QUIT":
+				_ = text.PrintfLine("221 bye")
+				received <- message
+				return
+			default:
+				_ = text.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+
+	_, portS, _ := net.SplitHostPort(listener.Addr().String())
+	p, err := strconv.ParseUint(portS, 10, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uint16(p), received
+}
+
+func TestMailer_Notify(t *testing.T) {
+	t.Parallel()
+
+	port, messages := smtpSink(t)
+
+	mailer := health.Mailer{
+		Config: config.SMTPConfig{
+			Host: "127.0.0.1",
+			Port: port,
+			From: "distillery@example.com",
+			To:   []string{"admin@example.com", "ops@example.com"},
+		},
+		Source: "wisski.example.com",
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := mailer.Notify(ctx, testEvents); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	message := <-messages
+	if message.From != "distillery@example.com" {
+		t.Errorf("got sender %q", message.From)
+	}
+	if strings.Join(message.To, ",") != "admin@example.com,ops@example.com" {
+		t.Errorf("got recipients %v", message.To)
+	}
+	for _, want := range []string{
+		"Subject: [wisski.example.com] 1 check(s) failing, 1 check(s) recovered",
+		"FAILING   barrel:example: instance is not running",
+		"RECOVERED sql",
+	} {
+		if !strings.Contains(message.Data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, message.Data)
+		}
+	}
+}
+
+func TestMailer_Notify_disabled(t *testing.T) {
+	t.Parallel()
+
+	if err := (health.Mailer{}).Notify(t.Context(), testEvents); err == nil {
+		t.Error("Notify did not return an error without a configured server")
+	}
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	t.Parallel()
+
+	payloads := make(chan health.WebhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var payload health.WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		payloads <- payload
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := health.Webhook{URL: server.URL, Source: "wisski.example.com", Client: server.Client()}
+	if err := webhook.Notify(t.Context(), testEvents); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	payload := <-payloads
+	if !strings.HasPrefix(payload.Text, "[wisski.example.com] 1 check(s) failing, 1 check(s) recovered\n") {
+		t.Errorf("got text %q", payload.Text)
+	}
+	if payload.Source != "wisski.example.com" {
+		t.Errorf("got source %q", payload.Source)
+	}
+	if len(payload.Events) != len(testEvents) || payload.Events[0].Check != "barrel:example" || payload.Events[1].Status != health.StatusRecovered {
+		t.Errorf("got events %v", payload.Events)
+	}
+}
+
+func TestWebhook_Notify_status(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhook := health.Webhook{URL: server.URL, Client: server.Client()}
+	if err := webhook.Notify(t.Context(), testEvents); err == nil {
+		t.Error("Notify did not return an error for a failing webhook")
+	}
+}
@@ -0,0 +1,73 @@
+//spellchecker:words health
+package health
+
+//spellchecker:words slices strings time github wisski distillery internal models
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+// Result is the result of evaluating a single check.
+type Result struct {
+	Check   string // unique name of the check, e.g. "barrel:slug"
+	Failing bool   // whether the check failed
+	Message string // human-readable reason for the failure
+}
+
+// Status is the status reported by an event.
+type Status string
+
+const (
+	StatusFailing   Status = "failing"
+	StatusRecovered Status = "recovered"
+)
+
+// Event is a notification about the state of a single check.
+type Event struct {
+	Check   string    `json:"check"`
+	Status  Status    `json:"status"`
+	Message string    `json:"message,omitempty"`
+	Since   time.Time `json:"since"`
+	Repeat  bool      `json:"repeat"` // a notification about the check failing was sent before
+}
+
+// Evaluate computes the next state of all checks from their previous state and the current results.
+// It returns the next state, and the events that should be notified about.
+//
+// A check that starts failing causes a failing event, and a failing check that passes again a recovered event.
+// While a check keeps failing, the failing event is repeated once repeat has passed since the last notification.
+// Checks that pass and were not known before do not cause any event.
+// Checks without a result are dropped from the state.
+func Evaluate(previous map[string]models.CheckState, results []Result, now time.Time, repeat time.Duration) (next map[string]models.CheckState, events []Event) {
+	next = make(map[string]models.CheckState, len(results))
+	for _, result := range results {
+		state, known := previous[result.Check]
+
+		switch {
+		case result.Failing && (!known || !state.Failing):
+			state = models.CheckState{Failing: true, Since: now, Message: result.Message, Notified: now}
+			events = append(events, Event{Check: result.Check, Status: StatusFailing, Message: result.Message, Since: now})
+		case result.Failing:
+			state.Message = result.Message
+			if now.Sub(state.Notified) >= repeat {
+				state.Notified = now
+				events = append(events, Event{Check: result.Check, Status: StatusFailing, Message: result.Message, Since: state.Since, Repeat: true})
+			}
+		case known && state.Failing:
+			state = models.CheckState{Since: now, Notified: now}
+			events = append(events, Event{Check: result.Check, Status: StatusRecovered, Since: now})
+		case !known:
+			state = models.CheckState{Since: now}
+		}
+
+		next[result.Check] = state
+	}
+
+	slices.SortFunc(events, func(a, b Event) int {
+		return strings.Compare(a.Check, b.Check)
+	})
+	return next, events
+}
@@ -0,0 +1,102 @@
+//spellchecker:words health
+package health_test
+
+//spellchecker:words testing time github wisski distillery internal component health models
+import (
+	"testing"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/health"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	const repeat = time.Hour
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ok := []health.Result{{Check: "barrel:example"}}
+	failing := []health.Result{{Check: "barrel:example", Failing: true, Message: "instance is not running"}}
+
+	// runs the checks through a sequence of results, and records the events of each step
+	steps := []struct {
+		name    string
+		after   time.Duration
+		results []health.Result
+		want    []health.Status // statuses of expected events
+	}{
+		{"first seen ok", 0, ok, nil},
+		{"still ok", time.Minute, ok, nil},
+		{"starts failing", 2 * time.Minute, failing, []health.Status{health.StatusFailing}},
+		{"still failing, deduplicated", 3 * time.Minute, failing, nil},
+		{"still failing, repeated", 2*time.Minute + repeat, failing, []health.Status{health.StatusFailing}},
+		{"still failing after repeat, deduplicated", 3*time.Minute + repeat, failing, nil},
+		{"recovers", 4*time.Minute + repeat, ok, []health.Status{health.StatusRecovered}},
+		{"still recovered", 4*time.Minute + 2*repeat, ok, nil},
+	}
+
+	var state map[string]models.CheckState
+	for _, step := range steps {
+		var events []health.Event
+		state, events = health.Evaluate(state, step.results, start.Add(step.after), repeat)
+
+		if len(events) != len(step.want) {
+			t.Fatalf("%s: got %d event(s), want %d", step.name, len(events), len(step.want))
+		}
+		for i, event := range events {
+			if event.Status != step.want[i] {
+				t.Errorf("%s: event %d has status %q, want %q", step.name, i, event.Status, step.want[i])
+			}
+		}
+	}
+}
+
+func TestEvaluate_firstSeenFailing(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	next, events := health.Evaluate(nil, []health.Result{{Check: "sql", Failing: true, Message: "connection refused"}}, now, time.Hour)
+
+	if len(events) != 1 || events[0].Status != health.StatusFailing || events[0].Repeat {
+		t.Fatalf("got events %v, want a single new failing event", events)
+	}
+	if got := next["sql"]; !got.Failing || !got.Since.Equal(now) || got.Message != "connection refused" {
+		t.Errorf("got state %v", got)
+	}
+}
+
+func TestEvaluate_repeatKeepsSince(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := since.Add(2 * time.Hour)
+	previous := map[string]models.CheckState{
+		"sql": {Failing: true, Since: since, Message: "old", Notified: since},
+	}
+
+	next, events := health.Evaluate(previous, []health.Result{{Check: "sql", Failing: true, Message: "new"}}, now, time.Hour)
+	if len(events) != 1 || !events[0].Repeat || !events[0].Since.Equal(since) {
+		t.Fatalf("got events %v, want a single repeated event", events)
+	}
+	if got := next["sql"]; !got.Since.Equal(since) || !got.Notified.Equal(now) || got.Message != "new" {
+		t.Errorf("got state %v", got)
+	}
+}
+
+func TestEvaluate_dropsChecksWithoutResult(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	previous := map[string]models.CheckState{
+		"barrel:removed": {Failing: true, Since: now, Notified: now},
+	}
+
+	next, events := health.Evaluate(previous, nil, now.Add(time.Minute), time.Hour)
+	if len(events) != 0 {
+		t.Errorf("got events %v, want none", events)
+	}
+	if _, ok := next["barrel:removed"]; ok {
+		t.Error("state of check without result was kept")
+	}
+}
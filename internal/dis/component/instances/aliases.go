@@ -0,0 +1,124 @@
+//spellchecker:words instances
+package instances
+
+//spellchecker:words context errors slices strings github wisski distillery internal config validators component models
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/config"
+	"github.com/FAU-CDI/wisski-distillery/internal/config/validators"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+var (
+	errAliasDistilleryDomain = errors.New("custom domain is a subdomain of the distillery")
+	errAliasDuplicate        = errors.New("custom domain is given more than once")
+	errAliasTaken            = errors.New("custom domain is already used by another instance")
+)
+
+// SlugFromHost is like [config.HTTPConfig.SlugFromHost], but additionally resolves the custom domains of instances.
+//
+// When host is a top-level domain, returns "", true.
+// When no slug is found, returns "", false.
+func (instances *Instances) SlugFromHost(ctx context.Context, host string) (slug string, ok bool) {
+	if slug, ok := component.GetStill(instances).Config.HTTP.SlugFromHost(host); ok {
+		return slug, true
+	}
+
+	instance, err := instances.findAlias(ctx, normAlias(host))
+	if err != nil || instance == nil {
+		return "", false
+	}
+	return instance.Slug, true
+}
+
+// NormSlugFromHost is like [config.HTTPConfig.NormSlugFromHost], but additionally resolves the custom domains of instances.
+func (instances *Instances) NormSlugFromHost(ctx context.Context, host string) (slug string, ok bool) {
+	if slug, ok := component.GetStill(instances).Config.HTTP.NormSlugFromHost(host); ok {
+		return slug, true
+	}
+
+	instance, err := instances.findAlias(ctx, normAlias(host))
+	if err != nil || instance == nil {
+		return "", false
+	}
+	return instance.Slug, true
+}
+
+// ValidateAliases validates and normalizes a comma-separated list of custom domains for the instance with the given slug.
+//
+// Each domain must be valid, may not be a (sub-)domain of the distillery itself, and may not be used by any other instance.
+func (instances *Instances) ValidateAliases(ctx context.Context, slug string, aliases string) (string, error) {
+	cfg := component.GetStill(instances).Config.HTTP
+
+	var domains []string
+	for _, alias := range strings.Split(aliases, ",") {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			continue
+		}
+
+		if err := validators.ValidateDomain(&alias, ""); err != nil {
+			return "", fmt.Errorf("invalid custom domain: %w", err)
+		}
+		if _, ok := cfg.SlugFromHost(alias); ok {
+			return "", fmt.Errorf("%q: %w", alias, errAliasDistilleryDomain)
+		}
+		if slices.Contains(domains, alias) {
+			return "", fmt.Errorf("%q: %w", alias, errAliasDuplicate)
+		}
+
+		other, err := instances.findAlias(ctx, alias)
+		if err != nil {
+			return "", err
+		}
+		if other != nil && other.Slug != slug {
+			return "", fmt.Errorf("%q: %w", alias, errAliasTaken)
+		}
+
+		domains = append(domains, alias)
+	}
+
+	return strings.Join(domains, ","), nil
+}
+
+// findAlias finds the instance that has the given (normalized) custom domain.
+// When no such instance exists, returns nil, nil.
+func (instances *Instances) findAlias(ctx context.Context, alias string) (*models.Instance, error) {
+	if alias == "" {
+		return nil, nil
+	}
+
+	if err := instances.dependencies.SQL.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("failed to wait for database: %w", err)
+	}
+
+	table, err := instances.dependencies.SQL.OpenTable(ctx, instances.dependencies.InstanceTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table: %w", err)
+	}
+
+	// aliases are stored as a comma-separated list, so first find candidates and then check exactly.
+	var candidates []models.Instance
+	if err := table.Where("aliases LIKE ?", "%"+alias+"%").Find(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to find instances: %w", err)
+	}
+	for _, candidate := range candidates {
+		if slices.Contains(candidate.GetAliases(), alias) {
+			return &candidate, nil
+		}
+	}
+	return nil, nil
+}
+
+// normAlias normalizes a host for looking up a custom domain.
+func normAlias(host string) string {
+	domain, _, _ := strings.Cut(host, ":")
+	domain = config.TrimSuffixFold(domain, ".wisski")
+	return strings.ToLower(domain)
+}
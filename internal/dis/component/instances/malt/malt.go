@@ -1,7 +1,7 @@
 //spellchecker:words malt
 package malt
 
-//spellchecker:words github wisski distillery internal component auth policy docker exporter logger meta sshkeys triplestore
+//spellchecker:words github wisski distillery internal component auth policy docker exporter logger meta sshkeys triplestore web
 import (
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/policy"
@@ -11,6 +11,7 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/ssh2/sshkeys"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/triplestore"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/web"
 )
 
 // Malt is a component passed to every WissKI ingredient.
@@ -27,6 +28,7 @@ type Malt struct {
 	Policy      *policy.Policy           `inject:"true"`
 
 	Docker *docker.Docker `inject:"true"`
+	Web    *web.Web       `inject:"true"`
 
 	Keys *sshkeys.SSHKeys `inject:"true"`
 }
@@ -0,0 +1,113 @@
+//spellchecker:words restorer
+package restorer
+
+//spellchecker:words path filepath slices strings github wisski distillery internal component exporter pkglib
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
+	"go.tkw01536.de/pkglib/fsx"
+)
+
+// Parts holds the paths to the parts of a snapshot needed for restoring.
+type Parts struct {
+	DataPath    string // directory holding the data directory
+	SQLFilePath string // sql dump
+	TSFilePath  string // triplestore dump
+}
+
+var (
+	errSnapshotMissingDataPart = errors.New("data part is not present in the snapshot")
+
+	errDataFolderNotDirectory        = errors.New("data folder is not a directory")
+	errSQLDataNotFoundInSnapshot     = errors.New("sql data not found in snapshot")
+	errTriplestoreDataNotRegularFile = errors.New("triplestore data not a regular file")
+
+	errSnapshotMissingPart    = errors.New("snapshot missing part")
+	errDataNotFoundInSnapshot = errors.New("data not found in snapshot")
+)
+
+// ReadParts reads the parts of the staged snapshot needed for restoring.
+// If a part is missing, returns an error.
+func ReadParts(staged *exporter.StagedSnapshot) (parts Parts, err error) {
+	parts, err = ReadDataParts(staged)
+	if err != nil {
+		return Parts{}, err
+	}
+
+	{
+		local, err := findPartPath(staged.Snapshot, "triplestore", "triplestore", "nq")
+		if err != nil {
+			return Parts{}, err
+		}
+
+		parts.TSFilePath = filepath.Join(staged.Path, local)
+		if isFile, err := fsx.IsRegular(parts.TSFilePath, false); !isFile {
+			return Parts{}, fmt.Errorf("%w: %s: %w", errTriplestoreDataNotRegularFile, parts.TSFilePath, cmp.Or(err, fs.ErrNotExist))
+		}
+	}
+
+	return parts, nil
+}
+
+// ReadDataParts is like [ReadParts], but only reads the data directory and sql dump.
+// The TSFilePath of the returned parts is empty.
+func ReadDataParts(staged *exporter.StagedSnapshot) (parts Parts, err error) {
+	archive := staged.Snapshot
+
+	{
+		if !slices.Contains(archive.Description.Parts, "data") {
+			return Parts{}, errSnapshotMissingDataPart
+		}
+
+		parts.DataPath = filepath.Join(staged.Path, "data", "data")
+		if isDirectory, err := fsx.IsDirectory(parts.DataPath, false); !isDirectory {
+			return Parts{}, fmt.Errorf("%w: %w", errDataFolderNotDirectory, cmp.Or(err, fs.ErrNotExist))
+		}
+	}
+
+	{
+		local, err := findPartPath(archive, "sql", "sql", "sql")
+		if err != nil {
+			return Parts{}, err
+		}
+
+		parts.SQLFilePath = filepath.Join(staged.Path, local)
+		if isFile, err := fsx.IsRegular(parts.SQLFilePath, false); !isFile {
+			return Parts{}, fmt.Errorf("%w: %s: %w", errSQLDataNotFoundInSnapshot, parts.SQLFilePath, cmp.Or(err, fs.ErrNotExist))
+		}
+	}
+
+	return parts, nil
+}
+
+func findPartPath(archive exporter.Snapshot, part string, dir string, extension string) (string, error) {
+	if !slices.Contains(archive.Description.Parts, part) {
+		return "", fmt.Errorf("%w: %s", errSnapshotMissingPart, part)
+	}
+
+	extension = "." + extension
+	var candidates []string
+	for _, path := range archive.Manifest {
+		// TODO: This is a very ugly search of the manifest
+		// But it's good enough for now.
+		if !strings.HasPrefix(path, dir+"/") || !strings.HasSuffix(path, extension) {
+			continue
+		}
+		candidates = append(candidates, path)
+	}
+
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("%w: No %s files found in manifest", errDataNotFoundInSnapshot, extension)
+	}
+	if len(candidates) > 1 {
+		return "", fmt.Errorf("%w: Multiple %s files found in manifest", errDataNotFoundInSnapshot, extension)
+	}
+	return candidates[0], nil
+}
@@ -0,0 +1,237 @@
+//spellchecker:words restorer
+package restorer
+
+//spellchecker:words context path filepath time github wisski distillery internal component exporter instances purger provision logging pkglib contextx errorsx stream
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances/purger"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/provision"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
+	"github.com/FAU-CDI/wisski-distillery/pkg/logging"
+	"go.tkw01536.de/pkglib/contextx"
+	"go.tkw01536.de/pkglib/errorsx"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+// Restorer restores instances from snapshots.
+type Restorer struct {
+	component.Base
+	dependencies struct {
+		Provision *provision.Provision
+		Purger    *purger.Purger
+	}
+}
+
+// Clone provisions a new instance with the given slug, and restores the staged snapshot into it.
+// The new instance uses the system configuration recorded in the snapshot, see [models.System.ForClone].
+//
+// If the snapshot cannot be restored into the new instance, the new instance is purged again.
+func (restorer *Restorer) Clone(ctx context.Context, progress io.Writer, slug string, staged *exporter.StagedSnapshot) (instance *wisski.WissKI, e error) {
+	snapshot := staged.Snapshot
+
+	provisioned := false
+	defer func() {
+		if e == nil || !provisioned {
+			return
+		}
+
+		// purge even if the context was cancelled, to not leave a half-restored instance behind
+		if err := logging.LogOperation(func() error {
+			return restorer.dependencies.Purger.Purge(context.WithoutCancel(ctx), progress, slug)
+		}, progress, "Purging new instance"); err != nil {
+			e = errorsx.Combine(e, fmt.Errorf("failed to purge new instance: %w", err))
+		}
+		instance = nil
+	}()
+
+	if err := logging.LogOperation(func() (err error) {
+		instance, err = restorer.dependencies.Provision.Provision(progress, ctx, provision.Flags{
+			Slug:   slug,
+			System: snapshot.Instance.System.ForClone(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to provision instance: %w", err)
+		}
+		provisioned = true
+
+		// carry over the remaining settings of the original instance
+		instance.OwnerEmail = snapshot.Instance.OwnerEmail
+		instance.AutoBlindUpdateEnabled = snapshot.Instance.AutoBlindUpdateEnabled
+		if err := instance.Bookkeeping().Save(ctx); err != nil {
+			return fmt.Errorf("failed to save bookkeeping data: %w", err)
+		}
+		return nil
+	}, progress, "Provisioning new instance"); err != nil {
+		return nil, fmt.Errorf("failed to provision new instance: %w", err)
+	}
+
+	if err := restorer.Restore(ctx, progress, instance, staged); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// restoreEstimate is the estimated time restoring a snapshot takes.
+const restoreEstimate = 30 * time.Minute
+
+// Restore restores the staged snapshot into the given instance.
+// Any existing data of the instance is overwritten.
+//
+// The instance is locked for the duration of the restore.
+func (restorer *Restorer) Restore(ctx context.Context, progress io.Writer, instance *wisski.WissKI, staged *exporter.StagedSnapshot) error {
+	parts, err := ReadParts(staged)
+	if err != nil {
+		return fmt.Errorf("snapshot is not suitable for restoration: %w", err)
+	}
+	return restorer.restore(ctx, progress, instance, parts, "A snapshot is being restored into the instance.")
+}
+
+// Rollback is like [Restorer.Restore], but only restores the data directory and sql database.
+// The triplestore of the instance is left untouched.
+//
+// It is used to undo changes to the codebase and database of an instance, such as a failed update.
+func (restorer *Restorer) Rollback(ctx context.Context, progress io.Writer, instance *wisski.WissKI, staged *exporter.StagedSnapshot) error {
+	parts, err := ReadDataParts(staged)
+	if err != nil {
+		return fmt.Errorf("snapshot is not suitable for rollback: %w", err)
+	}
+	return restorer.restore(ctx, progress, instance, parts, "The instance is being rolled back to a previous snapshot.")
+}
+
+// restore restores the given parts into instance.
+// When parts.TSFilePath is empty, the triplestore is not restored.
+func (restorer *Restorer) restore(ctx context.Context, progress io.Writer, instance *wisski.WissKI, parts Parts, reason string) (e error) {
+	if _, err := logging.LogMessage(progress, "Locking instance"); err != nil {
+		return fmt.Errorf("failed to log message: %w", err)
+	}
+	if err := instance.Locker().TryLock(ctx, reason, restoreEstimate); err != nil {
+		return fmt.Errorf("failed to lock instance: %w", err)
+	}
+	defer func() {
+		// #nosec G104
+		logging.LogMessage(progress, "Unlocking instance") //nolint:errcheck // no way to report error
+
+		ctx, cancel := contextx.Anyways(ctx, time.Second)
+		defer cancel()
+
+		instance.Locker().Unlock(ctx)
+	}()
+
+	if err := logging.LogOperation(func() error {
+		return shutdownInstance(ctx, progress, instance)
+	}, progress, "Shutting down instance"); err != nil {
+		return fmt.Errorf("failed to shutdown instance: %w", err)
+	}
+
+	// Data
+	if err := logging.LogOperation(func() error {
+		oldDataDirectory := filepath.Join(instance.FilesystemBase, "data")
+
+		_, _ = fmt.Fprintf(progress, "Old data directory: %s\n", oldDataDirectory)
+		_, _ = fmt.Fprintf(progress, "New data directory: %s\n", parts.DataPath)
+
+		return parts.restoreDataDirectory(progress, oldDataDirectory)
+	}, progress, "Restoring data directory"); err != nil {
+		return fmt.Errorf("failed to restore data directory: %w", err)
+	}
+
+	// Triplestore
+	if parts.TSFilePath != "" {
+		if err := logging.LogOperation(func() error {
+			return parts.restoreTriplestore(ctx, progress, instance)
+		}, progress, "Restoring triplestore"); err != nil {
+			return fmt.Errorf("failed to restore triplestore: %w", err)
+		}
+	}
+
+	// SQL
+	if err := logging.LogOperation(func() error {
+		return parts.restoreSQL(ctx, progress, instance)
+	}, progress, "Restoring SQL"); err != nil {
+		return fmt.Errorf("failed to restore SQL database: %w", err)
+	}
+
+	// Restart instance
+	// TODO: Restart in dummy mode!
+	if err := logging.LogOperation(func() error {
+		return startInstance(ctx, progress, instance)
+	}, progress, "Re-Starting instance"); err != nil {
+		return fmt.Errorf("failed to restart instance: %w", err)
+	}
+
+	// Re-set permissions
+	if err := logging.LogOperation(func() error {
+		barrel := instance.Barrel()
+		for _, script := range [][]string{
+			{"chown", "-R", "www-data:www-data", "/var/www/data/project/"},
+		} {
+			if err := barrel.BashScriptAs(ctx, "root", stream.NonInteractive(progress), script...); err != nil {
+				return fmt.Errorf("failed to reset permissions: %w", err)
+			}
+		}
+		return nil
+	}, progress, "Re-setting permissions and ownership"); err != nil {
+		return fmt.Errorf("failed to reset permissions: %w", err)
+	}
+
+	// Re-create SQL config, as the database credentials may differ from the ones in the snapshot
+	if err := logging.LogOperation(func() error {
+		if err := instance.Settings().SetDefaultDBConnection(ctx, nil, instance.BoundSQL().SQLUrl()); err != nil {
+			return fmt.Errorf("%w: %w", errFailedToRestoreSQLConfig, err)
+		}
+		return startInstance(ctx, progress, instance)
+	}, progress, "Re-Creating SQL config"); err != nil {
+		return fmt.Errorf("failed to re-create SQL config: %w", err)
+	}
+
+	// Re-create Adapter
+	if err := logging.LogOperation(func() error {
+		if _, err := instance.Adapters().SetAdapter(ctx, nil, instance.Adapters().DefaultAdapter()); err != nil {
+			return fmt.Errorf("failed to restore adapter: %w", err)
+		}
+		return nil
+	}, progress, "Re-Creating Adapter"); err != nil {
+		return fmt.Errorf("failed to re-create adapter: %w", err)
+	}
+
+	// Re-build settings.
+	// This also points the trusted host patterns to the domain of the instance,
+	// which differs from the snapshot when restoring into a new instance.
+	if err := logging.LogOperation(func() error {
+		if err := instance.SystemManager().Apply(ctx, progress, instance.System); err != nil {
+			return fmt.Errorf("failed to apply settings: %w", err)
+		}
+		return waitSQL(ctx, progress, instance)
+	}, progress, "Re-Applying settings"); err != nil {
+		return fmt.Errorf("failed to re-apply settings: %w", err)
+	}
+
+	// clear cache
+	if err := logging.LogOperation(func() error {
+		if err := instance.Drush().Exec(ctx, progress, "cr"); err != nil {
+			return fmt.Errorf("failed to run drush cr: %w", err)
+		}
+		return nil
+	}, progress, "Clearing cache"); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+
+	// and do a final restart for good measure ...
+	if err := logging.LogOperation(func() error {
+		return startInstance(ctx, progress, instance)
+	}, progress, "Re-Starting instance"); err != nil {
+		return fmt.Errorf("failed to restart instance: %w", err)
+	}
+
+	if _, err := logging.LogMessage(progress, "Instance should be restored."); err != nil {
+		return fmt.Errorf("failed to log message: %w", err)
+	}
+	return nil
+}
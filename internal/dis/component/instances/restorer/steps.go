@@ -0,0 +1,273 @@
+//spellchecker:words restorer
+package restorer
+
+//spellchecker:words context errors path filepath github wisski distillery internal ingredient logging pkglib errorsx status stream
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient"
+	"github.com/FAU-CDI/wisski-distillery/pkg/logging"
+	"go.tkw01536.de/pkglib/errorsx"
+	"go.tkw01536.de/pkglib/status"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+var (
+	errFailedToOpenStack                    = errors.New("failed to open stack")
+	errFailedToCreateTemporaryDirectory     = errors.New("failed to create temporary directory")
+	errFailedToComputeRelativePath          = errors.New("failed to compute relative path")
+	errFailedToGetDirectoryInfo             = errors.New("failed to get directory info")
+	errFailedToCreateDirectory              = errors.New("failed to create directory")
+	errFailedToOpenFile                     = errors.New("failed to open file")
+	errFailedToCopyFile                     = errors.New("failed to copy file")
+	errFailedToCopyDirectory                = errors.New("failed to copy directory")
+	errFailedToRemoveOldDirectory           = errors.New("failed to remove old directory")
+	errFailedToMoveRestoredDirectoryInPlace = errors.New("failed to move restored directory into place")
+
+	errFailedToLogMessage                 = errors.New("failed to log message")
+	errFailedToPurgeTriplestoreData       = errors.New("failed to purge triplestore data")
+	errFailedToProvisionTriplestore       = errors.New("failed to provision triplestore")
+	errFailedToOpenTriplestoreBackup      = errors.New("failed to open triplestore backup")
+	errFailedToRestoreTriplestoreContents = errors.New("failed to restore triplestore contents")
+
+	errFailedToPurgeSQLDatabase     = errors.New("failed to purge SQL database")
+	errFailedToProvisionSQLDatabase = errors.New("failed to provision SQL database")
+	errFailedToOpenSQLBackup        = errors.New("failed to open SQL backup")
+	errFailedToRestoreSQLContents   = errors.New("failed to restore SQL contents")
+	errFailedToRestoreSQLConfig     = errors.New("failed to restore SQL config")
+
+	errFailedToLstat         = errors.New("failed to lstat")
+	errFailedToWalkDir       = errors.New("failed to walk dir")
+	errFailedToCreateSymlink = errors.New("failed to create symlink")
+
+	errFailedToShutdownInstance = errors.New("failed to shutdown instance")
+	errFailedToStartInstance    = errors.New("failed to start instance")
+	errFailedToWaitSQL          = errors.New("failed to wait for SQL")
+)
+
+func shutdownInstance(ctx context.Context, progress io.Writer, instance *wisski.WissKI) (e error) {
+	stack, err := instance.Barrel().OpenStack()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToOpenStack, err)
+	}
+	defer errorsx.Close(stack, &e, "stack")
+
+	if err := stack.Down(ctx, progress); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToShutdownInstance, err)
+	}
+	return nil
+}
+
+func startInstance(ctx context.Context, progress io.Writer, instance *wisski.WissKI) (e error) {
+	stack, err := instance.Barrel().OpenStack()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToOpenStack, err)
+	}
+	defer errorsx.Close(stack, &e, "stack")
+	if err := stack.Start(ctx, progress); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToStartInstance, err)
+	}
+
+	return waitSQL(ctx, progress, instance)
+}
+
+func waitSQL(ctx context.Context, progress io.Writer, instance *wisski.WissKI) (e error) {
+	// wait for the sql to be up
+	if err := instance.BoundSQL().Impl.StartAndWait(ctx, progress); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWaitSQL, err)
+	}
+	return nil
+}
+
+func (parts Parts) restoreDataDirectory(progress io.Writer, old string) (e error) {
+	fresh := parts.DataPath
+
+	st := status.NewWithCompat(progress, 1)
+	st.Start()
+	defer st.Set(0, "")
+	defer st.Stop()
+
+	// Create a temporary directory next to the old directory
+	oldParent := filepath.Dir(old)
+	tempDir, err := os.MkdirTemp(oldParent, ".restore-*")
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToCreateTemporaryDirectory, err)
+	}
+
+	// Clean up temporary directory on failure
+	defer func() {
+		if e != nil {
+			if _, err := logging.LogMessage(progress, "failed to restore, cleaning up"); err != nil {
+				e = errorsx.Combine(e, fmt.Errorf("%w: %w", errFailedToLogMessage, err))
+			}
+			err := os.RemoveAll(tempDir)
+			e = errorsx.Combine(e, err)
+		}
+	}()
+
+	// Copy the new directory contents to the temporary directory
+	if err := filepath.WalkDir(fresh, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Compute relative path and destination
+		relPath, err := filepath.Rel(fresh, path)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errFailedToComputeRelativePath, err)
+		}
+		destPath := filepath.Join(tempDir, relPath)
+
+		st.Set(0, relPath)
+
+		if d.IsDir() {
+			// Create directory with same permissions
+			info, err := d.Info()
+			if err != nil {
+				return fmt.Errorf("%w: %w", errFailedToGetDirectoryInfo, err)
+			}
+			if err := os.MkdirAll(destPath, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("%w: %w", errFailedToCreateDirectory, &fs.PathError{Op: "mkdirall", Path: destPath, Err: err})
+			}
+			return nil
+		}
+
+		// Copy regular file
+		if err := copyFile(path, destPath); err != nil {
+			return fmt.Errorf("%w: %w", errFailedToCopyFile, &fs.PathError{Op: "copy", Path: relPath, Err: err})
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToCopyDirectory, err)
+	}
+
+	// Remove the old directory
+	if err := os.RemoveAll(old); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToRemoveOldDirectory, err)
+	}
+
+	// Move the temporary directory to the old directory's place
+	if err := os.Rename(tempDir, old); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToMoveRestoredDirectoryInPlace, err)
+	}
+
+	return nil
+}
+
+// Copies a file from src to dst.
+// If it is a symlink, it is copied as a symlink.
+func copyFile(src, dst string) (e error) {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToLstat, err)
+	}
+
+	if srcInfo.Mode()&os.ModeSymlink != 0 {
+		linkTarget, err := os.Readlink(src)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errFailedToWalkDir, err)
+		}
+		if err := os.Symlink(linkTarget, dst); err != nil {
+			return fmt.Errorf("%w: %w", errFailedToCreateSymlink, err)
+		}
+		return nil
+	}
+
+	srcFile, err := os.Open(src) // #nosec G304 -- intended
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToOpenFile, err)
+	}
+	defer errorsx.Close(srcFile, &e, "src file")
+
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, srcInfo.Mode().Perm()) // #nosec G304 -- intended
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToOpenFile, err)
+	}
+	defer errorsx.Close(dstFile, &e, "dst file")
+
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToCopyFile, err)
+	}
+
+	return nil
+}
+
+func (parts Parts) restoreTriplestore(ctx context.Context, progress io.Writer, instance *wisski.WissKI) (e error) {
+	liquid := ingredient.GetLiquid(instance.TRB())
+
+	stack, err := instance.Barrel().OpenStack()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToOpenStack, err)
+	}
+	defer errorsx.Close(stack, &e, "stack")
+
+	if _, err := logging.LogMessage(progress, "Purging triplestore repository"); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
+	}
+	if err := liquid.TS.For(liquid.Instance).Purge(ctx, progress, true); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToPurgeTriplestoreData, err)
+	}
+
+	if _, err := logging.LogMessage(progress, "Re-provisioning triplestore repository"); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
+	}
+	if err := liquid.TS.Provision(ctx, progress, liquid.Instance, liquid.Domain(), &stack); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToProvisionTriplestore, err)
+	}
+
+	if _, err := logging.LogMessage(progress, "Restoring triplestore contents"); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
+	}
+
+	file, err := os.Open(parts.TSFilePath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToOpenTriplestoreBackup, &fs.PathError{Op: "open", Path: parts.TSFilePath, Err: err})
+	}
+	defer errorsx.Close(file, &e, "file")
+
+	if err := liquid.TS.For(liquid.Instance).RestoreDB(ctx, progress, file); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToRestoreTriplestoreContents, err)
+	}
+	return nil
+}
+
+func (parts Parts) restoreSQL(ctx context.Context, progress io.Writer, instance *wisski.WissKI) (e error) {
+	liquid := ingredient.GetLiquid(instance.TRB())
+
+	if _, err := logging.LogMessage(progress, "Purging SQL database"); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
+	}
+	if err := liquid.BoundSQL().Purge(ctx, progress); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToPurgeSQLDatabase, err)
+	}
+
+	if _, err := logging.LogMessage(progress, "Re-provisioning SQL database"); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
+	}
+	if err := liquid.BoundSQL().Provision(ctx, progress); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToProvisionSQLDatabase, err)
+	}
+
+	if _, err := logging.LogMessage(progress, "Restoring SQL contents"); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToLogMessage, err)
+	}
+
+	file, err := os.Open(parts.SQLFilePath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToOpenSQLBackup, &fs.PathError{Op: "open", Path: parts.SQLFilePath, Err: err})
+	}
+	defer errorsx.Close(file, &e, "file")
+
+	if err := liquid.BoundSQL().Restore(ctx, file, stream.NewIOStream(progress, progress, nil)); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToRestoreSQLContents, err)
+	}
+	return nil
+}
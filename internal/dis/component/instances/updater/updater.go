@@ -0,0 +1,154 @@
+// Package updater implements guarded updates of instances.
+//
+//spellchecker:words updater
+package updater
+
+//spellchecker:words context errors http time github wisski distillery internal component exporter instances restorer models logging pkglib errorsx
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances/restorer"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
+	"github.com/FAU-CDI/wisski-distillery/pkg/logging"
+	"go.tkw01536.de/pkglib/errorsx"
+)
+
+// Updater performs guarded updates of instances.
+//
+// A guarded update takes a snapshot of the instance before running the update, and checks that the instance still responds afterwards.
+// If either the update or the check fails, the instance is rolled back to the snapshot.
+type Updater struct {
+	component.Base
+	dependencies struct {
+		Exporter *exporter.Exporter
+		Restorer *restorer.Restorer
+	}
+}
+
+// guardedParts are the snapshot parts taken before a guarded update.
+// Updates only change the codebase and the sql database, so the triplestore is not included.
+var guardedParts = []string{"data", "sql"}
+
+// smokeTimeout is the maximal time the instance may take to respond to the smoke check.
+const smokeTimeout = time.Minute
+
+var (
+	errGuardedSnapshot   = errors.New("failed to take snapshot before updating")
+	errGuardedRolledBack = errors.New("update failed and was rolled back")
+	errGuardedRollback   = errors.New("update failed and could not be rolled back")
+	errSmokeStatus       = errors.New("instance responded with a server error")
+)
+
+// Update runs a guarded update of instance, and records the outcome in the metadata of the instance.
+// See [Updater].
+//
+// The returned error is nil if the update succeeded.
+func (updater *Updater) Update(ctx context.Context, progress io.Writer, instance *wisski.WissKI) (err error) {
+	outcome := models.UpdateOutcome{Started: time.Now()}
+	defer func() {
+		if err != nil {
+			outcome.Error = err.Error()
+		}
+		err = errorsx.Combine(err, instance.Composer().SetGuardedUpdate(context.WithoutCancel(ctx), outcome))
+	}()
+
+	// take the snapshot
+	var staged *exporter.StagedSnapshot
+	if err := logging.LogOperation(func() (err error) {
+		staged, err = updater.snapshot(ctx, progress, instance)
+		return err
+	}, progress, "Taking snapshot"); err != nil {
+		outcome.Result = models.UpdateSkipped
+		return fmt.Errorf("%w: %w", errGuardedSnapshot, err)
+	}
+	defer func() {
+		err = errorsx.Combine(err, staged.Close())
+	}()
+	outcome.Snapshot = staged.Path
+
+	// run the update, and check that the instance still works
+	updateErr := logging.LogOperation(func() error {
+		if err := instance.Composer().Update(ctx, progress); err != nil {
+			return fmt.Errorf("failed to update: %w", err)
+		}
+		return nil
+	}, progress, "Running update")
+	if updateErr == nil {
+		updateErr = logging.LogOperation(func() error {
+			return SmokeCheck(ctx, instance)
+		}, progress, "Checking instance")
+	}
+	if updateErr == nil {
+		outcome.Result = models.UpdateSucceeded
+		return nil
+	}
+
+	// Rolling back regularly takes longer than the context is limited to.
+	// Cancelling it halfway would leave the instance broken, so it is not cancelled.
+	if err := logging.LogOperation(func() error {
+		return updater.dependencies.Restorer.Rollback(context.WithoutCancel(ctx), progress, instance, staged)
+	}, progress, "Rolling back instance"); err != nil {
+		outcome.Result = models.UpdateFailed
+		return fmt.Errorf("%w: %w", errGuardedRollback, errors.Join(updateErr, err))
+	}
+
+	outcome.Result = models.UpdateRolledBack
+	return fmt.Errorf("%w: %w", errGuardedRolledBack, updateErr)
+}
+
+// snapshot takes a snapshot of the guarded parts of instance, and stages it.
+// The snapshot is kept in the staging area, and recorded in the export log.
+func (updater *Updater) snapshot(ctx context.Context, progress io.Writer, instance *wisski.WissKI) (*exporter.StagedSnapshot, error) {
+	dest, err := updater.dependencies.Exporter.NewStagingDir(instance.Slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	if err := updater.dependencies.Exporter.MakeExport(ctx, progress, exporter.ExportTask{
+		Dest:        dest,
+		StagingOnly: true,
+		Instance:    instance,
+
+		SnapshotDescription: exporter.SnapshotDescription{
+			Parts: guardedParts,
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to make snapshot: %w", err)
+	}
+
+	staged, err := updater.dependencies.Exporter.Stage(ctx, progress, dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage snapshot: %w", err)
+	}
+	return staged, nil
+}
+
+// SmokeCheck checks that the public url of instance responds without a server error.
+func SmokeCheck(ctx context.Context, instance *wisski.WissKI) (e error) {
+	ctx, cancel := context.WithTimeout(ctx, smokeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, instance.URL().String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer errorsx.Close(res.Body, &e, "response body")
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %s", errSmokeStatus, res.Status)
+	}
+	return nil
+}
@@ -70,6 +70,10 @@ func (pv *Provision) validate(flags Flags) error {
 	if flags.Flavor != "" && !manager.HasProfile(flags.Flavor) {
 		return unknownFlavorError(flags.Flavor)
 	}
+	// check the resource settings
+	if err := flags.System.ValidateResources(); err != nil {
+		return fmt.Errorf("invalid resource settings: %w", err)
+	}
 	return nil
 }
 
@@ -80,6 +84,21 @@ func (pv *Provision) Provision(progress io.Writer, ctx context.Context, flags Fl
 		return nil, fmt.Errorf("failed to validate flags: %w", err)
 	}
 
+	// check the custom domains
+	aliases, err := pv.dependencies.Instances.ValidateAliases(ctx, flags.Slug, flags.System.Aliases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate flags: %w", err)
+	}
+	flags.System.Aliases = aliases
+
+	// check the access protection
+	if err := flags.System.ValidateLimits(); err != nil {
+		return nil, fmt.Errorf("failed to validate flags: %w", err)
+	}
+	if err := flags.System.HashBasicAuth(); err != nil {
+		return nil, fmt.Errorf("failed to validate flags: %w", err)
+	}
+
 	// check that it doesn't already exist
 	if _, err := logging.LogMessage(progress, "Provisioning new WissKI instance %s", flags.Slug); err != nil {
 		return nil, fmt.Errorf("failed to log message: %w", err)
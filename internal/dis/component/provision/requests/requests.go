@@ -0,0 +1,303 @@
+// Package requests implements requests for new instances by distillery users.
+//
+//spellchecker:words requests
+package requests
+
+//spellchecker:words context errors strings time github wisski distillery internal component audit auth policy instances provision models ingredient barrel manager gorm
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/policy"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/provision"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/barrel/manager"
+	"gorm.io/gorm"
+)
+
+// Requests manages requests for new instances.
+// Requests are submitted by distillery users, and approved or rejected by distillery admins.
+type Requests struct {
+	component.Base
+	dependencies struct {
+		SQL       *sql.SQL
+		Instances *instances.Instances
+		Provision *provision.Provision
+		Policy    *policy.Policy
+		Audit     *audit.Audit
+	}
+}
+
+var (
+	_ component.Table          = (*Requests)(nil)
+	_ component.UserDeleteHook = (*Requests)(nil)
+)
+
+func (*Requests) TableInfo() component.TableInfo {
+	return component.TableInfo{
+		Model: models.InstanceRequest{},
+	}
+}
+
+func (req *Requests) table(ctx context.Context) (*gorm.DB, error) {
+	conn, err := req.dependencies.SQL.OpenTable(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table: %w", err)
+	}
+	return conn, nil
+}
+
+// OnUserDelete removes the pending requests of the user.
+func (req *Requests) OnUserDelete(ctx context.Context, user *models.User) error {
+	table, err := req.table(ctx)
+	if err != nil {
+		return err
+	}
+	return table.Where("user = ? AND status = ?", user.User, models.RequestPending).Delete(&models.InstanceRequest{}).Error
+}
+
+const (
+	// MaxPending is the maximal number of pending requests per user.
+	MaxPending = 3
+
+	// MaxPurposeLength is the maximal length of the purpose of a request.
+	MaxPurposeLength = 2000
+)
+
+var (
+	ErrNotFound        = errors.New("request not found")
+	ErrNotPending      = errors.New("request was already approved or rejected")
+	ErrTooManyPending  = errors.New("too many requests are pending")
+	ErrSlugTaken       = errors.New("an instance with this slug already exists or has been requested")
+	ErrUnknownFlavor   = errors.New("unknown flavor")
+	ErrPurposeRequired = errors.New("purpose must be given")
+	ErrPurposeTooLong  = errors.New("purpose is too long")
+)
+
+// Submit submits a new request by user for an instance with the given slug and flavor.
+func (req *Requests) Submit(ctx context.Context, user string, slug string, flavor string, purpose string) (request models.InstanceRequest, err error) {
+	defer func() {
+		req.dependencies.Audit.Record(ctx, "request.submit", "", map[string]string{"user": user, "slug": slug, "flavor": flavor}, err)
+	}()
+
+	request = models.InstanceRequest{
+		Created: time.Now(),
+		User:    user,
+		Flavor:  flavor,
+		Purpose: strings.TrimSpace(purpose),
+		Status:  models.RequestPending,
+	}
+
+	// validate the request itself
+	request.Slug, err = req.dependencies.Instances.IsValidSlug(slug)
+	if err != nil {
+		return request, fmt.Errorf("invalid slug %q: %w", slug, err)
+	}
+	if flavor != "" && !manager.HasProfile(flavor) {
+		return request, fmt.Errorf("%w %q", ErrUnknownFlavor, flavor)
+	}
+	if request.Purpose == "" {
+		return request, ErrPurposeRequired
+	}
+	if len(request.Purpose) > MaxPurposeLength {
+		return request, ErrPurposeTooLong
+	}
+
+	// check that the slug is still available
+	exists, err := req.dependencies.Instances.Has(ctx, request.Slug)
+	if err != nil {
+		return request, fmt.Errorf("failed to check if instance exists: %w", err)
+	}
+	if exists {
+		return request, ErrSlugTaken
+	}
+
+	table, err := req.table(ctx)
+	if err != nil {
+		return request, err
+	}
+
+	var count int64
+	if err := table.Model(&models.InstanceRequest{}).Where("slug = ? AND status = ?", request.Slug, models.RequestPending).Count(&count).Error; err != nil {
+		return request, fmt.Errorf("failed to check for requests: %w", err)
+	}
+	if count > 0 {
+		return request, ErrSlugTaken
+	}
+
+	if err := table.Model(&models.InstanceRequest{}).Where("user = ? AND status = ?", user, models.RequestPending).Count(&count).Error; err != nil {
+		return request, fmt.Errorf("failed to count pending requests: %w", err)
+	}
+	if count >= MaxPending {
+		return request, ErrTooManyPending
+	}
+
+	if err := table.Create(&request).Error; err != nil {
+		return request, fmt.Errorf("failed to create request: %w", err)
+	}
+	return request, nil
+}
+
+// User returns all requests made by the given user, newest first.
+func (req *Requests) User(ctx context.Context, user string) ([]models.InstanceRequest, error) {
+	table, err := req.table(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []models.InstanceRequest
+	if err := table.Where("user = ?", user).Order("created desc").Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to find requests: %w", err)
+	}
+	return requests, nil
+}
+
+// Pending returns all pending requests, oldest first.
+func (req *Requests) Pending(ctx context.Context) ([]models.InstanceRequest, error) {
+	table, err := req.table(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []models.InstanceRequest
+	if err := table.Where("status = ?", models.RequestPending).Order("created asc").Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to find requests: %w", err)
+	}
+	return requests, nil
+}
+
+// Decided returns the most recently approved or rejected requests, newest first.
+func (req *Requests) Decided(ctx context.Context, limit int) ([]models.InstanceRequest, error) {
+	table, err := req.table(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []models.InstanceRequest
+	if err := table.Where("status <> ?", models.RequestPending).Order("decided desc").Limit(limit).Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to find requests: %w", err)
+	}
+	return requests, nil
+}
+
+// Get returns the request with the given id.
+func (req *Requests) Get(ctx context.Context, pk uint) (request models.InstanceRequest, err error) {
+	table, err := req.table(ctx)
+	if err != nil {
+		return request, err
+	}
+
+	res := table.Where("pk = ?", pk).Find(&request)
+	if err := res.Error; err != nil {
+		return request, fmt.Errorf("failed to find request: %w", err)
+	}
+	if res.RowsAffected == 0 {
+		return request, ErrNotFound
+	}
+	return request, nil
+}
+
+// Withdraw removes the pending request with the given id made by user.
+func (req *Requests) Withdraw(ctx context.Context, user string, pk uint) (err error) {
+	defer func() {
+		req.dependencies.Audit.Record(ctx, "request.withdraw", "", map[string]any{"user": user, "request": pk}, err)
+	}()
+
+	table, err := req.table(ctx)
+	if err != nil {
+		return err
+	}
+
+	res := table.Where("pk = ? AND user = ? AND status = ?", pk, user, models.RequestPending).Delete(&models.InstanceRequest{})
+	if err := res.Error; err != nil {
+		return fmt.Errorf("failed to delete request: %w", err)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Reject rejects the pending request with the given id, giving the reason to the user.
+func (req *Requests) Reject(ctx context.Context, pk uint, reason string) (err error) {
+	defer func() {
+		req.dependencies.Audit.Record(ctx, "request.reject", "", map[string]any{"request": pk, "reason": reason}, err)
+	}()
+
+	request, err := req.Get(ctx, pk)
+	if err != nil {
+		return err
+	}
+	if !request.Pending() {
+		return ErrNotPending
+	}
+
+	request.Reason = strings.TrimSpace(reason)
+	return req.decide(ctx, request, models.RequestRejected)
+}
+
+// Approve provisions the instance requested by the pending request with the given id.
+// Progress of provisioning is written to progress.
+//
+// Once provisioned, the requesting user is granted [models.RoleAdmin] on the new instance.
+func (req *Requests) Approve(ctx context.Context, progress io.Writer, pk uint) (instance *wisski.WissKI, err error) {
+	var request models.InstanceRequest
+	defer func() {
+		req.dependencies.Audit.Record(ctx, "request.approve", request.Slug, map[string]any{"request": pk, "user": request.User}, err)
+	}()
+
+	request, err = req.Get(ctx, pk)
+	if err != nil {
+		return nil, err
+	}
+	if !request.Pending() {
+		return nil, ErrNotPending
+	}
+
+	instance, err = req.dependencies.Provision.Provision(progress, ctx, provision.Flags{
+		Slug:   request.Slug,
+		Flavor: request.Flavor,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision instance: %w", err)
+	}
+
+	if err := req.decide(ctx, request, models.RequestApproved); err != nil {
+		return instance, err
+	}
+
+	grant := models.Grant{User: request.User, Slug: request.Slug}
+	grant.SetRole(models.RoleAdmin)
+	if err := req.dependencies.Policy.Set(ctx, grant); err != nil {
+		return instance, fmt.Errorf("failed to grant access to requester: %w", err)
+	}
+
+	return instance, nil
+}
+
+// decide marks request as decided with the given status.
+func (req *Requests) decide(ctx context.Context, request models.InstanceRequest, status string) error {
+	table, err := req.table(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	request.Status = status
+	request.Decided = &now
+	request.Decider = audit.ActorOf(ctx).User
+
+	if err := table.Select("status", "reason", "decided_by", "decided").Save(&request).Error; err != nil {
+		return fmt.Errorf("failed to update request: %w", err)
+	}
+	return nil
+}
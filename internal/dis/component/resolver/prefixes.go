@@ -31,10 +31,17 @@ func (resolver *Resolver) AllPrefixes(ctx context.Context) (map[string]string, e
 	gPrefixes := make(map[string]string)
 	var lastErr error
 	for _, instance := range instances {
+		url := instance.URL().String()
+
+		// uris under custom domains always belong to the instance
+		for _, alias := range instance.GetAliases() {
+			gPrefixes["http://"+alias+"/"] = url
+			gPrefixes["https://"+alias+"/"] = url
+		}
+
 		if instance.Prefixes().NoPrefix() {
 			continue
 		}
-		url := instance.URL().String()
 
 		// failed to fetch prefixes for this particular instance
 		// => skip it!
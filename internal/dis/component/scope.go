@@ -27,6 +27,10 @@ type ScopeInfo struct {
 
 	// TakesParam indicates if the scope accepts a parameter
 	TakesParam bool
+
+	// InstanceTokens indicates that tokens bound to specific instances may be used for this scope, even though it takes no parameter.
+	// It should only be set for scopes that do not permit any action by themselves.
+	InstanceTokens bool
 }
 
 type CheckError struct {
@@ -1,22 +1,26 @@
 //spellchecker:words admin
 package admin
 
-//spellchecker:words context http github wisski distillery internal component auth policy scopes server admin socket handling templating wdlog julienschmidt httprouter instances pkglib httpx
+//spellchecker:words context http github wisski distillery internal component audit auth exporter verifier policy scopes provision requests server admin socket handling templating wdlog julienschmidt httprouter instances pkglib httpx
 import (
 	"context"
 	"fmt"
 	"net/http"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/policy"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/scopes"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/provision/requests"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/admin/socket"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/handling"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
 	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
 	"github.com/julienschmidt/httprouter"
 
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/verifier"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances"
 	"go.tkw01536.de/pkglib/httpx"
 )
@@ -28,10 +32,14 @@ type Admin struct {
 		Fetchers []component.DistilleryFetcher
 
 		Instances *instances.Instances
+		Exporter  *exporter.Exporter
+		Verifier  *verifier.Verifier
 
 		Auth *auth.Auth
 
-		Policy *policy.Policy
+		Policy   *policy.Policy
+		Audit    *audit.Audit
+		Requests *requests.Requests
 
 		Templating *templating.Templating
 
@@ -74,6 +82,10 @@ var (
 	menuUsers      = component.MenuItem{Title: "Users", Path: "/admin/users/"}
 	menuUserCreate = component.MenuItem{Title: "Create User", Path: "/admin/users/create/"}
 
+	menuAudit = component.MenuItem{Title: "Audit Log", Path: "/admin/audit"}
+
+	menuRequests = component.MenuItem{Title: "Instance Requests", Path: "/admin/requests"}
+
 	menuProvision = component.MenuItem{Title: "Provision", Path: "/admin/instances/provision/"}
 
 	menuInstances   = component.MenuItem{Title: "Instances", Path: "/admin/instances/"}
@@ -87,6 +99,7 @@ var (
 	menuData        = component.DummyMenuItem()
 	menuTriplestore = component.DummyMenuItem()
 	menuDrupal      = component.DummyMenuItem()
+	menuUpdates     = component.DummyMenuItem()
 )
 
 func (admin *Admin) HandleRoute(ctx context.Context, route string) (handler http.Handler, err error) {
@@ -120,6 +133,19 @@ func (admin *Admin) HandleRoute(ctx context.Context, route string) (handler http
 		router.Handler(http.MethodPost, route+"users/create", create)
 	}
 
+	// add a handler for the audit log
+	{
+		audit := admin.audit(ctx)
+		router.Handler(http.MethodGet, route+"audit", audit)
+	}
+
+	// add a handler for instance requests
+	{
+		requests := admin.requests(ctx)
+		router.Handler(http.MethodGet, route+"requests", requests)
+		router.Handler(http.MethodPost, route+"requests/reject", admin.requestsRejectHandler(ctx))
+	}
+
 	// add all the admin actions
 	router.Handler(http.MethodPost, route+"users/delete", admin.usersDeleteHandler(ctx))
 	router.Handler(http.MethodPost, route+"users/disable", admin.usersDisableHandler(ctx))
@@ -169,6 +195,7 @@ func (admin *Admin) HandleRoute(ctx context.Context, route string) (handler http
 	{
 		triplestore := admin.instanceTS(ctx)
 		router.Handler(http.MethodGet, route+"instance/:slug/triplestore", triplestore)
+		router.Handler(http.MethodGet, route+"instance/:slug/triplestore/export", admin.instanceTSExport(ctx))
 	}
 
 	{
@@ -186,6 +213,11 @@ func (admin *Admin) HandleRoute(ctx context.Context, route string) (handler http
 		router.Handler(http.MethodGet, route+"instance/:slug/modules", modules)
 	}
 
+	{
+		updates := admin.instanceUpdates(ctx)
+		router.Handler(http.MethodGet, route+"instance/:slug/updates", updates)
+	}
+
 	// add a router for the login page
 	router.Handler(http.MethodPost, route+"login", admin.loginHandler(ctx))
 
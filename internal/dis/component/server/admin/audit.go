@@ -0,0 +1,65 @@
+//spellchecker:words admin
+package admin
+
+//spellchecker:words context http strconv time github wisski distillery internal component audit server assets templating models embed
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/assets"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+
+	_ "embed"
+)
+
+//go:embed "html/audit.html"
+var auditHTML []byte
+var auditTemplate = templating.Parse[auditContext](
+	"audit.html", auditHTML, nil,
+
+	templating.Title("Audit Log"),
+	templating.Assets(assets.AssetsAdmin),
+)
+
+type auditContext struct {
+	templating.RuntimeFlags
+
+	Filter  audit.Filter
+	Days    int // number of days to show entries of, 0 for all
+	Entries []models.AuditEntry
+}
+
+func (admin *Admin) audit(context.Context) http.Handler {
+	tpl := auditTemplate.Prepare(
+		admin.dependencies.Templating,
+		templating.Crumbs(
+			menuAdmin,
+			menuAudit,
+		),
+	)
+
+	return tpl.HTMLHandler(admin.dependencies.Handling, func(r *http.Request) (ac auditContext, err error) {
+		query := r.URL.Query()
+
+		ac.Filter.User = query.Get("user")
+		ac.Filter.Action = query.Get("action")
+		ac.Filter.Slug = query.Get("slug")
+		ac.Filter.Failed = query.Get("failed") != ""
+
+		if days, err := strconv.Atoi(query.Get("days")); err == nil && days > 0 {
+			ac.Days = days
+			ac.Filter.Since = time.Now().AddDate(0, 0, -days)
+		}
+
+		ac.Entries, err = admin.dependencies.Audit.Entries(r.Context(), ac.Filter)
+		if err != nil {
+			return ac, fmt.Errorf("failed to get audit log: %w", err)
+		}
+		return ac, nil
+	})
+}
@@ -1,7 +1,7 @@
 //spellchecker:words admin
 package admin
 
-//spellchecker:words context http sync time embed github wisski distillery internal component server assets templating status wdlog golang errgroup
+//spellchecker:words context http sync time embed github wisski distillery internal component server assets templating models status wdlog golang errgroup
 import (
 	"context"
 	"fmt"
@@ -14,6 +14,7 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/assets"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
 	"github.com/FAU-CDI/wisski-distillery/internal/status"
 	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
 	"golang.org/x/sync/errgroup"
@@ -119,6 +120,9 @@ type indexContext struct {
 
 	status.Distillery
 	Instances []status.WissKI
+
+	Retention models.RetentionPolicy // retention policy for backups
+	Problems  []models.Export        // exports that failed verification
 }
 
 func (admin *Admin) index(context.Context) http.Handler {
@@ -127,11 +131,21 @@ func (admin *Admin) index(context.Context) http.Handler {
 		templating.Actions(
 			menuUsers,
 			menuInstances,
+			menuRequests,
+			menuAudit,
 		),
 	)
 
 	return tpl.HTMLHandler(admin.dependencies.Handling, func(r *http.Request) (idx indexContext, err error) {
 		idx.Distillery, idx.Instances, err = admin.Status(r.Context(), false)
+		if err != nil {
+			return
+		}
+		idx.Retention, err = admin.dependencies.Exporter.RetentionPolicy(r.Context(), "")
+		if err != nil {
+			return
+		}
+		idx.Problems, err = admin.dependencies.Verifier.Problems(r.Context())
 		return
 	})
 }
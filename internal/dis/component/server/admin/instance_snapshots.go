@@ -33,6 +33,10 @@ type instanceSnapshotsContext struct {
 
 	Instance  *wisski.WissKI
 	Snapshots []models.Export
+	Retention models.RetentionPolicy
+
+	Schedule      models.SnapshotSchedule
+	ScheduleState models.SnapshotScheduleState
 }
 
 func (admin *Admin) instanceSnapshots(context.Context) http.Handler {
@@ -60,6 +64,20 @@ func (admin *Admin) instanceSnapshots(context.Context) http.Handler {
 			return ctx, nil, fmt.Errorf("failed to get snapshots: %w", err)
 		}
 
+		ctx.Retention, err = admin.dependencies.Exporter.RetentionPolicy(r.Context(), ctx.Instance.Slug)
+		if err != nil {
+			return ctx, nil, fmt.Errorf("failed to get retention policy: %w", err)
+		}
+
+		ctx.Schedule, err = ctx.Instance.Schedule().Get(r.Context())
+		if err != nil {
+			return ctx, nil, fmt.Errorf("failed to get snapshot schedule: %w", err)
+		}
+		ctx.ScheduleState, err = ctx.Instance.Schedule().State(r.Context())
+		if err != nil {
+			return ctx, nil, fmt.Errorf("failed to get snapshot schedule state: %w", err)
+		}
+
 		escapedSlug := url.PathEscape(ctx.Instance.Slug)
 		presentFunc, presentErr := admin.preparePanelInstancePage(r, ctx.Instance, "snapshots")
 		if presentErr != nil {
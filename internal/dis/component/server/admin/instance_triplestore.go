@@ -1,17 +1,21 @@
 //spellchecker:words admin
 package admin
 
-//spellchecker:words context embed html template http github wisski distillery internal component server assets templating ingredient extras pkglib httpx julienschmidt httprouter
+//spellchecker:words context embed html template http strconv github wisski distillery internal component server assets templating triplestore client wdlog ingredient extras pkglib httpx julienschmidt httprouter
 import (
 	"context"
 	_ "embed"
+	"fmt"
 	"html/template"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/assets"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/triplestore/client"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/php/extras"
 	"go.tkw01536.de/pkglib/httpx"
@@ -32,6 +36,10 @@ type instanceTriplestoreContext struct {
 
 	Instance *wisski.WissKI
 	Adapters []extras.DistilleryAdapter
+
+	Formats     []client.Format
+	Graphs      []string
+	GraphsError error
 }
 
 func (admin *Admin) instanceTS(context.Context) http.Handler {
@@ -55,6 +63,10 @@ func (admin *Admin) instanceTS(context.Context) http.Handler {
 		}
 		ctx.Adapters = ctx.Instance.Adapters().Adapters()
 
+		// the page remains usable when the graphs can not be listed
+		ctx.Formats = client.Formats
+		ctx.Graphs, ctx.GraphsError = ctx.Instance.BoundTriplestore().Graphs(r.Context())
+
 		escapedSlug := url.PathEscape(ctx.Instance.Slug)
 		presentFunc, presentErr := admin.preparePanelInstancePage(r, ctx.Instance, "triplestore")
 		if presentErr != nil {
@@ -68,3 +80,70 @@ func (admin *Admin) instanceTS(context.Context) http.Handler {
 		}, nil
 	})
 }
+
+// instanceTSExport returns a handler that downloads the content of the triplestore of an instance.
+// The format and named graphs to export are taken from the "format" and "graph" query parameters.
+func (admin *Admin) instanceTSExport(ctx context.Context) http.Handler {
+	logger := wdlog.Of(ctx)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slug := httprouter.ParamsFromContext(r.Context()).ByName("slug")
+
+		instance, err := admin.dependencies.Instances.WissKI(r.Context(), slug)
+		if err != nil {
+			httpx.TextInterceptor.Intercept(w, r, httpx.ErrNotFound)
+			return
+		}
+
+		query := r.URL.Query()
+		format, err := client.FormatByName(query.Get("format"))
+		if err != nil {
+			httpx.TextInterceptor.Intercept(w, r, httpx.ErrBadRequest)
+			return
+		}
+
+		download := &downloadWriter{
+			ResponseWriter: w,
+			ContentType:    format.ContentType,
+			Filename:       instance.Slug + format.Extension,
+		}
+		if err := instance.BoundTriplestore().Export(r.Context(), download, client.ExportOptions{
+			Format: format,
+			Graphs: query["graph"],
+		}); err != nil {
+			logger.Error(
+				"failed to export triplestore",
+				"error", err,
+				"slug", instance.Slug,
+			)
+
+			// once the download has started, we can not report the error
+			if !download.started {
+				httpx.TextInterceptor.Intercept(w, r, err)
+			}
+			return
+		}
+	})
+}
+
+// downloadWriter sets headers for a file download on the first write to the underlying ResponseWriter.
+type downloadWriter struct {
+	http.ResponseWriter
+
+	ContentType string
+	Filename    string
+
+	started bool
+}
+
+func (dw *downloadWriter) Write(data []byte) (int, error) {
+	if !dw.started {
+		dw.started = true
+		dw.Header().Set("Content-Type", dw.ContentType)
+		dw.Header().Set("Content-Disposition", "attachment; filename="+strconv.Quote(dw.Filename))
+	}
+	n, err := dw.ResponseWriter.Write(data)
+	if err != nil {
+		return n, fmt.Errorf("failed to write response: %w", err)
+	}
+	return n, nil
+}
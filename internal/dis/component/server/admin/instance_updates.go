@@ -0,0 +1,81 @@
+//spellchecker:words admin
+package admin
+
+//spellchecker:words context embed html template http github wisski distillery internal component server assets templating models pkglib httpx julienschmidt httprouter
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/assets"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
+	"go.tkw01536.de/pkglib/httpx"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+//go:embed "html/instance_updates.html"
+var instanceUpdatesHTML []byte
+var instanceUpdatesTemplate = templating.Parse[instanceUpdatesContext](
+	"instance_updates.html", instanceUpdatesHTML, nil,
+
+	templating.Assets(assets.AssetsAdmin),
+)
+
+type instanceUpdatesContext struct {
+	templating.RuntimeFlags
+
+	Instance *wisski.WissKI
+	Pending  models.PendingUpdates
+	Guarded  models.UpdateOutcome
+}
+
+func (admin *Admin) instanceUpdates(context.Context) http.Handler {
+	tpl := instanceUpdatesTemplate.Prepare(
+		admin.dependencies.Templating,
+		templating.Crumbs(
+			menuAdmin,
+			menuInstances,
+			menuInstance,
+			menuUpdates,
+		),
+	)
+
+	return tpl.HTMLHandlerWithFlags(admin.dependencies.Handling, func(r *http.Request) (ctx instanceUpdatesContext, funcs []templating.FlagFunc, err error) {
+		slug := httprouter.ParamsFromContext(r.Context()).ByName("slug")
+
+		// setup the context with just the instance
+		ctx.Instance, err = admin.dependencies.Instances.WissKI(r.Context(), slug)
+		if err != nil {
+			return ctx, nil, httpx.ErrNotFound
+		}
+
+		// get the updates found by the last check
+		ctx.Pending, err = ctx.Instance.Composer().PendingUpdates(r.Context())
+		if err != nil {
+			return ctx, nil, fmt.Errorf("%w: failed to get pending updates: %w", httpx.ErrInternalServerError, err)
+		}
+		ctx.Guarded, err = ctx.Instance.Composer().GuardedUpdate(r.Context())
+		if err != nil {
+			return ctx, nil, fmt.Errorf("%w: failed to get guarded update: %w", httpx.ErrInternalServerError, err)
+		}
+
+		escapedSlug := url.PathEscape(ctx.Instance.Slug)
+		presentFunc, presentErr := admin.preparePanelInstancePage(r, ctx.Instance, "updates")
+		if presentErr != nil {
+			return ctx, nil, presentErr
+		}
+		return ctx, []templating.FlagFunc{
+			templating.ReplaceCrumb(menuInstance, component.MenuItem{Title: "Instance", Path: template.URL("/admin/instance/" + escapedSlug)}),            // #nosec G203 -- escaped and safe
+			templating.ReplaceCrumb(menuUpdates, component.MenuItem{Title: "Updates", Path: template.URL("/admin/instance/" + escapedSlug + "/updates")}), // #nosec G203 -- escaped and safe
+			templating.Title(ctx.Instance.Slug + " - Updates"),
+			presentFunc,
+		}, nil
+	})
+}
@@ -1,7 +1,7 @@
 //spellchecker:words admin
 package admin
 
-//spellchecker:words context embed errors html template http github wisski distillery internal component instances server assets templating models status pkglib httpx form field maps slices julienschmidt httprouter
+//spellchecker:words context embed errors html template http github wisski distillery internal component instances server assets templating models status pkglib httpx maps slices julienschmidt httprouter
 import (
 	"context"
 	_ "embed"
@@ -19,7 +19,6 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/status"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
 	"go.tkw01536.de/pkglib/httpx"
-	"go.tkw01536.de/pkglib/httpx/form/field"
 
 	"maps"
 	"slices"
@@ -47,6 +46,8 @@ type instanceUsersContext struct {
 
 	Usernames []string       // unuused distillery usernames
 	Grants    []models.Grant // grants that exist for the user
+
+	Roles []models.Role // roles that can be granted
 }
 
 func (admin *Admin) instanceUsers(context.Context) http.Handler {
@@ -96,7 +97,7 @@ func (admin *Admin) postInstanceUsers(r *http.Request) (gc instanceUsersContext,
 		actionIsDelete = r.PostFormValue("action") == "delete"
 		distilleryUser = r.PostFormValue("distillery-user")
 		drupalUser     = r.PostFormValue("drupal-user")
-		adminRole      = r.PostFormValue("admin") == field.CheckboxChecked
+		role           = r.PostFormValue("role")
 	)
 
 	// set the common fields
@@ -117,8 +118,8 @@ func (admin *Admin) postInstanceUsers(r *http.Request) (gc instanceUsersContext,
 			User: distilleryUser,
 			Slug: slug,
 
-			DrupalUsername:  drupalUser,
-			DrupalAdminRole: adminRole,
+			DrupalUsername: drupalUser,
+			Role:           models.Role(role),
 		})
 		if err != nil {
 			gc.Error = fmt.Sprintf("Unable to update grant for user %s: %s", distilleryUser, err.Error())
@@ -142,6 +143,7 @@ func (gc *instanceUsersContext) use(r *http.Request, slug string, admin *Admin)
 		return nil, fmt.Errorf("failed to get WissKI: %w", err)
 	}
 	gc.Instance = gc.instance.Instance
+	gc.Roles = models.Roles
 
 	// replace the functions
 	escapedSlug := url.PathEscape(slug)
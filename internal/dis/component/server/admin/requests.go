@@ -0,0 +1,98 @@
+//spellchecker:words admin
+package admin
+
+//spellchecker:words context http url strconv github wisski distillery internal component server assets templating models wdlog pkglib httpx embed
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/assets"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+	"go.tkw01536.de/pkglib/httpx"
+
+	_ "embed"
+)
+
+//go:embed "html/requests.html"
+var requestsHTML []byte
+var requestsTemplate = templating.Parse[requestsContext](
+	"requests.html", requestsHTML, nil,
+
+	templating.Title("Instance Requests"),
+	templating.Assets(assets.AssetsAdmin),
+)
+
+type requestsContext struct {
+	templating.RuntimeFlags
+
+	Error string
+
+	Pending []models.InstanceRequest
+	Decided []models.InstanceRequest // most recently decided requests
+}
+
+// decidedRequestsLimit is the number of decided requests shown.
+const decidedRequestsLimit = 50
+
+func (admin *Admin) requests(context.Context) http.Handler {
+	tpl := requestsTemplate.Prepare(
+		admin.dependencies.Templating,
+		templating.Crumbs(
+			menuAdmin,
+			menuRequests,
+		),
+	)
+
+	return tpl.HTMLHandler(admin.dependencies.Handling, func(r *http.Request) (rc requestsContext, err error) {
+		rc.Error = r.URL.Query().Get("error")
+
+		rc.Pending, err = admin.dependencies.Requests.Pending(r.Context())
+		if err != nil {
+			return rc, fmt.Errorf("failed to get pending requests: %w", err)
+		}
+		rc.Decided, err = admin.dependencies.Requests.Decided(r.Context(), decidedRequestsLimit)
+		if err != nil {
+			return rc, fmt.Errorf("failed to get decided requests: %w", err)
+		}
+		return rc, nil
+	})
+}
+
+func (admin *Admin) requestsRejectHandler(ctx context.Context) http.Handler {
+	logger := wdlog.Of(ctx)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyFormBytes)
+		if err := r.ParseForm(); err != nil {
+			logger.Error(
+				"failed to parse form",
+				"error", err,
+				"action", "reject request",
+			)
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		pk, err := strconv.ParseUint(r.PostFormValue("id"), 10, 0)
+		if err != nil {
+			httpx.HTMLInterceptor.Fallback.ServeHTTP(w, r)
+			return
+		}
+
+		if err := admin.dependencies.Requests.Reject(r.Context(), uint(pk), r.PostFormValue("reason")); err != nil {
+			logger.Error(
+				"failed to reject request",
+				"error", err,
+				"action", "reject request",
+			)
+			http.Redirect(w, r, string(menuRequests.Path)+"?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+
+		http.Redirect(w, r, string(menuRequests.Path), http.StatusSeeOther)
+	})
+}
@@ -1,7 +1,7 @@
 //spellchecker:words socket
 package socket
 
-//spellchecker:words context errors http github process over websocket proto wisski distillery internal component server admin socket actions wdlog pkglib errorsx
+//spellchecker:words context errors http github process over websocket proto wisski distillery internal component audit server admin socket actions wdlog pkglib errorsx
 import (
 	"context"
 	"errors"
@@ -10,6 +10,7 @@ import (
 	"net/http"
 
 	"github.com/FAU-CDI/process_over_websocket/proto"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/admin/socket/actions"
 	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
 	"go.tkw01536.de/pkglib/errorsx"
@@ -76,8 +77,28 @@ func (sockets *Sockets) Actions(ctx context.Context) proto.Handler {
 			return nil, err
 		}
 
-		return proto.ProcessFunc(func(ictx context.Context, input io.Reader, output io.Writer, args ...string) (res any, err error) {
-			return action.Run(wdlog.Set(ctx, wdlog.Of(ictx)), input, output, args...)
+		// run the action on behalf of the user making the request
+		actor := audit.ActorOf(r.Context())
+
+		// identify the action in log messages, alongside the request that started it
+		processLogger := wdlog.Of(r.Context()).With("action", name, "process", wdlog.NewID())
+		return proto.ProcessFunc(func(_ context.Context, input io.Reader, output io.Writer, args ...string) (res any, err error) {
+			actx := audit.WithActor(wdlog.Set(ctx, processLogger), actor)
+
+			var slug string
+			params := args
+			if action.Instance && len(args) > 0 {
+				slug, params = args[0], args[1:]
+			}
+			var auditParams any = params
+			if action.AuditParams != nil {
+				auditParams = action.AuditParams(params)
+			}
+			defer func() {
+				sockets.dependencies.Audit.Record(actx, "socket."+name, slug, auditParams, err)
+			}()
+
+			return action.Run(actx, input, output, args...)
 		}), nil
 	})
 }
@@ -85,6 +106,7 @@ func (sockets *Sockets) Actions(ctx context.Context) proto.Handler {
 func (sockets *Sockets) regularAction(a actions.WebsocketAction) (actions.Action, *actionable) {
 	meta := a.Action()
 	return meta, &actionable{
+		AuditParams: auditParams(a),
 		Validate: func(r *http.Request, args ...string) error {
 			if err := sockets.dependencies.Auth.CheckScope(meta.ScopeParam, meta.Scope, r); err != nil {
 				return errorsx.Combine(err, proto.ErrHandlerAuthorizationDenied)
@@ -104,14 +126,23 @@ func (sockets *Sockets) regularAction(a actions.WebsocketAction) (actions.Action
 func (sockets *Sockets) instanceAction(a actions.WebsocketInstanceAction) (actions.InstanceAction, *actionable) {
 	meta := a.Action()
 	return meta, &actionable{
+		Instance:    true,
+		AuditParams: auditParams(a),
 		Validate: func(r *http.Request, args ...string) error {
-			if err := sockets.dependencies.Auth.CheckScope(meta.ScopeParam, meta.Scope, r); err != nil {
-				return errors.Join(err, proto.ErrHandlerAuthorizationDenied)
-			}
-
 			if len(args) != meta.NumParams+1 {
 				return proto.ErrHandlerInvalidArgs
 			}
+
+			param := meta.ScopeParam
+			if meta.SlugScope {
+				param = args[0]
+			}
+			if err := sockets.dependencies.Auth.CheckScope(param, meta.Scope, r); err != nil {
+				return errors.Join(err, proto.ErrHandlerAuthorizationDenied)
+			}
+			if err := sockets.dependencies.Auth.CheckInstance(args[0], r); err != nil {
+				return errors.Join(err, proto.ErrHandlerAuthorizationDenied)
+			}
 			return nil
 		},
 		Run: func(ctx context.Context, input io.Reader, output io.Writer, args ...string) (res any, err error) {
@@ -126,6 +157,17 @@ func (sockets *Sockets) instanceAction(a actions.WebsocketInstanceAction) (actio
 }
 
 type actionable struct {
-	Validate func(*http.Request, ...string) error
-	Run      func(ctx context.Context, input io.Reader, output io.Writer, args ...string) (any, error)
+	Instance    bool               // the first argument is the slug of an instance
+	AuditParams func([]string) any // returns the parameters to record in the audit log, nil to record them as is
+	Validate    func(*http.Request, ...string) error
+	Run         func(ctx context.Context, input io.Reader, output io.Writer, args ...string) (any, error)
+}
+
+// auditParams returns the AuditParams function of a, if any.
+func auditParams(a any) func([]string) any {
+	audited, ok := a.(actions.AuditedAction)
+	if !ok {
+		return nil
+	}
+	return audited.AuditParams
 }
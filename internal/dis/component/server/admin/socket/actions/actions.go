@@ -27,6 +27,14 @@ type WebsocketInstanceAction interface {
 	Act(ctx context.Context, instance *wisski.WissKI, in io.Reader, out io.Writer, params ...string) (any, error)
 }
 
+// AuditedAction is implemented by actions with parameters that must not be recorded in the audit log as is,
+// for instance because they contain passwords.
+type AuditedAction interface {
+	// AuditParams returns the parameters to record in the audit log instead of params.
+	// For instance actions, params does not include the slug.
+	AuditParams(params []string) any
+}
+
 // Action represents information about an action.
 type Action struct {
 	Name string
@@ -38,4 +46,8 @@ type Action struct {
 
 type InstanceAction struct {
 	Action
+
+	// SlugScope indicates that the slug of the instance should be used as the scope parameter.
+	// When set, the ScopeParam field is ignored.
+	SlugScope bool
 }
@@ -0,0 +1,50 @@
+//spellchecker:words actions
+package actions
+
+//spellchecker:words context strconv github wisski distillery internal component auth scopes provision requests
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/scopes"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/provision/requests"
+)
+
+type ApproveRequest struct {
+	component.Base
+	dependencies struct {
+		Requests *requests.Requests
+	}
+}
+
+var (
+	_ WebsocketAction = (*ApproveRequest)(nil)
+)
+
+func (*ApproveRequest) Action() Action {
+	return Action{
+		Name:      "approve_request",
+		Scope:     scopes.ScopeUserAdmin,
+		NumParams: 1,
+	}
+}
+
+func (ar *ApproveRequest) Act(ctx context.Context, in io.Reader, out io.Writer, params ...string) (any, error) {
+	pk, err := strconv.ParseUint(params[0], 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request id: %w", err)
+	}
+
+	instance, err := ar.dependencies.Requests.Approve(ctx, out, uint(pk))
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve request: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(out, "URL:      %s\n", instance.URL().String()); err != nil {
+		return nil, fmt.Errorf("failed to report progress: %w", err)
+	}
+	return nil, nil
+}
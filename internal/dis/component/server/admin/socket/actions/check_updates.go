@@ -0,0 +1,40 @@
+//spellchecker:words actions
+package actions
+
+//spellchecker:words context github wisski distillery internal component auth scopes
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/scopes"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
+)
+
+// CheckUpdates determines the pending updates of an instance, without applying them.
+type CheckUpdates struct {
+	component.Base
+}
+
+var (
+	_ WebsocketInstanceAction = (*CheckUpdates)(nil)
+)
+
+func (*CheckUpdates) Action() InstanceAction {
+	return InstanceAction{
+		Action: Action{
+			Name:      "check_updates",
+			Scope:     scopes.ScopeInstanceRebuild,
+			NumParams: 0,
+		},
+		SlugScope: true,
+	}
+}
+
+func (*CheckUpdates) Act(ctx context.Context, instance *wisski.WissKI, in io.Reader, out io.Writer, params ...string) (any, error) {
+	if _, err := instance.Composer().CheckUpdates(ctx, out); err != nil {
+		return nil, fmt.Errorf("failed to check updates: %w", err)
+	}
+	return nil, nil
+}
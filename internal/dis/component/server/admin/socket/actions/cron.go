@@ -24,9 +24,10 @@ func (*Cron) Action() InstanceAction {
 	return InstanceAction{
 		Action: Action{
 			Name:      "cron",
-			Scope:     scopes.ScopeUserAdmin,
+			Scope:     scopes.ScopeInstanceControl,
 			NumParams: 0,
 		},
+		SlugScope: true,
 	}
 }
 
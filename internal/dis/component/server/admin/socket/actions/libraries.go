@@ -27,9 +27,10 @@ func (*InstallColorboxJS) Action() InstanceAction {
 	return InstanceAction{
 		Action: Action{
 			Name:      "install-colorbox-js",
-			Scope:     scopes.ScopeUserAdmin,
+			Scope:     scopes.ScopeInstanceRebuild,
 			NumParams: 0,
 		},
+		SlugScope: true,
 	}
 }
 
@@ -52,9 +53,10 @@ func (*InstallDompurifyJS) Action() InstanceAction {
 	return InstanceAction{
 		Action: Action{
 			Name:      "install-dompurify-js",
-			Scope:     scopes.ScopeUserAdmin,
+			Scope:     scopes.ScopeInstanceRebuild,
 			NumParams: 0,
 		},
+		SlugScope: true,
 	}
 }
 
@@ -26,9 +26,10 @@ func (*InstanceLog) Action() InstanceAction {
 	return InstanceAction{
 		Action: Action{
 			Name:      "instance_log",
-			Scope:     scopes.ScopeUserAdmin,
+			Scope:     scopes.ScopeInstanceControl,
 			NumParams: 0,
 		},
+		SlugScope: true,
 	}
 }
 
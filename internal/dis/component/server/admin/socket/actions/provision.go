@@ -22,6 +22,7 @@ type Provision struct {
 
 var (
 	_ WebsocketAction = (*Provision)(nil)
+	_ AuditedAction   = (*Provision)(nil)
 )
 
 func (*Provision) Action() Action {
@@ -32,6 +33,17 @@ func (*Provision) Action() Action {
 	}
 }
 
+// AuditParams records the provision flags without the basic auth passwords.
+// Parameters that cannot be decoded are not recorded, as they might still contain a password.
+func (*Provision) AuditParams(params []string) any {
+	var flags provision.Flags
+	if err := json.Unmarshal([]byte(params[0]), &flags); err != nil {
+		return nil
+	}
+	flags.System = flags.System.Redacted()
+	return []any{flags}
+}
+
 type ProvisionResult struct {
 	URL            string
 	DrupalUsername string
@@ -30,9 +30,35 @@ func (*Prune) Action() Action {
 }
 
 func (pa *Prune) Act(ctx context.Context, in io.Reader, out io.Writer, params ...string) (any, error) {
-	err := pa.dependencies.exporter.PruneExports(ctx, out)
+	err := pa.dependencies.exporter.PruneExports(ctx, out, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prune exports: %w", err)
 	}
 	return nil, nil
 }
+
+// PruneDryRun lists the exports that would be pruned, without removing them.
+type PruneDryRun struct {
+	component.Base
+	dependencies struct {
+		exporter *exporter.Exporter
+	}
+}
+
+var _ WebsocketAction = (*PruneDryRun)(nil)
+
+func (*PruneDryRun) Action() Action {
+	return Action{
+		Name:      "prune_dry_run",
+		Scope:     scopes.ScopeUserAdmin,
+		NumParams: 0,
+	}
+}
+
+func (pd *PruneDryRun) Act(ctx context.Context, in io.Reader, out io.Writer, params ...string) (any, error) {
+	err := pd.dependencies.exporter.PruneExports(ctx, out, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exports to prune: %w", err)
+	}
+	return nil, nil
+}
@@ -28,9 +28,10 @@ func (*Purge) Action() InstanceAction {
 	return InstanceAction{
 		Action: Action{
 			Name:      "purge",
-			Scope:     scopes.ScopeUserAdmin,
+			Scope:     scopes.ScopeInstancePurge,
 			NumParams: 0,
 		},
+		SlugScope: true,
 	}
 }
 
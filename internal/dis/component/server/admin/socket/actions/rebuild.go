@@ -1,7 +1,7 @@
 //spellchecker:words actions
 package actions
 
-//spellchecker:words context encoding json github wisski distillery internal component auth scopes models
+//spellchecker:words context encoding json github wisski distillery internal component auth scopes instances models
 import (
 	"context"
 	"encoding/json"
@@ -10,37 +10,89 @@ import (
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/scopes"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances"
 	"github.com/FAU-CDI/wisski-distillery/internal/models"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
 )
 
+// Rebuild rebuilds an instance with new system properties.
+// Changing the system properties is reserved for distillery admins.
 type Rebuild struct {
 	component.Base
+	dependencies struct {
+		Instances *instances.Instances
+	}
 }
 
 var (
 	_ WebsocketInstanceAction = (*Rebuild)(nil)
+	_ AuditedAction           = (*Rebuild)(nil)
 )
 
 func (*Rebuild) Action() InstanceAction {
 	return InstanceAction{
 		Action: Action{
 			Name:      "rebuild",
-			Scope:     scopes.ScopeUserAdmin,
+			Scope:     scopes.ScopeInstanceSystem,
 			NumParams: 1,
 		},
+		SlugScope: true,
 	}
 }
 
 func (r *Rebuild) Act(ctx context.Context, instance *wisski.WissKI, in io.Reader, out io.Writer, params ...string) (any, error) {
-	// read the flags of the instance to be rebuilt
-	var system models.System
+	// read the flags of the instance to be rebuilt.
+	// start from the current system, so that properties not sent by the client are kept.
+	system := instance.System
 	if err := json.Unmarshal([]byte(params[0]), &system); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal system properties: %w", err)
 	}
 
+	var err error
+	system.Aliases, err = r.dependencies.Instances.ValidateAliases(ctx, instance.Slug, system.Aliases)
+	if err != nil {
+		return nil, fmt.Errorf("invalid system properties: %w", err)
+	}
+
 	if err := instance.SystemManager().Apply(ctx, out, system); err != nil {
 		return nil, fmt.Errorf("failed to apply system properties: %w", err)
 	}
 	return nil, nil
 }
+
+// AuditParams records the system properties without the basic auth passwords.
+// Parameters that cannot be decoded are not recorded, as they might still contain a password.
+func (*Rebuild) AuditParams(params []string) any {
+	var system models.System
+	if err := json.Unmarshal([]byte(params[0]), &system); err != nil {
+		return nil
+	}
+	return []any{system.Redacted()}
+}
+
+// RebuildCurrent rebuilds an instance with its current system properties.
+type RebuildCurrent struct {
+	component.Base
+}
+
+var (
+	_ WebsocketInstanceAction = (*RebuildCurrent)(nil)
+)
+
+func (*RebuildCurrent) Action() InstanceAction {
+	return InstanceAction{
+		Action: Action{
+			Name:      "rebuild_current",
+			Scope:     scopes.ScopeInstanceRebuild,
+			NumParams: 0,
+		},
+		SlugScope: true,
+	}
+}
+
+func (*RebuildCurrent) Act(ctx context.Context, instance *wisski.WissKI, in io.Reader, out io.Writer, params ...string) (any, error) {
+	if err := instance.SystemManager().Apply(ctx, out, instance.System); err != nil {
+		return nil, fmt.Errorf("failed to apply system properties: %w", err)
+	}
+	return nil, nil
+}
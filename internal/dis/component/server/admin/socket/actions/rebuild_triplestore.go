@@ -24,9 +24,10 @@ func (wsa *RebuildTriplestore) Action() InstanceAction {
 	return InstanceAction{
 		Action: Action{
 			Name:      "rebuild_triplestore",
-			Scope:     scopes.ScopeUserAdmin,
+			Scope:     scopes.ScopeInstanceRebuild,
 			NumParams: 0,
 		},
+		SlugScope: true,
 	}
 }
 
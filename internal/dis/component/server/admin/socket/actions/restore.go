@@ -0,0 +1,88 @@
+//spellchecker:words actions
+package actions
+
+//spellchecker:words context errors slices strconv github wisski distillery internal component auth scopes exporter instances restorer models pkglib errorsx
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/scopes"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances/restorer"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
+	"go.tkw01536.de/pkglib/errorsx"
+)
+
+// Restore restores an instance from one of its snapshots.
+//
+// It takes two parameters, the id of the snapshot in the export log, and a confirmation.
+// The confirmation must be the slug of the instance, as typed by the user.
+type Restore struct {
+	component.Base
+	dependencies struct {
+		Exporter *exporter.Exporter
+		Restorer *restorer.Restorer
+	}
+}
+
+var (
+	_ WebsocketInstanceAction = (*Restore)(nil)
+)
+
+func (*Restore) Action() InstanceAction {
+	return InstanceAction{
+		Action: Action{
+			Name:      "restore",
+			Scope:     scopes.ScopeInstanceRestore,
+			NumParams: 2,
+		},
+		SlugScope: true,
+	}
+}
+
+var (
+	errRestoreNotConfirmed  = errors.New("restore was not confirmed: confirmation must be the slug of the instance")
+	errRestoreInvalidID     = errors.New("invalid snapshot id")
+	errRestoreUnknownExport = errors.New("snapshot does not belong to instance")
+	errRestoreStaging       = errors.New("failed to stage snapshot")
+)
+
+func (r *Restore) Act(ctx context.Context, instance *wisski.WissKI, in io.Reader, out io.Writer, params ...string) (_ any, e error) {
+	if params[1] != instance.Slug {
+		return nil, errRestoreNotConfirmed
+	}
+
+	pk, err := strconv.ParseUint(params[0], 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errRestoreInvalidID, err)
+	}
+
+	// only allow snapshots of this instance
+	snapshots, err := instance.Snapshots(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	index := slices.IndexFunc(snapshots, func(export models.Export) bool { return export.Pk == uint(pk) })
+	if index < 0 {
+		return nil, errRestoreUnknownExport
+	}
+
+	staged, err := r.dependencies.Exporter.StageExport(ctx, out, snapshots[index])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errRestoreStaging, err)
+	}
+	defer func() {
+		e = errorsx.Combine(e, staged.Close())
+	}()
+
+	if err := r.dependencies.Restorer.Restore(ctx, out, instance, staged); err != nil {
+		return nil, fmt.Errorf("failed to restore instance: %w", err)
+	}
+	return nil, nil
+}
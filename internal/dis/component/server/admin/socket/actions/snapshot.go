@@ -28,9 +28,10 @@ func (*Snapshot) Action() InstanceAction {
 	return InstanceAction{
 		Action: Action{
 			Name:      "snapshot",
-			Scope:     scopes.ScopeUserAdmin,
+			Scope:     scopes.ScopeInstanceSnapshot,
 			NumParams: 0,
 		},
+		SlugScope: true,
 	}
 }
 
@@ -0,0 +1,51 @@
+//spellchecker:words actions
+package actions
+
+//spellchecker:words context github wisski distillery internal component auth scopes exporter
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/scopes"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
+)
+
+// SnapshotIncremental makes an incremental snapshot of an instance.
+type SnapshotIncremental struct {
+	component.Base
+	dependencies struct {
+		Exporter *exporter.Exporter
+	}
+}
+
+var (
+	_ WebsocketInstanceAction = (*SnapshotIncremental)(nil)
+)
+
+func (*SnapshotIncremental) Action() InstanceAction {
+	return InstanceAction{
+		Action: Action{
+			Name:      "snapshot_incremental",
+			Scope:     scopes.ScopeInstanceSnapshot,
+			NumParams: 0,
+		},
+		SlugScope: true,
+	}
+}
+
+func (s *SnapshotIncremental) Act(ctx context.Context, instance *wisski.WissKI, in io.Reader, out io.Writer, params ...string) (any, error) {
+	if err := s.dependencies.Exporter.MakeExport(
+		ctx,
+		out,
+		exporter.ExportTask{
+			Instance:    instance,
+			Incremental: true,
+		},
+	); err != nil {
+		return nil, fmt.Errorf("failed to make export: %w", err)
+	}
+	return nil, nil
+}
@@ -1,7 +1,7 @@
 //spellchecker:words actions
 package actions
 
-//spellchecker:words context github wisski distillery internal component auth scopes pkglib errorsx
+//spellchecker:words context github wisski distillery internal component auth scopes
 import (
 	"context"
 	"fmt"
@@ -10,7 +10,6 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/scopes"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski"
-	"go.tkw01536.de/pkglib/errorsx"
 )
 
 type Stop struct {
@@ -25,20 +24,15 @@ func (*Stop) Action() InstanceAction {
 	return InstanceAction{
 		Action: Action{
 			Name:      "stop",
-			Scope:     scopes.ScopeUserAdmin,
+			Scope:     scopes.ScopeInstanceControl,
 			NumParams: 0,
 		},
+		SlugScope: true,
 	}
 }
 
-func (*Stop) Act(ctx context.Context, instance *wisski.WissKI, in io.Reader, out io.Writer, params ...string) (a any, e error) {
-	stack, err := instance.Barrel().OpenStack()
-	if err != nil {
-		return nil, fmt.Errorf("failed to open stack: %w", err)
-	}
-	defer errorsx.Close(stack, &e, "stack")
-
-	if err := stack.Down(ctx, out); err != nil {
+func (*Stop) Act(ctx context.Context, instance *wisski.WissKI, in io.Reader, out io.Writer, params ...string) (any, error) {
+	if err := instance.Barrel().Stop(ctx, out); err != nil {
 		return nil, fmt.Errorf("failed to shutdown barrel: %w", err)
 	}
 	return nil, nil
@@ -24,9 +24,10 @@ func (*Update) Action() InstanceAction {
 	return InstanceAction{
 		Action: Action{
 			Name:      "update",
-			Scope:     scopes.ScopeUserAdmin,
+			Scope:     scopes.ScopeInstanceRebuild,
 			NumParams: 0,
 		},
+		SlugScope: true,
 	}
 }
 
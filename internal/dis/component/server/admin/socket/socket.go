@@ -1,7 +1,7 @@
 //spellchecker:words socket
 package socket
 
-//spellchecker:words context http strings github process over websocket proto wisski distillery internal component auth scopes exporter instances purger provision server admin socket actions models pkglib lazy
+//spellchecker:words context http strings github process over websocket proto wisski distillery internal component audit auth scopes exporter instances purger provision server admin socket actions models pkglib lazy
 import (
 	"context"
 	"net/http"
@@ -10,6 +10,7 @@ import (
 	"github.com/FAU-CDI/process_over_websocket"
 	"github.com/FAU-CDI/process_over_websocket/proto"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/scopes"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
@@ -36,6 +37,7 @@ type Sockets struct {
 		Exporter  *exporter.Exporter
 		Purger    *purger.Purger
 		Auth      *auth.Auth
+		Audit     *audit.Audit
 	}
 }
 
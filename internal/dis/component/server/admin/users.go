@@ -281,7 +281,7 @@ func (admin *Admin) usersImpersonateHandler(ctx context.Context) http.Handler {
 		}
 
 		// login the user into the session of the provided user
-		if err := admin.dependencies.Auth.Login(w, r, user); err != nil {
+		if err := admin.dependencies.Auth.Login(w, r, user, auth.LoginImpersonate); err != nil {
 			logger.Error(
 				"failed to login user",
 				"error", err,
@@ -10,12 +10,42 @@ const phpDevelopment = document.getElementById('phpDevelopment') as HTMLInputEle
 const contentSecurityPolicy = document.getElementById('contentsecuritypolicy') as HTMLInputElement
 const iipserver = document.getElementById('iipserver') as HTMLInputElement
 const ipAllowlist = document.getElementById('ipallowlist') as HTMLInputElement
+const aliases = document.getElementById('aliases') as HTMLInputElement
+const cpuLimit = document.getElementById('cpulimit') as HTMLInputElement
+const memoryLimit = document.getElementById('memorylimit') as HTMLInputElement
+const phpMemoryLimit = document.getElementById('phpmemorylimit') as HTMLInputElement
+const serviceCpuLimit = document.getElementById('servicecpulimit') as HTMLInputElement
+const serviceMemoryLimit = document.getElementById('servicememorylimit') as HTMLInputElement
+const triplestoreHeap = document.getElementById('triplestoreheap') as HTMLInputElement
+const solrHeap = document.getElementById('solrheap') as HTMLInputElement
+const basicAuth = document.getElementById('basicauth') as HTMLInputElement
+const rateLimit = document.getElementById('ratelimit') as HTMLInputElement
+const rateLimitBurst = document.getElementById('ratelimitburst') as HTMLInputElement
+const inFlightLimit = document.getElementById('inflightlimit') as HTMLInputElement
 
 // add an event handler to open the modal form!
 system.addEventListener('submit', (evt) => {
   evt.preventDefault()
 
-  Rebuild(slug.value, { PHP: php.value, IIPServer: iipserver.checked, PHPDevelopment: phpDevelopment.checked, ContentSecurityPolicy: contentSecurityPolicy.value, IPAllowlist: ipAllowlist.value })
+  Rebuild(slug.value, {
+    PHP: php.value,
+    IIPServer: iipserver.checked,
+    PHPDevelopment: phpDevelopment.checked,
+    ContentSecurityPolicy: contentSecurityPolicy.value,
+    IPAllowlist: ipAllowlist.value,
+    Aliases: aliases.value,
+    CPULimit: cpuLimit.value,
+    MemoryLimit: memoryLimit.value,
+    PHPMemoryLimit: phpMemoryLimit.value,
+    ServiceCPULimit: serviceCpuLimit.value,
+    ServiceMemoryLimit: serviceMemoryLimit.value,
+    TriplestoreHeap: triplestoreHeap.value,
+    SolrHeap: solrHeap.value,
+    BasicAuth: basicAuth.value,
+    RateLimit: rateLimit.valueAsNumber || 0,
+    RateLimitBurst: rateLimitBurst.valueAsNumber || 0,
+    InFlightLimit: inFlightLimit.valueAsNumber || 0,
+  })
     .then(slug => {
       location.href = '/admin/instance/' + slug
     })
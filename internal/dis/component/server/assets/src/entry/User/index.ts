@@ -1,3 +1,4 @@
 import '~/src/lib/copy'
 import '~/src/lib/reveal'
 import '~/src/lib/filter'
+import '~/src/lib/passkey'
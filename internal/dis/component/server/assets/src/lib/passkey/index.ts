@@ -0,0 +1,108 @@
+/** passkey implements the browser side of WebAuthn ceremonies */
+
+document.querySelectorAll<HTMLElement>('[data-passkey-login]').forEach((elem) => {
+  elem.addEventListener('click', (evt) => {
+    evt.preventDefault()
+
+    const options = JSON.parse(elem.getAttribute('data-passkey-login') ?? '{}')
+    const publicKey = options.publicKey
+    publicKey.challenge = decode(publicKey.challenge)
+    publicKey.allowCredentials?.forEach((cred: any) => { cred.id = decode(cred.id) })
+
+    ceremony(elem, async () => {
+      const credential = await navigator.credentials.get({ publicKey }) as PublicKeyCredential | null
+      if (credential === null) throw new Error('No passkey was selected')
+
+      const response = credential.response as AuthenticatorAssertionResponse
+      return {
+        id: credential.id,
+        rawId: encode(credential.rawId),
+        type: credential.type,
+        response: {
+          clientDataJSON: encode(response.clientDataJSON),
+          authenticatorData: encode(response.authenticatorData),
+          signature: encode(response.signature),
+          userHandle: response.userHandle !== null ? encode(response.userHandle) : undefined
+        }
+      }
+    })
+  })
+})
+
+document.querySelectorAll<HTMLElement>('[data-passkey-register]').forEach((elem) => {
+  elem.addEventListener('click', (evt) => {
+    evt.preventDefault()
+
+    const options = JSON.parse(elem.getAttribute('data-passkey-register') ?? '{}')
+    const publicKey = options.publicKey
+    publicKey.challenge = decode(publicKey.challenge)
+    publicKey.user.id = decode(publicKey.user.id)
+    publicKey.excludeCredentials?.forEach((cred: any) => { cred.id = decode(cred.id) })
+
+    ceremony(elem, async () => {
+      const credential = await navigator.credentials.create({ publicKey }) as PublicKeyCredential | null
+      if (credential === null) throw new Error('No passkey was created')
+
+      const response = credential.response as AuthenticatorAttestationResponse
+      return {
+        name: valueOf('[data-passkey-name]'),
+        password: valueOf('[data-passkey-password]'),
+        credential: {
+          id: credential.id,
+          rawId: encode(credential.rawId),
+          type: credential.type,
+          response: {
+            clientDataJSON: encode(response.clientDataJSON),
+            attestationObject: encode(response.attestationObject),
+            transports: response.getTransports?.() ?? []
+          }
+        }
+      }
+    })
+  })
+})
+
+/** ceremony runs a webauthn ceremony and posts the result back to the current page */
+function ceremony (elem: HTMLElement, run: () => Promise<any>): void {
+  const error = document.querySelector<HTMLElement>('[data-passkey-error]')
+  const next = elem.getAttribute('data-passkey-next') ?? '/'
+
+  elem.setAttribute('disabled', 'disabled')
+  if (error !== null) error.hidden = true
+
+  run()
+    .then(async (body) => await fetch(location.href, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(body)
+    }))
+    .then(async (res) => {
+      if (!res.ok) throw new Error(await res.text())
+      const result = await res.json()
+      location.href = result.next ?? next
+    })
+    .catch((err: unknown) => {
+      console.error(err)
+      elem.removeAttribute('disabled')
+      if (error === null) return
+      error.innerText = err instanceof Error ? err.message : String(err)
+      error.hidden = false
+    })
+}
+
+function valueOf (selector: string): string {
+  return document.querySelector<HTMLInputElement>(selector)?.value ?? ''
+}
+
+/** decode decodes base64url-encoded data */
+function decode (data: string): ArrayBuffer {
+  const base64 = data.replace(/-/g, '+').replace(/_/g, '/')
+  const binary = atob(base64.padEnd(base64.length + (4 - base64.length % 4) % 4, '='))
+  return Uint8Array.from(binary, c => c.charCodeAt(0)).buffer
+}
+
+/** encode encodes data as base64url without padding */
+function encode (data: ArrayBuffer): string {
+  const binary = String.fromCharCode(...new Uint8Array(data))
+  return btoa(binary).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '')
+}
@@ -16,6 +16,18 @@ interface System {
   PHPDevelopment: boolean
   ContentSecurityPolicy: string
   IPAllowlist: string
+  Aliases: string
+  CPULimit: string
+  MemoryLimit: string
+  PHPMemoryLimit: string
+  ServiceCPULimit: string
+  ServiceMemoryLimit: string
+  TriplestoreHeap: string
+  SolrHeap: string
+  BasicAuth: string
+  RateLimit: number
+  RateLimitBurst: number
+  InFlightLimit: number
   DedicatedSQL?: boolean
   DedicatedTriplestore?: boolean
   SolrServer?: boolean
@@ -80,9 +80,11 @@ export default function setup (): void {
     const action = element.getAttribute('data-action') as string
     const reload = element.getAttribute('data-force-reload')
     const param = element.getAttribute('data-param') as string | undefined
+    const extraParams = element.getAttribute('data-params')
     const cancelText = element.getAttribute('data-cancel-text') as string | undefined
 
     const confirmElementName = element.getAttribute('data-confirm-param')
+    const confirmSend = element.hasAttribute('data-confirm-send')
     const confirmElement = typeof confirmElementName === 'string' ? document.querySelector(confirmElementName) : null
 
     const getConfirmValue = (): string | null => {
@@ -139,6 +141,12 @@ export default function setup (): void {
 
       // create a modal dialog
       const params = (typeof param === 'string') ? [param] : []
+      if (typeof extraParams === 'string') {
+        params.push(...(JSON.parse(extraParams) as string[]))
+      }
+      if (confirmSend) {
+        params.push(getConfirmValue() ?? '')
+      }
       createModal(action, params, {
         onClose,
         cancelText,
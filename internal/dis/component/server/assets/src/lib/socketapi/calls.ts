@@ -39,6 +39,22 @@ export function Snapshot(Slug: string): CallSpec {
   };
 }
 
+/** SnapshotIncremental makes an incremental snapshot of an instance */
+export function SnapshotIncremental(Slug: string): CallSpec {
+  return {
+    call: "snapshot_incremental",
+    params: [Slug],
+  };
+}
+
+/** Restore restores an instance from the snapshot with the given id, Confirmation must be the slug of the instance */
+export function Restore(Slug: string, Snapshot: number, Confirmation: string): CallSpec {
+  return {
+    call: "restore",
+    params: [Slug, Snapshot.toString(), Confirmation],
+  };
+}
+
 /** Rebuild rebuilds an instance */
 export function Rebuild(Slug: string, params: SystemParams): CallSpec {
   return {
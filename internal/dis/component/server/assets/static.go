@@ -26,6 +26,9 @@ func (*Static) Routes() component.Routes {
 	return component.Routes{
 		Prefix: Public,
 
+		// also needed by the maintenance page of instances
+		MatchAllDomains: true,
+
 		CSRF: false,
 	}
 }
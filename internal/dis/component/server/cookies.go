@@ -1,7 +1,7 @@
 //spellchecker:words server
 package server
 
-// CSRFCookie, CSRFCookieField, SessionCookie and SessionUserKey
+// CSRFCookie, CSRFCookieField, SessionCookie, SessionUserKey, SessionMethodKey and OIDCCookie
 // hold the names of the cookies and fields used for specific cookies.
 //
 // These are intentionally kept short to conserve bandwidth.
@@ -10,6 +10,9 @@ const (
 	CSRFCookieField = "@" // form field name __should not be used by anything else__
 	// to pay respect.
 
-	SessionCookie  = "x" // name of the cookie to use ; to doubt
-	SessionUserKey = "@" // key within the session data to hold the username
+	SessionCookie    = "x" // name of the cookie to use ; to doubt
+	SessionUserKey   = "@" // key within the session data to hold the username
+	SessionMethodKey = "m" // key within the session data to hold the login method
+
+	OIDCCookie = "o" // cookie holding the state of a pending single sign-on
 )
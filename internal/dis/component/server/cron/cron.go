@@ -1,12 +1,15 @@
 //spellchecker:words cron
 package cron
 
-//spellchecker:words context signal sync syscall time github wisski distillery internal component wdlog pkglib timex
+//spellchecker:words context maps signal slices strings sync syscall time github wisski distillery internal component wdlog pkglib timex
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -21,6 +24,40 @@ type Cron struct {
 	dependencies struct {
 		Tasks []component.Cronable
 	}
+
+	runsM sync.Mutex
+	runs  map[string]TaskRun // last run of each task, by name
+}
+
+// TaskRun records the last run of a cron task.
+type TaskRun struct {
+	Task     string        // name of the task
+	Start    time.Time     // time the task was started
+	Duration time.Duration // time the task took
+	Failed   bool          // task returned an error or panicked
+}
+
+// LastRuns returns the last run of each task that has run in this process, ordered by name.
+func (control *Cron) LastRuns() []TaskRun {
+	control.runsM.Lock()
+	defer control.runsM.Unlock()
+
+	runs := slices.Collect(maps.Values(control.runs))
+	slices.SortFunc(runs, func(a, b TaskRun) int {
+		return strings.Compare(a.Task, b.Task)
+	})
+	return runs
+}
+
+// recordRun records the run of a task.
+func (control *Cron) recordRun(run TaskRun) {
+	control.runsM.Lock()
+	defer control.runsM.Unlock()
+
+	if control.runs == nil {
+		control.runs = make(map[string]TaskRun)
+	}
+	control.runs[run.Task] = run
 }
 
 // Listen returns a channel that listens for triggers in the current process.
@@ -56,6 +93,9 @@ func (control *Cron) Once(ctx context.Context) {
 	var wg sync.WaitGroup
 	wg.Add(len(control.dependencies.Tasks))
 
+	// identify this run in log messages of all tasks
+	ctx = wdlog.Set(ctx, wdlog.Of(ctx).With("cron", wdlog.NewID()))
+
 	wdlog.Of(ctx).Info(
 		"Starting Cron",
 	)
@@ -84,6 +124,12 @@ func (control *Cron) Once(ctx context.Context) {
 			}()
 
 			took := time.Since(start)
+			control.recordRun(TaskRun{
+				Task:     name,
+				Start:    start,
+				Duration: took,
+				Failed:   panicked || err != nil,
+			})
 
 			switch {
 			case !panicked:
@@ -1,13 +1,14 @@
 //spellchecker:words home
 package home
 
-//spellchecker:words context http github wisski distillery internal component server handling list templating
+//spellchecker:words context http github wisski distillery internal component instances server handling list templating
 import (
 	"context"
 	"fmt"
 	"net/http"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/handling"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/list"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
@@ -17,6 +18,7 @@ type Home struct {
 	component.Base
 	dependencies struct {
 		ListInstances *list.ListInstances
+		Instances     *instances.Instances
 		Templating    *templating.Templating
 		Handling      *handling.Handling
 	}
@@ -46,20 +48,22 @@ func (home *Home) HandleRoute(ctx context.Context, route string) (http.Handler,
 	}
 	dflt.Fallback = home.publicHandler(ctx)
 
+	maintenance := maintenanceTemplate.Prepare(home.dependencies.Templating)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		slug, ok := component.GetStill(home).Config.HTTP.NormSlugFromHost(r.Host)
+		slug, ok := home.dependencies.Instances.NormSlugFromHost(r.Context(), r.Host)
 		switch {
 		case !ok:
 			http.NotFound(w, r)
 		case slug != "":
-			home.serveWissKI(w, slug, r)
+			home.serveWissKI(maintenance, w, slug, r)
 		default:
 			dflt.ServeHTTP(w, r)
 		}
 	}), nil
 }
 
-func (home *Home) serveWissKI(w http.ResponseWriter, slug string, _ *http.Request) {
+func (home *Home) serveWissKI(maintenance *templating.Template[maintenanceContext], w http.ResponseWriter, slug string, r *http.Request) {
 	if _, ok := home.dependencies.ListInstances.Names()[slug]; !ok {
 		// Get(nil) guaranteed to work by precondition
 		w.WriteHeader(http.StatusNotFound)
@@ -67,6 +71,5 @@ func (home *Home) serveWissKI(w http.ResponseWriter, slug string, _ *http.Reques
 		return
 	}
 
-	w.WriteHeader(http.StatusBadGateway)
-	_, _ = fmt.Fprintf(w, "WissKI %q is currently offline\n", slug) // #nosec G705 -- cleaned up via quote
+	home.serveMaintenance(maintenance, w, slug, r)
 }
@@ -0,0 +1,89 @@
+//spellchecker:words home
+package home
+
+//spellchecker:words context embed http strconv time github wisski distillery internal component server assets templating status wdlog
+import (
+	"context"
+	_ "embed"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/assets"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
+	"github.com/FAU-CDI/wisski-distillery/internal/status"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+)
+
+//go:embed "maintenance.html"
+var maintenanceHTML []byte
+var maintenanceTemplate = templating.Parse[maintenanceContext](
+	"maintenance.html", maintenanceHTML, nil,
+
+	templating.Title("Maintenance"),
+	templating.Assets(assets.AssetsDefault),
+)
+
+// maintenanceContext is passed to maintenance.html.
+type maintenanceContext struct {
+	templating.RuntimeFlags
+
+	Info status.WissKI
+}
+
+// serveMaintenance serves the maintenance page for the instance with the given slug.
+//
+// It is served by the fallback router whenever the barrel of the instance is down,
+// and by the maintenance router of the web component while the instance is locked.
+func (home *Home) serveMaintenance(tpl *templating.Template[maintenanceContext], w http.ResponseWriter, slug string, r *http.Request) {
+	info := home.maintenanceInfo(r.Context(), slug)
+
+	if !info.LockExpected.IsZero() {
+		if wait := time.Until(info.LockExpected); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())))
+		}
+	}
+
+	tpl.HTMLHandler(home.dependencies.Handling, func(r *http.Request) (maintenanceContext, error) {
+		return maintenanceContext{Info: info}, nil
+	}).ServeHTTP(&statusWriter{ResponseWriter: w, status: http.StatusServiceUnavailable}, r)
+}
+
+// maintenanceInfo quickly fetches information about the instance with the given slug.
+// When this fails, only the slug is returned.
+func (home *Home) maintenanceInfo(ctx context.Context, slug string) status.WissKI {
+	instance, err := home.dependencies.Instances.WissKI(ctx, slug)
+	if err != nil {
+		wdlog.Of(ctx).Error("failed to get instance for maintenance page", "slug", slug, "error", err)
+		return status.WissKI{Slug: slug}
+	}
+
+	info, err := instance.Info().Information(ctx, true)
+	if err != nil {
+		wdlog.Of(ctx).Error("failed to get information for maintenance page", "slug", slug, "error", err)
+	}
+	info.Slug = slug
+	return info
+}
+
+// statusWriter replaces the status code of a successful response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	if !sw.wrote && code == http.StatusOK {
+		code = sw.status
+	}
+	sw.wrote = true
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *statusWriter) Write(data []byte) (int, error) {
+	if !sw.wrote {
+		sw.WriteHeader(http.StatusOK)
+	}
+	return sw.ResponseWriter.Write(data) //nolint:wrapcheck // transparent wrapper
+}
@@ -27,6 +27,9 @@ func (*Logo) Routes() component.Routes {
 		Prefix:  "/logo/",
 		Aliases: []string{"/favicon.ico", "/logo.svg"},
 		Exact:   true,
+
+		// also needed by the maintenance page of instances
+		MatchAllDomains: true,
 	}
 }
 
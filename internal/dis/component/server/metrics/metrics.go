@@ -0,0 +1,288 @@
+// Package metrics implements an internal endpoint exposing metrics about the distillery and its instances.
+//
+//spellchecker:words metrics
+package metrics
+
+//spellchecker:words context http sync time github wisski distillery internal component exporter logger instances server cron sql triplestore models status wdlog
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/logger"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/cron"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/triplestore"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/status"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+)
+
+// Metrics exposes metrics about the distillery and its instances in the Prometheus text format.
+// It is only available on the internal server.
+type Metrics struct {
+	component.Base
+	dependencies struct {
+		Instances   *instances.Instances
+		Logger      *logger.Logger
+		Cron        *cron.Cron
+		SQL         *sql.SQL
+		Triplestore *triplestore.Triplestore
+	}
+
+	cacheM    sync.Mutex
+	cache     []byte    // last rendered metrics
+	cacheTime time.Time // time the cache was rendered
+}
+
+var (
+	_ component.Routeable = (*Metrics)(nil)
+)
+
+func (*Metrics) Routes() component.Routes {
+	return component.Routes{
+		Prefix:   "/metrics",
+		Exact:    true,
+		Internal: true,
+	}
+}
+
+const (
+	// cacheTTL is the time rendered metrics are re-used for.
+	// Collecting metrics runs commands inside every instance, so concurrent or frequent scrapes should not repeat it.
+	cacheTTL = 30 * time.Second
+
+	// healthTimeout is the maximal time a health check of the sql database or triplestore may take.
+	healthTimeout = 10 * time.Second
+)
+
+func (metrics *Metrics) HandleRoute(ctx context.Context, path string) (http.Handler, error) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := metrics.render(r.Context())
+
+		w.Header().Set("Content-Type", ContentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	}), nil
+}
+
+// render returns the current metrics, re-using recently rendered ones.
+func (metrics *Metrics) render(ctx context.Context) []byte {
+	metrics.cacheM.Lock()
+	defer metrics.cacheM.Unlock()
+
+	if metrics.cache != nil && time.Since(metrics.cacheTime) < cacheTTL {
+		return metrics.cache
+	}
+
+	var w textWriter
+	metrics.collect(ctx, &w)
+
+	metrics.cache = w.Bytes()
+	metrics.cacheTime = time.Now()
+	return metrics.cache
+}
+
+// instanceMetrics holds the metrics collected for a single instance.
+type instanceMetrics struct {
+	info     status.WissKI
+	lastCron time.Time
+}
+
+// collect collects all metrics and writes them to w.
+func (metrics *Metrics) collect(ctx context.Context, w *textWriter) {
+	now := time.Now()
+
+	metrics.collectHealth(ctx, w)
+	metrics.collectInstances(ctx, w, now)
+	metrics.collectExports(ctx, w, now)
+	metrics.collectCron(w, now)
+}
+
+func (metrics *Metrics) collectHealth(ctx context.Context, w *textWriter) {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	var sqlErr, tsErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		sqlErr = metrics.dependencies.SQL.Ping(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		tsErr = metrics.dependencies.Triplestore.Ping(ctx)
+	}()
+	wg.Wait()
+
+	w.family("wisski_distillery_sql_up", "gauge", "Whether the distillery sql database can be reached.")
+	w.sample("wisski_distillery_sql_up", boolValue(sqlErr == nil))
+
+	w.family("wisski_distillery_triplestore_up", "gauge", "Whether the distillery triplestore can be reached.")
+	w.sample("wisski_distillery_triplestore_up", boolValue(tsErr == nil))
+}
+
+func (metrics *Metrics) collectInstances(ctx context.Context, w *textWriter, now time.Time) {
+	all, err := metrics.dependencies.Instances.All(ctx)
+	if err != nil {
+		wdlog.Of(ctx).Error(
+			"failed to list instances for metrics",
+			"error", err,
+		)
+		return
+	}
+
+	// collect information about all instances in parallel
+	collected := make([]instanceMetrics, len(all))
+	var wg sync.WaitGroup
+	wg.Add(len(all))
+	for i, instance := range all {
+		go func() {
+			defer wg.Done()
+
+			var err error
+			collected[i].info, err = instance.Info().Information(ctx, true)
+			if err != nil {
+				wdlog.Of(ctx).Warn(
+					"failed to fetch information for instance",
+					"error", err,
+					"slug", instance.Slug,
+				)
+			}
+			if collected[i].info.Running {
+				collected[i].lastCron, _ = instance.Drush().LastCron(ctx, nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	w.family("wisski_distillery_instances", "gauge", "Number of instances.")
+	w.sample("wisski_distillery_instances", float64(len(all)))
+
+	w.family("wisski_instance_up", "gauge", "Whether the instance is running.")
+	for i, instance := range all {
+		w.sample("wisski_instance_up", boolValue(collected[i].info.Running), "slug", instance.Slug)
+	}
+
+	w.family("wisski_instance_locked", "gauge", "Whether the instance is locked.")
+	for i, instance := range all {
+		w.sample("wisski_instance_locked", boolValue(collected[i].info.Locked), "slug", instance.Slug)
+	}
+
+	ages := []struct {
+		name string
+		help string
+		get  func(m instanceMetrics) time.Time
+	}{
+		{"wisski_instance_last_cron_age_seconds", "Seconds since cron last ran in the instance.", func(m instanceMetrics) time.Time { return m.lastCron }},
+		{"wisski_instance_last_update_age_seconds", "Seconds since the instance was last updated.", func(m instanceMetrics) time.Time { return m.info.LastUpdate }},
+		{"wisski_instance_last_rebuild_age_seconds", "Seconds since the instance was last rebuilt.", func(m instanceMetrics) time.Time { return m.info.LastRebuild }},
+	}
+	for _, age := range ages {
+		w.family(age.name, "gauge", age.help)
+		for i, instance := range all {
+			if t := age.get(collected[i]); !isUnset(t) {
+				w.sample(age.name, now.Sub(t).Seconds(), "slug", instance.Slug)
+			}
+		}
+	}
+}
+
+func (metrics *Metrics) collectExports(ctx context.Context, w *textWriter, now time.Time) {
+	exports, err := metrics.dependencies.Logger.Log(ctx)
+	if err != nil {
+		wdlog.Of(ctx).Error(
+			"failed to read export log for metrics",
+			"error", err,
+		)
+		return
+	}
+
+	type exportStats struct {
+		count  int
+		size   int64
+		latest time.Time
+	}
+	var slugs []string
+	stats := make(map[string]*exportStats)
+	for _, export := range exports {
+		s, ok := stats[export.Slug]
+		if !ok {
+			s = new(exportStats)
+			stats[export.Slug] = s
+			slugs = append(slugs, export.Slug)
+		}
+
+		s.count++
+		s.size += exportSize(export)
+		if export.Created.After(s.latest) {
+			s.latest = export.Created
+		}
+	}
+
+	// exports without a slug are backups of the entire distillery
+	labels := func(slug string) []string {
+		if slug == "" {
+			return []string{"kind", "backup", "slug", ""}
+		}
+		return []string{"kind", "snapshot", "slug", slug}
+	}
+
+	w.family("wisski_distillery_exports", "gauge", "Number of exports in the export log.")
+	for _, slug := range slugs {
+		w.sample("wisski_distillery_exports", float64(stats[slug].count), labels(slug)...)
+	}
+
+	w.family("wisski_distillery_export_size_bytes", "gauge", "Total size of exports stored as archives on the local disk.")
+	for _, slug := range slugs {
+		w.sample("wisski_distillery_export_size_bytes", float64(stats[slug].size), labels(slug)...)
+	}
+
+	w.family("wisski_distillery_last_export_age_seconds", "gauge", "Seconds since the last export was made.")
+	for _, slug := range slugs {
+		w.sample("wisski_distillery_last_export_age_seconds", now.Sub(stats[slug].latest).Seconds(), labels(slug)...)
+	}
+}
+
+// exportSize returns the size of the archive of export on the local disk.
+// Exports that are not stored as archives on the local disk have size 0.
+func exportSize(export models.Export) int64 {
+	if !export.IsLocal() || !export.Packed || export.Incremental {
+		return 0
+	}
+	info, err := os.Stat(export.Path)
+	if err != nil || !info.Mode().IsRegular() {
+		return 0
+	}
+	return info.Size()
+}
+
+func (metrics *Metrics) collectCron(w *textWriter, now time.Time) {
+	runs := metrics.dependencies.Cron.LastRuns()
+
+	w.family("wisski_distillery_cron_task_duration_seconds", "gauge", "Duration of the last run of the cron task.")
+	for _, run := range runs {
+		w.sample("wisski_distillery_cron_task_duration_seconds", run.Duration.Seconds(), "task", run.Task)
+	}
+
+	w.family("wisski_distillery_cron_task_last_run_age_seconds", "gauge", "Seconds since the cron task was last started.")
+	for _, run := range runs {
+		w.sample("wisski_distillery_cron_task_last_run_age_seconds", now.Sub(run.Start).Seconds(), "task", run.Task)
+	}
+
+	w.family("wisski_distillery_cron_task_failed", "gauge", "Whether the last run of the cron task failed.")
+	for _, run := range runs {
+		w.sample("wisski_distillery_cron_task_failed", boolValue(run.Failed), "task", run.Task)
+	}
+}
+
+// isUnset checks if t does not represent an actual point in time.
+// Times stored as unix timestamps are unset when they are 0.
+func isUnset(t time.Time) bool {
+	return t.IsZero() || t.Unix() <= 0
+}
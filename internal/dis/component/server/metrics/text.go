@@ -0,0 +1,55 @@
+//spellchecker:words metrics
+package metrics
+
+//spellchecker:words bytes strconv strings
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// textWriter writes metrics in the Prometheus text exposition format.
+type textWriter struct {
+	bytes.Buffer
+}
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// family starts a new metric family with the given name, type and help text.
+func (w *textWriter) family(name, typ, help string) {
+	w.WriteString("# HELP " + name + " " + helpEscaper.Replace(help) + "\n")
+	w.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+// sample writes a single sample of the metric with the given name.
+// labels are given as alternating names and values.
+func (w *textWriter) sample(name string, value float64, labels ...string) {
+	w.WriteString(name)
+	if len(labels) > 1 {
+		w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(labels[i] + `="` + labelEscaper.Replace(labels[i+1]) + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	w.WriteByte('\n')
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// boolValue returns 1 if b is true, and 0 otherwise.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -38,6 +38,10 @@ var (
 	_ component.Installable = (*Server)(nil)
 )
 
+// RequestIDHeader is the response header holding the id of the request.
+// The id is also included in all log messages belonging to the request.
+const RequestIDHeader = "X-Request-Id"
+
 // Logging messages are directed to progress.
 func (server *Server) Server(ctx context.Context, progress io.Writer) (public http.Handler, internal http.Handler, err error) {
 	interceptor := server.dependencies.Handleing.TextInterceptor()
@@ -56,6 +60,10 @@ func (server *Server) Server(ctx context.Context, progress io.Writer) (public ht
 			// determine if we are on a slug from a host
 			slug, ok := component.GetStill(server).Config.HTTP.NormSlugFromHost(r.Host)
 
+			// identify the request in log messages and to the client
+			requestID := wdlog.NewID()
+			w.Header().Set(RequestIDHeader, requestID)
+
 			// copy over the route context and the logger
 			// into the child context
 			rctx := wdlog.Set(
@@ -65,7 +73,7 @@ func (server *Server) Server(ctx context.Context, progress io.Writer) (public ht
 						DefaultDomain: slug == "" && ok,
 					},
 				),
-				wdlog.Of(ctx),
+				wdlog.Of(ctx).With("request", requestID),
 			)
 
 			// serve with the next context
@@ -26,6 +26,14 @@ func (sql *SQL) Wait(ctx context.Context) (err error) {
 	return nil
 }
 
+// Ping checks that the distillery-specific database can be reached, without waiting for it.
+func (sql *SQL) Ping(ctx context.Context) error {
+	if _, err := sql.connectSQL(ctx); err != nil {
+		return fmt.Errorf("failed to connect to sql: %w", err)
+	}
+	return nil
+}
+
 // connectSQL establishes a connection to the sql database.
 // The context is used to check connection validity, and not attached to the connection permanently.
 func (sql *SQL) connectSQL(ctx context.Context) (*databaseSQL.DB, error) {
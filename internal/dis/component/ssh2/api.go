@@ -27,7 +27,7 @@ func (ssh2 *SSH2) HandleRoute(ctx context.Context, path string) (http.Handler, e
 		}
 
 		// find the host
-		slug, ok := component.GetStill(ssh2).Config.HTTP.SlugFromHost(r.Host)
+		slug, ok := ssh2.dependencies.Instances.SlugFromHost(r.Context(), r.Host)
 		if slug == "" || !ok {
 			httpx.TextInterceptor.Intercept(w, r, httpx.ErrNotFound)
 			return
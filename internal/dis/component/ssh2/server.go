@@ -21,6 +21,7 @@ func (ssh2 *SSH2) Server(ctx context.Context, privateKeyPath string, progress io
 		return nil, err
 	}
 
+	ssh2.setupLogging(ctx, &server)
 	ssh2.setupForwardHandler(&server)
 	ssh2.setupHandler(&server)
 	ssh2.setupAuth(&server)
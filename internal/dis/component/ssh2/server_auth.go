@@ -1,9 +1,11 @@
 package ssh2
 
-//spellchecker:words github wisski distillery internal component sshkeys gliderlabs
+//spellchecker:words github wisski distillery internal component sshkeys wdlog gliderlabs golang crypto gossh
 import (
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/ssh2/sshkeys"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
 	"github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 func (ssh2 *SSH2) setupAuth(server *ssh.Server) {
@@ -16,6 +18,9 @@ type ssh2Key int
 const (
 	// permissions represents the permissions for the given session.
 	permission ssh2Key = iota
+
+	// connectionLogger represents the logger for the given connection.
+	connectionLogger
 )
 
 func setPermissions(context ssh.Context, permissions map[string]bool) {
@@ -48,12 +53,21 @@ func getAnyPermission(context ssh.Context) (string, bool) {
 }
 
 func (ssh2 *SSH2) handleAuth(ctx ssh.Context, key ssh.PublicKey) bool {
+	lctx := withLogger(ctx)
 	return sshkeys.Slowdown(func() (ok bool) {
 		permissions := make(map[string]bool)
+		defer func() {
+			wdlog.Of(lctx).Debug(
+				"public key authentication",
+				"user", ctx.User(),
+				"fingerprint", gossh.FingerprintSHA256(key),
+				"ok", ok,
+			)
+		}()
 
 		// grab the global permissions
 		{
-			globalKeys, err := ssh2.dependencies.Keys.Admin(ctx)
+			globalKeys, err := ssh2.dependencies.Keys.Admin(lctx)
 			if err != nil {
 				return false
 			}
@@ -63,13 +77,13 @@ func (ssh2 *SSH2) handleAuth(ctx ssh.Context, key ssh.PublicKey) bool {
 
 		// grab permissions for each instance
 		{
-			instances, err := ssh2.dependencies.Instances.All(ctx)
+			instances, err := ssh2.dependencies.Instances.All(lctx)
 			if err != nil {
 				return false
 			}
 
 			for _, instance := range instances {
-				ikeys, err := instance.SSH().Keys(ctx)
+				ikeys, err := instance.SSH().Keys(lctx)
 				if err != nil {
 					continue
 				}
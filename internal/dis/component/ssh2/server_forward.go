@@ -1,12 +1,13 @@
 package ssh2
 
-//spellchecker:words github wisski distillery internal component gliderlabs golang crypto gossh
+//spellchecker:words github wisski distillery internal component wdlog gliderlabs golang crypto gossh
 import (
 	"fmt"
 	"io"
 	"net"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
 	"github.com/gliderlabs/ssh"
 	gossh "golang.org/x/crypto/ssh"
 )
@@ -82,7 +83,7 @@ func (ssh2 *SSH2) getForwardDest(req component.HostPort, ctx ssh.Context) (ok bo
 	config := component.GetStill(ssh2).Config
 
 	// then check the instances
-	slug, ok := config.HTTP.SlugFromHost(req.Host)
+	slug, ok := ssh2.dependencies.Instances.SlugFromHost(ctx, req.Host)
 	if !ok || req.Port != 22 || !hasPermission(ctx, slug) {
 		return false, dest, "permission denied"
 	}
@@ -102,8 +103,15 @@ func (ssh2 *SSH2) handleDirectTCP(srv *ssh.Server, conn *gossh.ServerConn, newCh
 		return
 	}
 
-	ok, dest, rejectReason := ssh2.getForwardDest(component.HostPort{Host: d.DestAddr, Port: d.DestPort}, ctx)
+	req := component.HostPort{Host: d.DestAddr, Port: d.DestPort}
+	logger := wdlog.Of(withLogger(ctx)).With("request", req.String())
+
+	ok, dest, rejectReason := ssh2.getForwardDest(req, ctx)
 	if !ok {
+		logger.Info(
+			"rejected forward",
+			"reason", rejectReason,
+		)
 		newChan.Reject(gossh.Prohibited, rejectReason)
 		return
 	}
@@ -111,9 +119,18 @@ func (ssh2 *SSH2) handleDirectTCP(srv *ssh.Server, conn *gossh.ServerConn, newCh
 	var dialer net.Dialer
 	dconn, err := dialer.DialContext(ctx, "tcp", dest.String())
 	if err != nil {
+		logger.Warn(
+			"failed to connect forward",
+			"dest", dest.String(),
+			"error", err,
+		)
 		newChan.Reject(gossh.ConnectionFailed, err.Error())
 		return
 	}
+	logger.Info(
+		"forwarding",
+		"dest", dest.String(),
+	)
 
 	ch, reqs, err := newChan.Accept()
 	if err != nil {
@@ -1,6 +1,6 @@
 package ssh2
 
-//spellchecker:words bufio strconv strings github wisski distillery internal component gliderlabs
+//spellchecker:words bufio strconv strings github wisski distillery internal component wdlog gliderlabs
 import (
 	"bufio"
 	"io"
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
 	"github.com/gliderlabs/ssh"
 )
 
@@ -56,6 +57,13 @@ func (ssh2 *SSH2) handleConnection(session ssh.Session) {
 		banner = strings.ReplaceAll(banner, oldnew[0], oldnew[1])
 	}
 
+	logger := wdlog.Of(withLogger(session.Context()))
+	logger.Info(
+		"session started",
+		"user", session.User(),
+	)
+	defer logger.Info("session closed")
+
 	_, _ = io.WriteString(session, banner) // no way to deal with this message
 
 	// wait until the user closes
@@ -0,0 +1,32 @@
+package ssh2
+
+//spellchecker:words context slog github wisski distillery internal wdlog gliderlabs
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
+	"github.com/gliderlabs/ssh"
+)
+
+// setupLogging assigns every connection to the server a logger identifying it.
+// Handlers retrieve it using [withLogger].
+func (ssh2 *SSH2) setupLogging(ctx context.Context, server *ssh.Server) {
+	server.ConnCallback = func(sctx ssh.Context, conn net.Conn) net.Conn {
+		logger := wdlog.Of(ctx).With(
+			"connection", wdlog.NewID(),
+			"remote", conn.RemoteAddr().String(),
+		)
+		sctx.SetValue(connectionLogger, logger)
+
+		logger.Debug("accepted connection")
+		return conn
+	}
+}
+
+// withLogger returns a context that uses the logger of the connection belonging to sctx.
+func withLogger(sctx ssh.Context) context.Context {
+	logger, _ := sctx.Value(connectionLogger).(*slog.Logger)
+	return wdlog.Set(sctx, logger)
+}
@@ -1,12 +1,13 @@
 //spellchecker:words sshkeys
 package sshkeys
 
-//spellchecker:words context github wisski distillery internal component auth gliderlabs
+//spellchecker:words context github wisski distillery internal component audit auth gliderlabs
 import (
 	"context"
 	"fmt"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
 	"github.com/gliderlabs/ssh"
@@ -15,8 +16,9 @@ import (
 type SSHKeys struct {
 	component.Base
 	dependencies struct {
-		SQL  *sql.SQL
-		Auth *auth.Auth
+		SQL   *sql.SQL
+		Auth  *auth.Auth
+		Audit *audit.Audit
 	}
 }
 
@@ -1,7 +1,7 @@
 //spellchecker:words sshkeys
 package sshkeys
 
-//spellchecker:words context github wisski distillery internal component models gliderlabs
+//spellchecker:words context github wisski distillery internal component models gliderlabs golang crypto gossh
 import (
 	"context"
 	"fmt"
@@ -9,6 +9,7 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
 	"github.com/FAU-CDI/wisski-distillery/internal/models"
 	"github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 func (ssh2 *SSHKeys) TableInfo() component.TableInfo {
@@ -42,7 +43,11 @@ func (ssh2 *SSHKeys) Keys(ctx context.Context, user string) ([]models.Keys, erro
 }
 
 // Add adds a new key to the given user, unless it already exists.
-func (ssh2 *SSHKeys) Add(ctx context.Context, user string, comment string, key ssh.PublicKey) error {
+func (ssh2 *SSHKeys) Add(ctx context.Context, user string, comment string, key ssh.PublicKey) (err error) {
+	defer func() {
+		ssh2.dependencies.Audit.Record(ctx, "ssh.add", "", map[string]string{"user": user, "comment": comment, "key": gossh.FingerprintSHA256(key)}, err)
+	}()
+
 	// check that the given user exists
 	{
 		_, err := ssh2.dependencies.Auth.User(ctx, user)
@@ -90,7 +95,11 @@ func (ssh2 *SSHKeys) Add(ctx context.Context, user string, comment string, key s
 }
 
 // Remove removes a given publuc key from a user.
-func (ssh2 *SSHKeys) Remove(ctx context.Context, user string, key ssh.PublicKey) error {
+func (ssh2 *SSHKeys) Remove(ctx context.Context, user string, key ssh.PublicKey) (err error) {
+	defer func() {
+		ssh2.dependencies.Audit.Record(ctx, "ssh.remove", "", map[string]string{"user": user, "key": gossh.FingerprintSHA256(key)}, err)
+	}()
+
 	// find all the keys for the given user
 	keys, err := ssh2.Keys(ctx, user)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"io"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/triplestore/client"
 )
 
 func (ts *Triplestore) BackupName() string { return "triplestore" }
@@ -24,7 +25,7 @@ func (ts *Triplestore) Backup(scontext *component.StagingContext) error {
 
 		for _, repo := range repos {
 			if err := scontext.AddFile(repo.ID+".nq", func(ctx context.Context, file io.Writer) error {
-				_, err := globalClient.ExportContent(ctx, file, repo.ID)
+				_, err := globalClient.ExportContent(ctx, file, repo.ID, client.ExportOptions{})
 				if err != nil {
 					return fmt.Errorf("failed to snapshot database: %w", err)
 				}
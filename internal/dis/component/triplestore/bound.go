@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/triplestore/client"
 	"github.com/FAU-CDI/wisski-distillery/internal/models"
 	"github.com/FAU-CDI/wisski-distillery/pkg/dockerx"
 )
@@ -40,4 +41,8 @@ type BoundTriplestore interface {
 	// Snapshots or restores the repository belonging to this instance.
 	SnapshotDB(ctx context.Context, progress io.Writer, dst io.Writer) error
 	RestoreDB(ctx context.Context, progress io.Writer, reader io.Reader) error
+
+	// Exports (parts of) the repository belonging to this instance into dst, or lists the named graphs in it.
+	Export(ctx context.Context, dst io.Writer, opts client.ExportOptions) error
+	Graphs(ctx context.Context) ([]string, error)
 }
@@ -81,16 +81,42 @@ func (bound *boundDedicated) Purge(ctx context.Context, progress io.Writer, allo
 
 // SnapshotDB snapshots the provided repository into dst.
 func (bound *boundDedicated) SnapshotDB(ctx context.Context, progress io.Writer, dst io.Writer) error {
+	return bound.Export(ctx, dst, client.ExportOptions{})
+}
+
+// Export exports the provided repository into dst.
+func (bound *boundDedicated) Export(ctx context.Context, dst io.Writer, opts client.ExportOptions) error {
 	return bound.do(ctx, stream.Null, true, func(stack *dockerx.Stack) error {
 		return bound.curl(
 			ctx, stack, dst,
-			"GET", "/repositories/"+url.PathEscape(bound.instance.GraphDBRepository)+"/statements?infer=false",
-			map[string]string{"Accept": client.NQuadsContentType},
+			"GET", opts.StatementsPath(bound.instance.GraphDBRepository),
+			map[string]string{"Accept": opts.ContentType()},
 			nil,
 		)
 	})
 }
 
+// Graphs lists the named graphs in the provided repository.
+func (bound *boundDedicated) Graphs(ctx context.Context) ([]string, error) {
+	var buffer bytes.Buffer
+	if err := bound.do(ctx, stream.Null, true, func(stack *dockerx.Stack) error {
+		return bound.curl(
+			ctx, stack, &buffer,
+			"GET", client.ContextsPath(bound.instance.GraphDBRepository),
+			map[string]string{"Accept": client.SPARQLResultsContentType},
+			nil,
+		)
+	}); err != nil {
+		return nil, err
+	}
+
+	graphs, err := client.ParseContexts(&buffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list graphs: %w", err)
+	}
+	return graphs, nil
+}
+
 // Provision provisions the repository for this instance, possibly deleting any existing repositories.
 func (bound *boundDedicated) Provision(ctx context.Context, progress io.Writer, domain string) (e error) {
 	var createRepo bytes.Buffer
@@ -47,13 +47,27 @@ func (bound *boundGlobal) Purge(ctx context.Context, progress io.Writer, allowCr
 
 // SnapshotDB snapshots the provided repository into dst.
 func (bound *boundGlobal) SnapshotDB(ctx context.Context, progress io.Writer, dst io.Writer) error {
-	_, err := bound.client.ExportContent(ctx, dst, bound.instance.GraphDBRepository)
+	return bound.Export(ctx, dst, client.ExportOptions{})
+}
+
+// Export exports the provided repository into dst.
+func (bound *boundGlobal) Export(ctx context.Context, dst io.Writer, opts client.ExportOptions) error {
+	_, err := bound.client.ExportContent(ctx, dst, bound.instance.GraphDBRepository, opts)
 	if err == nil {
 		return nil
 	}
 	return fmt.Errorf("failed to export content: %w", err)
 }
 
+// Graphs lists the named graphs in the provided repository.
+func (bound *boundGlobal) Graphs(ctx context.Context) ([]string, error) {
+	graphs, err := bound.client.Contexts(ctx, bound.instance.GraphDBRepository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list graphs: %w", err)
+	}
+	return graphs, nil
+}
+
 // Provision provisions the repository for this instance, possibly deleting any existing repositories.
 func (bound *boundGlobal) Provision(ctx context.Context, progress io.Writer, domain string) (e error) {
 	if err := bound.client.Wait(ctx, progress); err != nil {
@@ -12,10 +12,11 @@ import (
 
 const NQuadsContentType = "application/n-quads"
 
-// ExportContent exports the content of the provided repository as an n-quads file and writes them into dst.
+// ExportContent exports the content of the provided repository and writes it into dst.
+// The zero options export all statements as n-quads.
 // count contains the total number of bytes written, and any error.
-func (client *Client) ExportContent(ctx context.Context, dst io.Writer, repo string) (c int64, e error) {
-	res, err := client.rest(ctx, http.MethodGet, "/repositories/"+url.PathEscape(repo)+"/statements?infer=false", headers{Accept: NQuadsContentType})
+func (client *Client) ExportContent(ctx context.Context, dst io.Writer, repo string, opts ExportOptions) (c int64, e error) {
+	res, err := client.rest(ctx, http.MethodGet, opts.StatementsPath(repo), headers{Accept: opts.ContentType()})
 	if err != nil {
 		return 0, fmt.Errorf("failed to send statements endpoint request: %w", err)
 	}
@@ -31,6 +32,20 @@ func (client *Client) ExportContent(ctx context.Context, dst io.Writer, repo str
 	return count, nil
 }
 
+// Contexts lists the IRIs of the named graphs in the provided repository.
+func (client *Client) Contexts(ctx context.Context, repo string) (_ []string, e error) {
+	res, err := client.rest(ctx, http.MethodGet, ContextsPath(repo), headers{Accept: SPARQLResultsContentType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send contexts endpoint request: %w", err)
+	}
+	defer errorsx.Close(res.Body, &e, "response body")
+
+	if err := newStatusError(res, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("contexts endpoint responded: %w", err)
+	}
+	return ParseContexts(res.Body)
+}
+
 // ReplaceContent repleaces the content of the provided repository with the content of the given reader.
 // The reader must contain valid n-quads data.
 func (client *Client) ReplaceContent(ctx context.Context, repo string, reader io.Reader) (e error) {
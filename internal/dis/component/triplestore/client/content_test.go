@@ -0,0 +1,89 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/triplestore/client"
+)
+
+func TestExportContent(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repositories/repo/statements":
+			query := r.URL.Query()
+			if query.Get("infer") != "false" {
+				http.Error(w, "inference not disabled", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", r.Header.Get("Accept"))
+			_, _ = w.Write([]byte(r.Header.Get("Accept") + " " + r.URL.RawQuery))
+		case "/repositories/repo/contexts":
+			w.Header().Set("Content-Type", client.SPARQLResultsContentType)
+			_, _ = w.Write([]byte(`{"head":{"vars":["contextID"]},"results":{"bindings":[{"contextID":{"type":"uri","value":"http://example.com/a"}},{"contextID":{"type":"uri","value":"http://example.com/b"}}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := client.NewClient(time.Minute, server.URL, "admin", "admin")
+
+	for _, tt := range []struct {
+		name string
+		opts client.ExportOptions
+		want string
+	}{
+		{"zero options", client.ExportOptions{}, "application/n-quads infer=false"},
+		{"turtle", client.ExportOptions{Format: client.Turtle}, "text/turtle infer=false"},
+		{
+			"trig with graphs",
+			client.ExportOptions{Format: client.TriG, Graphs: []string{"http://example.com/a", "http://example.com/b"}},
+			"application/trig context=%3Chttp%3A%2F%2Fexample.com%2Fa%3E&context=%3Chttp%3A%2F%2Fexample.com%2Fb%3E&infer=false",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if _, err := c.ExportContent(context.Background(), &buffer, "repo", tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if got := buffer.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("contexts", func(t *testing.T) {
+		graphs, err := c.Contexts(context.Background(), "repo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"http://example.com/a", "http://example.com/b"}; !slices.Equal(graphs, want) {
+			t.Errorf("got %v, want %v", graphs, want)
+		}
+	})
+}
+
+func TestFormatByName(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range client.Formats {
+		got, err := client.FormatByName(format.Name)
+		if err != nil || got != format {
+			t.Errorf("FormatByName(%q) = %v, %v", format.Name, got, err)
+		}
+	}
+	if got, err := client.FormatByName(""); err != nil || got != client.NQuads {
+		t.Errorf("FormatByName(\"\") = %v, %v", got, err)
+	}
+	if _, err := client.FormatByName("csv"); err == nil {
+		t.Error("FormatByName(\"csv\") did not return an error")
+	}
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Format is an RDF serialization supported by the statements endpoint.
+type Format struct {
+	Name        string // short name used on the command line and in urls
+	ContentType string // content type to request from the statements endpoint
+	Extension   string // file extension, including the leading dot
+	Quads       bool   // does the format retain named graphs?
+}
+
+var (
+	NQuads   = Format{Name: "nquads", ContentType: NQuadsContentType, Extension: ".nq", Quads: true}
+	NTriples = Format{Name: "ntriples", ContentType: "application/n-triples", Extension: ".nt"}
+	Turtle   = Format{Name: "turtle", ContentType: "text/turtle", Extension: ".ttl"}
+	TriG     = Format{Name: "trig", ContentType: "application/trig", Extension: ".trig", Quads: true}
+	JSONLD   = Format{Name: "jsonld", ContentType: "application/ld+json", Extension: ".jsonld", Quads: true}
+	RDFXML   = Format{Name: "rdfxml", ContentType: "application/rdf+xml", Extension: ".rdf"}
+)
+
+// Formats lists all supported formats.
+var Formats = []Format{NQuads, NTriples, Turtle, TriG, JSONLD, RDFXML}
+
+var errUnknownFormat = errors.New("unknown format")
+
+// FormatByName returns the format with the given name.
+// The empty name returns [NQuads].
+func FormatByName(name string) (Format, error) {
+	if name == "" {
+		return NQuads, nil
+	}
+	for _, format := range Formats {
+		if strings.EqualFold(format.Name, name) {
+			return format, nil
+		}
+	}
+	return Format{}, fmt.Errorf("%w %q", errUnknownFormat, name)
+}
+
+// ExportOptions determine the content exported from a repository.
+type ExportOptions struct {
+	// Format to export in.
+	// The zero value exports in [NQuads].
+	Format Format
+
+	// Graphs optionally restricts the export to the named graphs with the given IRIs.
+	// When empty, all statements are exported.
+	Graphs []string
+}
+
+// ContentType returns the content type to request from the statements endpoint.
+func (opts ExportOptions) ContentType() string {
+	if opts.Format.ContentType == "" {
+		return NQuads.ContentType
+	}
+	return opts.Format.ContentType
+}
+
+// Query returns the query string to pass to the statements endpoint.
+func (opts ExportOptions) Query() string {
+	values := url.Values{}
+	values.Set("infer", "false")
+	for _, graph := range opts.Graphs {
+		values.Add("context", "<"+graph+">")
+	}
+	return values.Encode()
+}
+
+// StatementsPath returns the path of the statements endpoint of the given repository, including the query.
+func (opts ExportOptions) StatementsPath(repo string) string {
+	return "/repositories/" + url.PathEscape(repo) + "/statements?" + opts.Query()
+}
+
+// ContextsPath returns the path of the endpoint listing the named graphs in the given repository.
+func ContextsPath(repo string) string {
+	return "/repositories/" + url.PathEscape(repo) + "/contexts"
+}
+
+// SPARQLResultsContentType is the content type of sparql results in json.
+const SPARQLResultsContentType = "application/sparql-results+json"
+
+// ParseContexts parses the response of the contexts endpoint, and returns the IRIs of the named graphs.
+func ParseContexts(src io.Reader) ([]string, error) {
+	var results struct {
+		Results struct {
+			Bindings []struct {
+				ContextID struct {
+					Value string `json:"value"`
+				} `json:"contextID"`
+			} `json:"bindings"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(src).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode contexts: %w", err)
+	}
+
+	graphs := make([]string, len(results.Results.Bindings))
+	for i, binding := range results.Results.Bindings {
+		graphs[i] = binding.ContextID.Value
+	}
+	return graphs, nil
+}
@@ -3,6 +3,8 @@ package triplestore
 
 //spellchecker:words bytes context encoding json errors mime multipart http time github wisski distillery internal component wdlog pkglib errorsx timex
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
@@ -20,3 +22,11 @@ func (ts *Triplestore) globalClient() *client.Client {
 	client.PollInterval = ts.PollInterval
 	return client
 }
+
+// Ping checks that the triplestore can be reached with the admin credentials, without waiting for it.
+func (ts *Triplestore) Ping(ctx context.Context) error {
+	if _, err := ts.globalClient().ListRepositories(ctx); err != nil {
+		return fmt.Errorf("failed to list repositories: %w", err)
+	}
+	return nil
+}
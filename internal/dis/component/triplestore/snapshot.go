@@ -8,6 +8,7 @@ import (
 	"io"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/triplestore/client"
 	"github.com/FAU-CDI/wisski-distillery/internal/models"
 )
 
@@ -18,7 +19,7 @@ func (Triplestore) SnapshotName() string { return "triplestore" }
 func (ts *Triplestore) Snapshot(wisski models.Instance, scontext *component.StagingContext) error {
 	bound := ts.For(wisski)
 	if err := scontext.AddDirectory(".", func(ctx context.Context) error {
-		if err := scontext.AddFile(wisski.GraphDBRepository+".nq", func(ctx context.Context, file io.Writer) error {
+		if err := scontext.AddFile(wisski.GraphDBRepository+client.NQuads.Extension, func(ctx context.Context, file io.Writer) error {
 			err := bound.SnapshotDB(ctx, scontext.Progress(), file)
 			if err != nil {
 				return fmt.Errorf("failed to snapshot database: %w", err)
@@ -0,0 +1,18 @@
+//spellchecker:words component
+package component
+
+//spellchecker:words context github wisski distillery internal models
+import (
+	"context"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+// SSOLoginHook represents a hook that is called whenever a user logs in via single sign-on.
+type SSOLoginHook interface {
+	Component
+
+	// OnSSOLogin is called right after a user has been authenticated by the identity provider.
+	// Groups holds the groups the identity provider reported for the user.
+	OnSSOLogin(ctx context.Context, user *models.User, groups []string) error
+}
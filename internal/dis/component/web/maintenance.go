@@ -0,0 +1,84 @@
+//spellchecker:words web
+package web
+
+//spellchecker:words errors path filepath github wisski distillery internal component pkglib umaskfree gopkg yaml
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"go.tkw01536.de/pkglib/fsx/umaskfree"
+	"gopkg.in/yaml.v3"
+)
+
+// maintenancePriority is the priority of maintenance routers.
+// It must be higher than the priority of any barrel router, which traefik derives from the length of their rule.
+const maintenancePriority = 1_000_000
+
+// maintenanceService is the service that serves the maintenance page.
+// It is provided by the distillery server.
+const maintenanceService = "core_panel@docker"
+
+// DynamicPath returns the path to the directory of dynamic traefik configuration.
+func (web *Web) DynamicPath() string {
+	return filepath.Join(web.Path(), "dynamic")
+}
+
+func (web *Web) maintenancePath(slug string) string {
+	return filepath.Join(web.DynamicPath(), "maintenance_"+slug+".yml")
+}
+
+// SetMaintenance routes all requests matching rule to the maintenance page served by the distillery.
+// This takes precedence over the barrel of the instance with the given slug, even when it is running.
+func (web *Web) SetMaintenance(slug string, rule string) error {
+	name := "maintenance_" + slug
+
+	router := map[string]any{
+		"rule":     rule,
+		"priority": maintenancePriority,
+		"service":  maintenanceService,
+	}
+	if component.GetStill(web).Config.HTTP.HTTPSEnabled() {
+		router["tls"] = map[string]any{
+			"certResolver": "distillery",
+		}
+	}
+
+	data, err := yaml.Marshal(map[string]any{
+		"http": map[string]any{
+			"routers": map[string]any{
+				name: router,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal maintenance router: %w", err)
+	}
+
+	if err := umaskfree.MkdirAll(web.DynamicPath(), umaskfree.DefaultDirPerm); err != nil {
+		return fmt.Errorf("failed to create dynamic configuration directory: %w", err)
+	}
+	if err := umaskfree.WriteFile(web.maintenancePath(slug), data, umaskfree.DefaultFilePerm); err != nil {
+		return fmt.Errorf("failed to write maintenance router: %w", err)
+	}
+	return nil
+}
+
+// ClearMaintenance removes the maintenance router for the instance with the given slug, if any.
+func (web *Web) ClearMaintenance(slug string) error {
+	err := os.Remove(web.maintenancePath(slug))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove maintenance router: %w", err)
+	}
+	return nil
+}
+
+// InMaintenance checks if the maintenance page is served for the instance with the given slug.
+// This is the case while the instance is stopped or locked.
+func (web *Web) InMaintenance(slug string) bool {
+	_, err := os.Stat(web.maintenancePath(slug))
+	return err == nil
+}
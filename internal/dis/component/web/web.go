@@ -44,6 +44,7 @@ func (web *Web) OpenStack() (component.StackWithResources, error) {
 	var stack component.StackWithResources
 
 	config := component.GetStill(web).Config
+	stack.MakeDirs = []string{"dynamic"}
 	stack.EnvContext = map[string]string{
 		"DOCKER_NETWORK_NAME": config.Docker.Network(),
 		"CERT_EMAIL":          config.HTTP.CertbotEmail,
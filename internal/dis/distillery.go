@@ -1,17 +1,19 @@
 // Package dis provides the main distillery
 package dis
 
-//spellchecker:words sync time github wisski distillery internal component auth next panel policy scopes tokens binder docker exporter logger instances malt purger meta provision resolver server admin socket actions assets cron handling handleing home legal list logo news templating solr sshkeys triplestore pkglib lifetime
+//spellchecker:words sync time github wisski distillery internal component audit auth next panel passkeys policy scopes tokens binder docker exporter logger verifier scheduler health instances malt purger restorer updater meta provision requests resolver server admin socket actions assets cron handling handleing home legal list logo metrics news templating solr sshkeys triplestore pkglib lifetime
 import (
 	"io"
 	"sync"
 	"time"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/audit"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/api"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/next"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/panel"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/passkeys"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/policy"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/scopes"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/auth/tokens"
@@ -19,11 +21,17 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/docker"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/logger"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/scheduler"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/exporter/verifier"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/health"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances/malt"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances/purger"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances/restorer"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/instances/updater"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/meta"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/provision"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/provision/requests"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/resolver"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/admin"
@@ -36,6 +44,7 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/legal"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/list"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/logo"
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/metrics"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/news"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/server/templating"
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
@@ -112,6 +121,15 @@ func (dis *Distillery) Instances() *instances.Instances {
 func (dis *Distillery) Exporter() *exporter.Exporter {
 	return export[*exporter.Exporter](dis)
 }
+func (dis *Distillery) ExporterLogger() *logger.Logger {
+	return export[*logger.Logger](dis)
+}
+func (dis *Distillery) Verifier() *verifier.Verifier {
+	return export[*verifier.Verifier](dis)
+}
+func (dis *Distillery) Scheduler() *scheduler.Scheduler {
+	return export[*scheduler.Scheduler](dis)
+}
 func (dis *Distillery) Provision() *provision.Provision {
 	return export[*provision.Provision](dis)
 }
@@ -138,6 +156,15 @@ func (dis *Distillery) Templating() *templating.Templating {
 func (dis *Distillery) Purger() *purger.Purger {
 	return export[*purger.Purger](dis)
 }
+func (dis *Distillery) Restorer() *restorer.Restorer {
+	return export[*restorer.Restorer](dis)
+}
+func (dis *Distillery) Updater() *updater.Updater {
+	return export[*updater.Updater](dis)
+}
+func (dis *Distillery) Audit() *audit.Audit {
+	return export[*audit.Audit](dis)
+}
 
 //
 // All components
@@ -167,6 +194,8 @@ func (dis *Distillery) allComponents(context *lifetime.Registry[component.Compon
 	lifetime.Place[*panel.UserPanel](context)
 	lifetime.Place[*next.Next](context)
 	lifetime.Place[*tokens.Tokens](context)
+	lifetime.Place[*passkeys.Passkeys](context)
+	lifetime.Place[*audit.Audit](context)
 
 	// scopes
 	lifetime.Place[*scopes.Never](context)
@@ -175,19 +204,34 @@ func (dis *Distillery) allComponents(context *lifetime.Registry[component.Compon
 	lifetime.Place[*scopes.ListInstancesScope](context)
 	lifetime.Place[*scopes.ListNewsScope](context)
 	lifetime.Place[*scopes.ResolverScope](context)
+	lifetime.Place[*scopes.SnapshotScope](context)
+	lifetime.Place[*scopes.RebuildScope](context)
+	lifetime.Place[*scopes.SystemScope](context)
+	lifetime.Place[*scopes.ControlScope](context)
+	lifetime.Place[*scopes.PurgeScope](context)
+	lifetime.Place[*scopes.RestoreScope](context)
 
 	// instances
 	lifetime.Place[*instances.Instances](context)
 	lifetime.Place[*meta.Meta](context)
 	lifetime.Place[*malt.Malt](context)
 	lifetime.Place[*provision.Provision](context)
+	lifetime.Place[*requests.Requests](context)
 
 	// Purger
 	lifetime.Place[*purger.Purger](context)
 
+	// Restorer
+	lifetime.Place[*restorer.Restorer](context)
+
+	// Updater
+	lifetime.Place[*updater.Updater](context)
+
 	// Snapshots
 	lifetime.Place[*exporter.Exporter](context)
 	lifetime.Place[*logger.Logger](context)
+	lifetime.Place[*verifier.Verifier](context)
+	lifetime.Place[*scheduler.Scheduler](context)
 	lifetime.Place[*exporter.Config](context)
 	lifetime.Place[*exporter.Bookkeeping](context)
 	lifetime.Place[*exporter.Filesystem](context)
@@ -212,15 +256,20 @@ func (dis *Distillery) allComponents(context *lifetime.Registry[component.Compon
 
 	lifetime.Place[*assets.Static](context)
 	lifetime.Place[*logo.Logo](context)
+	lifetime.Place[*metrics.Metrics](context)
 	lifetime.Place[*templating.Templating](context)
 
 	// Websockets
 	lifetime.Place[*socket.Sockets](context)
 	lifetime.Place[*actions.Backup](context)
 	lifetime.Place[*actions.Provision](context)
+	lifetime.Place[*actions.ApproveRequest](context)
 	lifetime.Place[*actions.Snapshot](context)
+	lifetime.Place[*actions.SnapshotIncremental](context)
 	lifetime.Place[*actions.Rebuild](context)
+	lifetime.Place[*actions.RebuildCurrent](context)
 	lifetime.Place[*actions.Update](context)
+	lifetime.Place[*actions.CheckUpdates](context)
 	lifetime.Place[*actions.Cron](context)
 	lifetime.Place[*actions.InstallColorboxJS](context)
 	lifetime.Place[*actions.InstallDompurifyJS](context)
@@ -228,11 +277,14 @@ func (dis *Distillery) allComponents(context *lifetime.Registry[component.Compon
 	lifetime.Place[*actions.Start](context)
 	lifetime.Place[*actions.Stop](context)
 	lifetime.Place[*actions.Purge](context)
+	lifetime.Place[*actions.Restore](context)
 	lifetime.Place[*actions.Prune](context)
+	lifetime.Place[*actions.PruneDryRun](context)
 	lifetime.Place[*actions.RebuildTriplestore](context)
 
 	// Cron
 	lifetime.Place[*cron.Cron](context)
+	lifetime.Place[*health.Health](context)
 
 	// API
 	lifetime.Place[*api.API](context)
@@ -0,0 +1,31 @@
+//spellchecker:words models
+package models
+
+//spellchecker:words time
+import (
+	"time"
+)
+
+var _ Model = AuditEntry{}
+
+// AuditEntry represents an administrative action recorded in the audit log.
+type AuditEntry struct {
+	Pk uint `gorm:"column:pk;primaryKey"`
+
+	Time time.Time `gorm:"column:time;not null;index"` // time the action was completed
+
+	Origin string `gorm:"column:origin;not null"`           // how the actor was authenticated: "session", "token", "cli" or "system"
+	User   string `gorm:"column:user;not null;index"`       // distillery user, or system user for the command line
+	Token  string `gorm:"column:token;not null;default:''"` // id of the token used, if any
+
+	Action string `gorm:"column:action;not null;index"`          // name of the action
+	Slug   string `gorm:"column:slug;not null;default:'';index"` // slug of the instance the action targeted, if any
+	Params []byte `gorm:"column:params"`                         // json-encoded parameters of the action
+
+	Success bool   `gorm:"column:success;not null"`
+	Error   string `gorm:"column:error;not null;default:''"` // error message, if the action failed
+}
+
+func (AuditEntry) TableName() string {
+	return "audit"
+}
@@ -20,14 +20,44 @@ type Export struct {
 
 	Path   string `gorm:"column:path;not null"`   // path the export is stored at
 	Packed bool   `gorm:"column:packed;not null"` // was the export packed, or was it staging only?
+
+	// Incremental indicates an incremental snapshot.
+	// Path then points to the index of the snapshot inside the chunk store.
+	Incremental bool `gorm:"column:incremental;not null;default:false"`
+
+	// Target is the name of the storage target the export is stored in.
+	// If the target is remote, Path holds the location within that target.
+	// Entries without a target are stored on the local disk.
+	Target string `gorm:"column:target;not null;default:''"`
+
+	// Encrypted indicates that the archive is encrypted using age.
+	Encrypted bool `gorm:"column:encrypted;not null;default:false"`
+
+	// Digest is the hex-encoded sha256 digest of the archive, or of the index of an incremental snapshot.
+	// Empty for staging only exports, and for exports made before digests were recorded.
+	Digest string `gorm:"column:digest;not null;default:''"`
+
+	// Verified is the time the export was last verified, if ever.
+	// Problem describes the problem found by the last verification, and is empty if none was found.
+	Verified *time.Time `gorm:"column:verified"`
+	Problem  string     `gorm:"column:problem;not null;default:''"`
 }
 
 func (Export) TableName() string {
 	return "snapshot"
 }
 
+// IsLocal checks if the export is stored on the local disk.
+func (e Export) IsLocal() bool {
+	return e.Target == "" || e.Target == "local"
+}
+
 // Exists checks if the given export exists on disk.
+// Exports stored in a remote target are assumed to exist.
 func (e Export) Exists() (bool, error) {
+	if !e.IsLocal() {
+		return true, nil
+	}
 	if e.Path == "" {
 		return false, nil
 	}
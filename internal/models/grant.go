@@ -1,9 +1,18 @@
 //spellchecker:words models
 package models
 
+//spellchecker:words errors slices
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
 var _ Model = Grant{}
 
 // Grant represents an access grant to a specific user.
+//
+//nolint:recvcheck
 type Grant struct {
 	Pk uint `gorm:"column:pk;primaryKey"`
 
@@ -11,9 +20,71 @@ type Grant struct {
 	Slug string `gorm:"column:slug;not null;index:user_slug;index:drupal_slug"` // (distillery) instance slug
 
 	DrupalUsername  string `gorm:"column:drupal_user;not null;index:drupal_slug,unique"` // drupal username
-	DrupalAdminRole bool   `gorm:"column:admin;not null"`                                // drupal admin rights
+	DrupalAdminRole bool   `gorm:"column:admin;not null"`                                // drupal admin rights, kept in sync with Role
+
+	Role Role `gorm:"column:role;not null;default:''"` // role of the user on the instance, empty for grants created before roles existed
 }
 
 func (Grant) TableName() string {
 	return "grant"
 }
+
+// GetRole returns the role of this grant.
+//
+// Grants created before roles existed have no role.
+// Within Drupal, they are treated as [RoleAdmin] or [RoleReviewer], depending on DrupalAdminRole.
+// They do not give access to the distillery, see [Grant.Legacy].
+func (grant Grant) GetRole() Role {
+	if grant.Role.Valid() {
+		return grant.Role
+	}
+	if grant.DrupalAdminRole {
+		return RoleAdmin
+	}
+	return RoleReviewer
+}
+
+// Legacy reports if this grant was created before roles existed, and has not been assigned a role since.
+func (grant Grant) Legacy() bool {
+	return !grant.Role.Valid()
+}
+
+// SetRole sets the role of this grant, and updates DrupalAdminRole accordingly.
+func (grant *Grant) SetRole(role Role) {
+	grant.Role = role
+	grant.DrupalAdminRole = role == RoleAdmin
+}
+
+// Role is the role a user has on a specific instance.
+type Role string
+
+const (
+	RoleReviewer Role = "reviewer" // plain drupal user with read-only access
+	RoleEditor   Role = "editor"   // may edit content of the instance
+	RoleOperator Role = "operator" // may edit content, and operate the instance from the distillery
+	RoleAdmin    Role = "admin"    // drupal administrator, and may operate the instance from the distillery
+)
+
+// Roles lists all roles, from least to most privileged.
+var Roles = []Role{RoleReviewer, RoleEditor, RoleOperator, RoleAdmin}
+
+// Valid checks if role is one of the known roles.
+func (role Role) Valid() bool {
+	return slices.Contains(Roles, role)
+}
+
+// AtLeast checks if role is at least as privileged as other.
+func (role Role) AtLeast(other Role) bool {
+	return slices.Index(Roles, role) >= slices.Index(Roles, other)
+}
+
+var errUnknownRole = errors.New("unknown role")
+
+// ParseRole parses the name of a role.
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if !role.Valid() {
+		return "", fmt.Errorf("%w %q", errUnknownRole, name)
+	}
+	return role, nil
+}
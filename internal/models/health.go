@@ -0,0 +1,15 @@
+//spellchecker:words models
+package models
+
+//spellchecker:words time
+import (
+	"time"
+)
+
+// CheckState records the state of a single health check.
+type CheckState struct {
+	Failing  bool      `json:"failing"`           // check failed when it was last evaluated
+	Since    time.Time `json:"since"`             // time the check entered its current state
+	Message  string    `json:"message,omitempty"` // message of the last failure, if failing
+	Notified time.Time `json:"notified"`          // time a notification about the current state was last sent
+}
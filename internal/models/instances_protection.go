@@ -0,0 +1,84 @@
+//spellchecker:words models
+package models
+
+//spellchecker:words errors strings golang crypto bcrypt
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	errBasicAuthEntry    = errors.New("basic auth entries must be of the form 'user:password'")
+	errBasicAuthUser     = errors.New("basic auth user may only be given once")
+	errNegativeRateLimit = errors.New("rate limits must not be negative")
+)
+
+// GetBasicAuth returns the 'user:bcrypt-hash' entries required to access the instance.
+func (system System) GetBasicAuth() []string {
+	if system.BasicAuth == "" {
+		return nil
+	}
+	return strings.Split(system.BasicAuth, ",")
+}
+
+// HashBasicAuth normalizes the BasicAuth field of system.
+//
+// BasicAuth may contain entries of the form 'user:password' or 'user:bcrypt-hash'.
+// Plain passwords are replaced by their bcrypt hash, existing hashes are kept.
+// This allows passing back a previously hashed configuration unchanged.
+func (system *System) HashBasicAuth() error {
+	var entries []string
+	seen := make(map[string]struct{})
+
+	for _, entry := range strings.Split(system.BasicAuth, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		user, secret, ok := strings.Cut(entry, ":")
+		if !ok || user == "" || secret == "" {
+			return errBasicAuthEntry
+		}
+		if _, ok := seen[user]; ok {
+			return fmt.Errorf("%w: %q", errBasicAuthUser, user)
+		}
+		seen[user] = struct{}{}
+
+		if _, err := bcrypt.Cost([]byte(secret)); err != nil {
+			hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+			if err != nil {
+				return fmt.Errorf("failed to hash password for %q: %w", user, err)
+			}
+			secret = string(hash)
+		}
+
+		entries = append(entries, user+":"+secret)
+	}
+
+	system.BasicAuth = strings.Join(entries, ",")
+	return nil
+}
+
+// Redacted returns a copy of system without the passwords and hashes of BasicAuth.
+// Use it to record a system, e.g. in a log.
+func (system System) Redacted() System {
+	entries := system.GetBasicAuth()
+	for i, entry := range entries {
+		user, _, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		entries[i] = user + ":[redacted]"
+	}
+	system.BasicAuth = strings.Join(entries, ",")
+	return system
+}
+
+// ValidateLimits checks that the rate and in-flight limits of this system are valid.
+func (system System) ValidateLimits() error {
+	if system.RateLimit < 0 || system.RateLimitBurst < 0 || system.InFlightLimit < 0 {
+		return errNegativeRateLimit
+	}
+	return nil
+}
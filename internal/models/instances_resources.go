@@ -0,0 +1,87 @@
+//spellchecker:words models
+package models
+
+//spellchecker:words errors regexp strconv
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Defaults for resource settings of a system.
+// These are used when the corresponding field is empty.
+const (
+	DefaultPHPMemoryLimit  = "4G"
+	DefaultServiceCPULimit = "1.0"
+	DefaultTriplestoreHeap = "8g"
+	DefaultSolrHeap        = "1g"
+)
+
+var (
+	errInvalidCPULimit = errors.New("cpu limit must be a positive number")
+	errInvalidSize     = errors.New("size must be a number followed by an optional unit (k, m or g)")
+)
+
+var regexpSize = regexp.MustCompile(`^[1-9][0-9]*[kKmMgG]?$`)
+
+// ValidateResources checks that the resource settings of this system are valid.
+//
+// CPU limits are given as a positive decimal number of cpus, e.g. "1.5".
+// Memory limits, php memory limits and heap sizes are given as a number followed by an optional unit, e.g. "512m" or "4g".
+func (system System) ValidateResources() error {
+	for _, setting := range [][2]string{
+		{"cpu limit", system.CPULimit},
+		{"service cpu limit", system.ServiceCPULimit},
+	} {
+		name, value := setting[0], setting[1]
+		if value == "" {
+			continue
+		}
+		if cpus, err := strconv.ParseFloat(value, 64); err != nil || cpus <= 0 {
+			return fmt.Errorf("%s %q: %w", name, value, errInvalidCPULimit)
+		}
+	}
+
+	for _, setting := range [][2]string{
+		{"memory limit", system.MemoryLimit},
+		{"php memory limit", system.PHPMemoryLimit},
+		{"service memory limit", system.ServiceMemoryLimit},
+		{"triplestore heap", system.TriplestoreHeap},
+		{"solr heap", system.SolrHeap},
+	} {
+		name, value := setting[0], setting[1]
+		if value != "" && !regexpSize.MatchString(value) {
+			return fmt.Errorf("%s %q: %w", name, value, errInvalidSize)
+		}
+	}
+
+	return nil
+}
+
+// GetPHPMemoryLimit returns the php memory_limit of this system.
+func (system System) GetPHPMemoryLimit() string {
+	return valueOr(system.PHPMemoryLimit, DefaultPHPMemoryLimit)
+}
+
+// GetServiceCPULimit returns the cpus available to each dedicated service of this system.
+func (system System) GetServiceCPULimit() string {
+	return valueOr(system.ServiceCPULimit, DefaultServiceCPULimit)
+}
+
+// GetTriplestoreHeap returns the maximum java heap of the dedicated triplestore.
+func (system System) GetTriplestoreHeap() string {
+	return valueOr(system.TriplestoreHeap, DefaultTriplestoreHeap)
+}
+
+// GetSolrHeap returns the java heap of solr.
+func (system System) GetSolrHeap() string {
+	return valueOr(system.SolrHeap, DefaultSolrHeap)
+}
+
+func valueOr(value, dflt string) string {
+	if value == "" {
+		return dflt
+	}
+	return value
+}
@@ -12,6 +12,7 @@ type System struct {
 	PHP            string `gorm:"column:php;not null"`           // php version to use
 	PHPDevelopment bool   `gorm:"column:opcache_devel;not null"` // php development (sql field name is legacy)
 	IPAllowlist    string `gorm:"column:ip_allowlist;not null"`
+	Aliases        string `gorm:"column:aliases;not null;default:''"` // comma-separated custom domains the instance is also served under
 
 	ContentSecurityPolicy string `gorm:"column:csp;not null"` // content security policy for the system
 
@@ -20,6 +21,21 @@ type System struct {
 	DedicatedSQL         bool `gorm:"column:dedicated_sql;not null;default:false"`         // should we use a dedicated SQL server?
 	DedicatedTriplestore bool `gorm:"column:dedicated_triplestore;not null;default:false"` // should we use a dedicated Triplestore?
 	SolrServer           bool `gorm:"column:solr;not null;default:false"`                  // should we add a solr?
+
+	// Resource limits, see [System.ValidateResources].
+	CPULimit           string `gorm:"column:cpu_limit;not null;default:''"`            // cpus available to the barrel, empty for no limit
+	MemoryLimit        string `gorm:"column:memory_limit;not null;default:''"`         // memory available to the barrel, empty for no limit
+	PHPMemoryLimit     string `gorm:"column:php_memory_limit;not null;default:''"`     // php memory_limit, empty for the default
+	ServiceCPULimit    string `gorm:"column:service_cpu_limit;not null;default:''"`    // cpus available to each dedicated service, empty for the default
+	ServiceMemoryLimit string `gorm:"column:service_memory_limit;not null;default:''"` // memory available to each dedicated service, empty for no limit
+	TriplestoreHeap    string `gorm:"column:triplestore_heap;not null;default:''"`     // maximum java heap of the dedicated triplestore, empty for the default
+	SolrHeap           string `gorm:"column:solr_heap;not null;default:''"`            // java heap of solr, empty for the default
+
+	// Access protection, see [System.HashBasicAuth].
+	BasicAuth      string `gorm:"column:basic_auth;not null;default:''"`      // comma-separated 'user:bcrypt-hash' entries required to access the instance, empty to disable
+	RateLimit      int    `gorm:"column:rate_limit;not null;default:0"`       // average number of requests per second allowed per client, 0 to disable
+	RateLimitBurst int    `gorm:"column:rate_limit_burst;not null;default:0"` // maximum number of requests per client in a burst, 0 to use RateLimit
+	InFlightLimit  int    `gorm:"column:in_flight_limit;not null;default:0"`  // maximum number of simultaneous requests to the instance, 0 to disable
 }
 
 // Called to get the final System info for the given current configuration.
@@ -78,6 +94,23 @@ func (system System) GetIPAllowlist() []string {
 	return strings.Split(system.IPAllowlist, ",")
 }
 
+// GetAliases returns the custom domains the instance is also served under.
+func (system System) GetAliases() []string {
+	if system.Aliases == "" {
+		return nil
+	}
+	return strings.Split(system.Aliases, ",")
+}
+
+// ForClone returns a copy of system to provision a clone of the instance with.
+//
+// Aliases are removed, as the custom domains continue to point to the original instance.
+// BasicAuth is kept, so that the cloned data remains protected in the same way.
+func (system System) ForClone() System {
+	system.Aliases = ""
+	return system
+}
+
 // GetDockerBaseImage returns the docker base image used by the given system.
 func (system System) GetDockerBaseImage() string {
 	version := DefaultPHPVersion
@@ -0,0 +1,31 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+func TestSystem_ForClone(t *testing.T) {
+	t.Parallel()
+
+	system := models.System{
+		PHP:       "8.3",
+		Aliases:   "example.com,www.example.com",
+		BasicAuth: "alice:$2a$10$hash",
+	}
+	clone := system.ForClone()
+
+	if clone.Aliases != "" {
+		t.Errorf("clone.Aliases = %q, want empty", clone.Aliases)
+	}
+	if clone.BasicAuth != system.BasicAuth {
+		t.Errorf("clone.BasicAuth = %q, want %q", clone.BasicAuth, system.BasicAuth)
+	}
+	if clone.PHP != system.PHP {
+		t.Errorf("clone.PHP = %q, want %q", clone.PHP, system.PHP)
+	}
+	if system.Aliases == "" {
+		t.Error("ForClone modified the original system")
+	}
+}
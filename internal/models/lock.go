@@ -1,6 +1,11 @@
 //spellchecker:words models
 package models
 
+//spellchecker:words time
+import (
+	"time"
+)
+
 var _ Model = Lock{}
 
 // Lock represents a lock on WissKI Instances.
@@ -8,6 +13,10 @@ type Lock struct {
 	Pk uint `gorm:"column:pk;primaryKey"`
 
 	Slug string `gorm:"column:slug;not null;unique"` // slug of instance
+
+	Reason   string     `gorm:"column:reason;not null;default:''"` // human-readable reason for the lock
+	Created  *time.Time `gorm:"column:created"`                    // time the lock was acquired
+	Expected *time.Time `gorm:"column:expected"`                   // estimated time the lock will be released, if known
 }
 
 func (Lock) TableName() string {
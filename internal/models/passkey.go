@@ -0,0 +1,58 @@
+//spellchecker:words models
+package models
+
+//spellchecker:words encoding base json time github webauthn
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+var _ Model = Passkey{}
+
+// Passkey represents a WebAuthn credential registered by a distillery user.
+// Passkeys are used as a second factor during login.
+//
+//nolint:recvcheck
+type Passkey struct {
+	Pk uint `gorm:"column:pk;primaryKey"`
+
+	User string `gorm:"column:user;not null"` // (distillery) username
+	Name string `gorm:"column:name;not null"` // name given by the user
+
+	CredentialID string `gorm:"column:credential;not null;unique"` // credential id, base64url encoded
+	Credential   []byte `gorm:"column:data;not null"     json:"-"` // json-encoded webauthn credential
+
+	Created  time.Time  `gorm:"column:created;autoCreateTime"`
+	LastUsed *time.Time `gorm:"column:used"`
+}
+
+func (Passkey) TableName() string {
+	return "passkeys"
+}
+
+// EncodeCredentialID encodes a raw credential id as stored in the CredentialID field.
+func EncodeCredentialID(id []byte) string {
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+// GetCredential returns the webauthn credential stored in this passkey.
+func (passkey *Passkey) GetCredential() (credential webauthn.Credential, err error) {
+	if err := json.Unmarshal(passkey.Credential, &credential); err != nil {
+		return credential, fmt.Errorf("failed to unmarshal credential: %w", err)
+	}
+	return credential, nil
+}
+
+// SetCredential stores credential in this passkey.
+func (passkey *Passkey) SetCredential(credential *webauthn.Credential) (err error) {
+	passkey.CredentialID = EncodeCredentialID(credential.ID)
+	passkey.Credential, err = json.Marshal(credential)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credential: %w", err)
+	}
+	return nil
+}
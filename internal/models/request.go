@@ -0,0 +1,41 @@
+//spellchecker:words models
+package models
+
+//spellchecker:words time
+import (
+	"time"
+)
+
+var _ Model = InstanceRequest{}
+
+// InstanceRequest represents a request by a distillery user for a new instance.
+type InstanceRequest struct {
+	Pk uint `gorm:"column:pk;primaryKey"`
+
+	Created time.Time `gorm:"column:created;not null"` // time the request was made
+	User    string    `gorm:"column:user;not null;index"`
+
+	Slug    string `gorm:"column:slug;not null;index"`            // slug of the requested instance
+	Flavor  string `gorm:"column:flavor;not null;default:''"`     // flavor of the requested instance, empty for the default
+	Purpose string `gorm:"column:purpose;not null;default:''"`    // purpose of the instance, as given by the user
+	Status  string `gorm:"column:status;not null;index"`          // one of [RequestPending], [RequestApproved] or [RequestRejected]
+	Reason  string `gorm:"column:reason;not null;default:''"`     // reason given when rejecting the request
+	Decider string `gorm:"column:decided_by;not null;default:''"` // admin that approved or rejected the request
+
+	Decided *time.Time `gorm:"column:decided"` // time the request was approved or rejected
+}
+
+func (InstanceRequest) TableName() string {
+	return "instance_requests"
+}
+
+const (
+	RequestPending  = "pending"
+	RequestApproved = "approved"
+	RequestRejected = "rejected"
+)
+
+// Pending checks if the request has not been approved or rejected yet.
+func (request InstanceRequest) Pending() bool {
+	return request.Status == RequestPending
+}
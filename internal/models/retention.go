@@ -0,0 +1,127 @@
+//spellchecker:words models
+package models
+
+//spellchecker:words slices strings time
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy is a grandfather-father-son retention policy for exports.
+//
+// For each of the most recent Daily days, Weekly weeks and Monthly months that have exports,
+// the most recent export of that period is kept.
+// The most recent export is always kept.
+//
+// A zero policy is not set; see [RetentionPolicy.IsZero].
+type RetentionPolicy struct {
+	Daily   int `json:"daily"`
+	Weekly  int `json:"weekly"`
+	Monthly int `json:"monthly"`
+}
+
+// IsZero checks if this policy does not keep any periods.
+func (policy RetentionPolicy) IsZero() bool {
+	return policy.Daily <= 0 && policy.Weekly <= 0 && policy.Monthly <= 0
+}
+
+func (policy RetentionPolicy) String() string {
+	if policy.IsZero() {
+		return "unset"
+	}
+	return fmt.Sprintf("%d daily, %d weekly, %d monthly", policy.Daily, policy.Weekly, policy.Monthly)
+}
+
+// RetentionDecision is the result of evaluating a [RetentionPolicy] against a single export.
+type RetentionDecision struct {
+	Export Export
+	Keep   bool
+
+	// Reasons lists the periods the export was kept for.
+	// If the export is not kept, it instead lists why no period kept it.
+	Reasons []string
+}
+
+// retentionPeriod is a single kind of period of a retention policy.
+type retentionPeriod struct {
+	Name   string
+	Unit   string
+	Count  int
+	Bucket func(t time.Time) string
+}
+
+// Evaluate evaluates this policy against the given exports.
+// The returned decisions are sorted from most recent to least recent.
+func (policy RetentionPolicy) Evaluate(exports []Export) []RetentionDecision {
+	decisions := make([]RetentionDecision, len(exports))
+	for i, export := range exports {
+		decisions[i].Export = export
+	}
+	slices.SortStableFunc(decisions, func(a, b RetentionDecision) int {
+		return b.Export.Created.Compare(a.Export.Created)
+	})
+
+	if len(decisions) > 0 {
+		decisions[0].Keep = true
+		decisions[0].Reasons = append(decisions[0].Reasons, "latest")
+	}
+
+	// reasons why each export was not kept by a period
+	pruneReasons := make([][]string, len(decisions))
+
+	for _, period := range []retentionPeriod{
+		{Name: "daily", Unit: "day", Count: policy.Daily, Bucket: func(t time.Time) string { return t.Format("2006-01-02") }},
+		{Name: "weekly", Unit: "week", Count: policy.Weekly, Bucket: func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%d", year, week)
+		}},
+		{Name: "monthly", Unit: "month", Count: policy.Monthly, Bucket: func(t time.Time) string { return t.Format("2006-01") }},
+	} {
+		if period.Count <= 0 {
+			continue
+		}
+
+		// reasons why exports are not kept for this period
+		superseded := fmt.Sprintf("a newer export is kept for its %s", period.Unit)
+		exceeded := fmt.Sprintf("older than the %d most recent %s(s) with exports", period.Count, period.Unit)
+
+		remaining := period.Count
+		last := ""
+		for i := range decisions {
+			bucket := period.Bucket(decisions[i].Export.Created)
+			switch {
+			case bucket == last:
+				pruneReasons[i] = append(pruneReasons[i], superseded)
+			case remaining <= 0:
+				pruneReasons[i] = append(pruneReasons[i], exceeded)
+			default:
+				last = bucket
+				remaining--
+
+				decisions[i].Keep = true
+				decisions[i].Reasons = append(decisions[i].Reasons, period.Name)
+			}
+		}
+	}
+
+	for i := range decisions {
+		if !decisions[i].Keep {
+			decisions[i].Reasons = pruneReasons[i]
+		}
+	}
+
+	return decisions
+}
+
+// Reason returns a human-readable description of the decision.
+func (decision RetentionDecision) Reason() string {
+	if !decision.Keep {
+		if len(decision.Reasons) == 0 {
+			return "prune"
+		}
+		return "prune (" + strings.Join(decision.Reasons, ", ") + ")"
+	}
+	return "keep (" + strings.Join(decision.Reasons, ", ") + ")"
+}
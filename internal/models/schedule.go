@@ -0,0 +1,126 @@
+//spellchecker:words models
+package models
+
+//spellchecker:words strings time
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScheduleFrequency is how often scheduled snapshots are taken.
+type ScheduleFrequency string
+
+const (
+	ScheduleNever  ScheduleFrequency = ""
+	ScheduleDaily  ScheduleFrequency = "daily"
+	ScheduleWeekly ScheduleFrequency = "weekly"
+)
+
+// SnapshotSchedule is a schedule for automatically taking snapshots of an instance.
+//
+// Snapshots are due at Hour (in the local time of the distillery) every day, or on Weekday every week.
+// A zero schedule is not set; see [SnapshotSchedule.IsZero].
+type SnapshotSchedule struct {
+	Frequency ScheduleFrequency `json:"frequency"`
+	Hour      int               `json:"hour"`
+	Weekday   time.Weekday      `json:"weekday"` // only used for weekly schedules
+
+	Parts       []string `json:"parts,omitempty"` // parts to include, empty means all parts
+	Incremental bool     `json:"incremental,omitempty"`
+}
+
+// IsZero checks if this schedule never takes any snapshots.
+func (schedule SnapshotSchedule) IsZero() bool {
+	return schedule.Frequency == ScheduleNever
+}
+
+func (schedule SnapshotSchedule) String() string {
+	var builder strings.Builder
+	switch schedule.Frequency {
+	case ScheduleNever:
+		return "unset"
+	case ScheduleDaily:
+		_, _ = fmt.Fprintf(&builder, "daily at %02d:00", schedule.Hour)
+	case ScheduleWeekly:
+		_, _ = fmt.Fprintf(&builder, "weekly on %s at %02d:00", schedule.Weekday, schedule.Hour)
+	default:
+		_, _ = fmt.Fprintf(&builder, "unknown frequency %q", schedule.Frequency)
+	}
+
+	if schedule.Incremental {
+		builder.WriteString(", incremental")
+	}
+	if len(schedule.Parts) > 0 {
+		builder.WriteString(", parts " + strings.Join(schedule.Parts, ","))
+	}
+	return builder.String()
+}
+
+// Last returns the most recent time at or before now a snapshot was due.
+// The zero schedule returns the zero time.
+func (schedule SnapshotSchedule) Last(now time.Time) time.Time {
+	var period int
+	switch schedule.Frequency {
+	case ScheduleDaily:
+		period = 1
+	case ScheduleWeekly:
+		period = 7
+	default:
+		return time.Time{}
+	}
+
+	last := time.Date(now.Year(), now.Month(), now.Day(), schedule.Hour, 0, 0, 0, now.Location())
+	if last.After(now) {
+		last = last.AddDate(0, 0, -1)
+	}
+	if period == 7 {
+		last = last.AddDate(0, 0, -((int(last.Weekday()) - int(schedule.Weekday) + 7) % 7))
+	}
+	return last
+}
+
+// Next returns the next time after now a snapshot is due.
+// The zero schedule returns the zero time.
+func (schedule SnapshotSchedule) Next(now time.Time) time.Time {
+	last := schedule.Last(now)
+	switch {
+	case last.IsZero():
+		return last
+	case schedule.Frequency == ScheduleWeekly:
+		return last.AddDate(0, 0, 7)
+	default:
+		return last.AddDate(0, 0, 1)
+	}
+}
+
+// Due checks if a snapshot is due at now, when the previous scheduled snapshot was started at last.
+func (schedule SnapshotSchedule) Due(last, now time.Time) bool {
+	if schedule.IsZero() {
+		return false
+	}
+	return last.Before(schedule.Last(now))
+}
+
+// SnapshotScheduleState records the outcome of scheduled snapshots of an instance.
+type SnapshotScheduleState struct {
+	LastRun     time.Time `json:"last_run"`        // time the last scheduled snapshot was started
+	LastSuccess time.Time `json:"last_success"`    // time the last successful scheduled snapshot was started
+	Error       string    `json:"error,omitempty"` // error of the last scheduled snapshot, if any
+}
+
+// Finish records the outcome of the scheduled snapshot started at LastRun.
+// err is the error returned when taking the snapshot, and nil if it succeeded.
+func (state *SnapshotScheduleState) Finish(err error) {
+	state.Error = ""
+	if err != nil {
+		state.Error = err.Error()
+		return
+	}
+	state.LastSuccess = state.LastRun
+}
+
+// Failed checks if the last scheduled snapshot failed.
+func (state SnapshotScheduleState) Failed() bool {
+	return state.Error != ""
+}
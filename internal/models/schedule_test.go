@@ -0,0 +1,46 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+)
+
+var errSnapshot = errors.New("snapshot failed")
+
+func TestSnapshotScheduleState_Finish(t *testing.T) {
+	t.Parallel()
+
+	previous := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+	run := previous.Add(24 * time.Hour)
+
+	t.Run("failed snapshot", func(t *testing.T) {
+		t.Parallel()
+
+		state := models.SnapshotScheduleState{LastRun: run, LastSuccess: previous}
+		state.Finish(errSnapshot)
+
+		if !state.LastSuccess.Equal(previous) {
+			t.Errorf("LastSuccess = %v, want %v", state.LastSuccess, previous)
+		}
+		if !state.Failed() {
+			t.Error("Failed() = false, want true")
+		}
+	})
+
+	t.Run("successful snapshot", func(t *testing.T) {
+		t.Parallel()
+
+		state := models.SnapshotScheduleState{LastRun: run, LastSuccess: previous, Error: "previous failure"}
+		state.Finish(nil)
+
+		if !state.LastSuccess.Equal(run) {
+			t.Errorf("LastSuccess = %v, want %v", state.LastSuccess, run)
+		}
+		if state.Failed() {
+			t.Error("Failed() = true, want false")
+		}
+	})
+}
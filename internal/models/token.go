@@ -1,10 +1,12 @@
 //spellchecker:words models
 package models
 
-//spellchecker:words encoding json
+//spellchecker:words encoding json slices time
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"time"
 )
 
 var _ Model = Token{}
@@ -24,6 +26,12 @@ type Token struct {
 
 	AllScopes bool   `gorm:"column:all;not null"`
 	Scopes    []byte `gorm:"column:scopes;not null"` // comma-seperated list of scopes
+	Instances []byte `gorm:"column:instances"`       // json-encoded list of instance slugs the token is restricted to
+
+	Expires *time.Time `gorm:"column:expires"` // time the token expires, nil if it never expires
+
+	LastUsed *time.Time `gorm:"column:used"` // time the token was last used
+	LastIP   string     `gorm:"column:ip"`   // ip address the token was last used from
 }
 
 func (Token) TableName() string {
@@ -62,3 +70,43 @@ func (token *Token) SetScopes(scopes []string) (err error) {
 	}
 	return nil
 }
+
+// GetInstances returns the slugs of the instances this token is restricted to.
+//
+// If this token is not restricted to specific instances, returns nil.
+func (token *Token) GetInstances() (slugs []string) {
+	if len(token.Instances) == 0 {
+		return nil
+	}
+
+	err := json.Unmarshal(token.Instances, &slugs)
+	if slugs == nil || err != nil {
+		slugs = []string{}
+	}
+	return
+}
+
+// SetInstances restricts this token to the instances with the given slugs.
+// If slugs is nil, the token is not restricted to specific instances.
+func (token *Token) SetInstances(slugs []string) (err error) {
+	if slugs == nil {
+		token.Instances = nil
+		return nil
+	}
+	token.Instances, err = json.Marshal(slugs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal instances: %w", err)
+	}
+	return nil
+}
+
+// PermitsInstance checks if this token may be used to access the instance with the given slug.
+func (token *Token) PermitsInstance(slug string) bool {
+	slugs := token.GetInstances()
+	return slugs == nil || slices.Contains(slugs, slug)
+}
+
+// Expired checks if this token has expired at the given time.
+func (token *Token) Expired(now time.Time) bool {
+	return token.Expires != nil && !now.Before(*token.Expires)
+}
@@ -0,0 +1,32 @@
+//spellchecker:words models
+package models
+
+//spellchecker:words time
+import (
+	"time"
+)
+
+// UpdateOutcome records the outcome of the last guarded update of an instance.
+type UpdateOutcome struct {
+	Started  time.Time `json:"started"`            // time the guarded update was started
+	Snapshot string    `json:"snapshot,omitempty"` // path to the snapshot taken before updating, if any
+	Result   string    `json:"result"`             // one of [UpdateSucceeded], [UpdateSkipped], [UpdateRolledBack] or [UpdateFailed]
+	Error    string    `json:"error,omitempty"`    // error that caused the update to fail, if any
+}
+
+const (
+	UpdateSucceeded  = "succeeded"   // update was applied and the instance passed the smoke check
+	UpdateSkipped    = "skipped"     // no snapshot could be taken, so the update was not applied
+	UpdateRolledBack = "rolled back" // update failed, and the instance was restored from the snapshot
+	UpdateFailed     = "failed"      // update failed, and the instance could not be restored
+)
+
+// IsZero checks if no guarded update has been recorded.
+func (outcome UpdateOutcome) IsZero() bool {
+	return outcome.Started.IsZero()
+}
+
+// Failed checks if the guarded update did not succeed.
+func (outcome UpdateOutcome) Failed() bool {
+	return !outcome.IsZero() && outcome.Result != UpdateSucceeded
+}
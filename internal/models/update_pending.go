@@ -0,0 +1,60 @@
+//spellchecker:words models
+package models
+
+//spellchecker:words time
+import (
+	"time"
+)
+
+// PendingUpdates lists the updates an update of an instance would apply.
+type PendingUpdates struct {
+	Checked    time.Time          `json:"checked"`              // time the pending updates were determined
+	Packages   []PackageUpdate    `json:"packages,omitempty"`   // pending composer package operations
+	Database   []DatabaseUpdate   `json:"database,omitempty"`   // pending drupal database updates
+	Advisories []SecurityAdvisory `json:"advisories,omitempty"` // security advisories affecting installed packages
+}
+
+// IsZero checks if pending updates have never been determined.
+func (pending PendingUpdates) IsZero() bool {
+	return pending.Checked.IsZero()
+}
+
+// Count returns the total number of pending updates.
+func (pending PendingUpdates) Count() int {
+	return len(pending.Packages) + len(pending.Database)
+}
+
+// SecurityCount returns the number of pending package updates that fix a security advisory.
+func (pending PendingUpdates) SecurityCount() (count int) {
+	for _, pkg := range pending.Packages {
+		if pkg.Security {
+			count++
+		}
+	}
+	return count
+}
+
+// PackageUpdate is a pending operation on a single composer package.
+type PackageUpdate struct {
+	Name      string `json:"name"`           // name of the package
+	Operation string `json:"operation"`      // one of "upgrade", "downgrade", "install" or "remove"
+	From      string `json:"from,omitempty"` // currently installed version, if any
+	To        string `json:"to,omitempty"`   // version after updating, if any
+	Security  bool   `json:"security"`       // the installed version is affected by a security advisory
+}
+
+// DatabaseUpdate is a pending drupal database update.
+type DatabaseUpdate struct {
+	Module      string `json:"module"`      // module providing the update
+	ID          string `json:"id"`          // id of the update
+	Type        string `json:"type"`        // type of update, e.g. "hook_update_n" or "post-update"
+	Description string `json:"description"` // human-readable description of the update
+}
+
+// SecurityAdvisory is a security advisory reported by composer audit.
+type SecurityAdvisory struct {
+	Package string `json:"package"`        // affected package
+	Title   string `json:"title"`          // title of the advisory
+	CVE     string `json:"cve,omitempty"`  // cve identifier, if any
+	Link    string `json:"link,omitempty"` // link to the advisory, if any
+}
@@ -15,6 +15,8 @@ type User struct {
 	TOTPEnabled  *bool  `gorm:"column:totpenabled" json:"-"` // is totp enabled for the user
 	TOTPURL      string `gorm:"column:totp"        json:"-"` // the totp of the user
 
+	SSO *string `gorm:"column:sso;size:64;unique" json:"-"` // link to the single sign-on identity of the user, if any
+
 	Enabled *bool `gorm:"enabled;not null"      json:"enabled"`
 	Admin   *bool `gorm:"column:admin;not null" json:"admin"`
 }
@@ -25,7 +25,10 @@ type WissKI struct {
 	// Note that the html in templates may contain dirty html.
 	Requirements []Requirement
 
-	Locked bool // Is this instance currently locked?
+	Locked       bool      // Is this instance currently locked?
+	LockReason   string    // reason the instance is locked, if any
+	LockedSince  time.Time // time the instance was locked, if known
+	LockExpected time.Time // estimated time the lock is released, if known
 
 	// Information about the running instance
 	Running     bool
@@ -33,6 +36,10 @@ type WissKI struct {
 	LastUpdate  time.Time
 	LastCron    time.Time
 
+	// Outcome of the last guarded update, and the updates pending as of the last check
+	GuardedUpdate  models.UpdateOutcome
+	PendingUpdates models.PendingUpdates
+
 	PHPVersion    string // current php version
 	DrupalVersion string // current drupal version
 	Theme         string // current default theme
@@ -43,6 +50,10 @@ type WissKI struct {
 	// List of backups made
 	Snapshots []models.Export
 
+	// Schedule of automatic snapshots, and the outcome of the last scheduled snapshot
+	SnapshotSchedule      models.SnapshotSchedule
+	SnapshotScheduleState models.SnapshotScheduleState
+
 	// List of SSH Keys that have access to this server
 	SSHKeys []string
 
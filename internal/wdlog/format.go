@@ -0,0 +1,25 @@
+//spellchecker:words wdlog
+package wdlog
+
+//spellchecker:words slog
+import (
+	"io"
+	"log/slog"
+)
+
+// Format is the format log messages are written in.
+type Format string
+
+const (
+	FormatText Format = "text" // key=value pairs, see [slog.TextHandler]
+	FormatJSON Format = "json" // one JSON object per line, see [slog.JSONHandler]
+)
+
+// Handler creates a new handler writing messages in this format to out.
+// Unknown formats use [FormatText].
+func (format Format) Handler(out io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	if format == FormatJSON {
+		return slog.NewJSONHandler(out, opts)
+	}
+	return slog.NewTextHandler(out, opts)
+}
@@ -0,0 +1,16 @@
+//spellchecker:words wdlog
+package wdlog
+
+//spellchecker:words crypto encoding
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// NewID returns a new random identifier.
+// It is intended to correlate log messages belonging to the same request, connection or task.
+func NewID() string {
+	var id [8]byte
+	_, _ = rand.Read(id[:]) // never returns an error
+	return hex.EncodeToString(id[:])
+}
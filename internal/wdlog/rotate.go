@@ -0,0 +1,126 @@
+//spellchecker:words wdlog
+package wdlog
+
+//spellchecker:words errors path filepath strconv sync
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// RotatingFile is an [io.WriteCloser] that appends to a file, and rotates it once it exceeds a maximal size.
+//
+// When rotating, the file is renamed to "<path>.1", an existing "<path>.1" to "<path>.2", and so on.
+// At most MaxFiles rotated files are kept.
+type RotatingFile struct {
+	Path     string
+	MaxSize  int64 // maximal size in bytes; 0 disables rotation
+	MaxFiles int   // number of rotated files to keep
+
+	m    sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Write writes p to the file.
+// If p does not fit into the current file, the file is rotated first.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.m.Lock()
+	defer rf.m.Unlock()
+
+	if rf.file == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if rf.MaxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.MaxSize {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("failed to write log file: %w", err)
+	}
+	return n, nil
+}
+
+// Close closes the underlying file.
+func (rf *RotatingFile) Close() error {
+	rf.m.Lock()
+	defer rf.m.Unlock()
+
+	if rf.file == nil {
+		return nil
+	}
+
+	err := rf.file.Close()
+	rf.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	return nil
+}
+
+// open opens the file for appending.
+func (rf *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(rf.Path), 0o750); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	// #nosec G304 -- path is provided by the configuration
+	file, err := os.OpenFile(rf.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	rf.file = file
+	rf.size = info.Size()
+	return nil
+}
+
+// rotate closes the current file, shifts existing rotated files and opens a new file.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	rf.file = nil
+
+	rotated := func(i int) string {
+		return rf.Path + "." + strconv.Itoa(i)
+	}
+
+	if rf.MaxFiles <= 0 {
+		if err := os.Remove(rf.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove log file: %w", err)
+		}
+		return rf.open()
+	}
+
+	if err := os.Remove(rotated(rf.MaxFiles)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove rotated log file: %w", err)
+	}
+	for i := rf.MaxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(rotated(i), rotated(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to rename rotated log file: %w", err)
+		}
+	}
+	if err := os.Rename(rf.Path, rotated(1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to rename log file: %w", err)
+	}
+
+	return rf.open()
+}
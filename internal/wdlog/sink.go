@@ -0,0 +1,303 @@
+//spellchecker:words wdlog
+package wdlog
+
+//spellchecker:words bytes context errors slog strconv sync time
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sinkTimeout is the maximal time connecting to or writing to a sink may take.
+const sinkTimeout = 5 * time.Second
+
+var errUnsupportedSink = errors.New("unsupported sink url, expected 'udp://', 'tcp://' or 'unix://'")
+
+// ParseSink parses the url of a remote log sink into a network and address.
+// Supported urls are "udp://host:port", "tcp://host:port" and "unix:///path/to/socket".
+func ParseSink(sink string) (network, address string, err error) {
+	u, err := url.Parse(sink)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse sink url: %w", err)
+	}
+
+	switch {
+	case (u.Scheme == "udp" || u.Scheme == "tcp") && u.Host != "":
+		return u.Scheme, u.Host, nil
+	case u.Scheme == "unix" && u.Path != "":
+		return "unixgram", u.Path, nil
+	default:
+		return "", "", fmt.Errorf("%w: %q", errUnsupportedSink, sink)
+	}
+}
+
+// sinkQueueSize is the number of messages that may wait to be sent to a sink.
+const sinkQueueSize = 1024
+
+var errSinkTimeout = errors.New("timed out sending remaining messages to log sink")
+
+// sinkConn sends messages to a remote sink.
+//
+// Messages are queued and sent in the background, so that logging never waits for the sink.
+// The connection is established on first use, and re-established after an error.
+type sinkConn struct {
+	network, address string
+	delimiter        byte // appended to messages on stream connections
+
+	m      sync.Mutex // protects closed
+	closed bool
+
+	queue chan []byte
+	done  chan struct{} // closed once the queue has been worked off
+
+	conn     net.Conn // only used by work
+	closeErr error    // set by work before done is closed
+}
+
+func newSinkConn(sink string, delimiter byte) (*sinkConn, error) {
+	network, address, err := ParseSink(sink)
+	if err != nil {
+		return nil, err
+	}
+	sc := &sinkConn{
+		network:   network,
+		address:   address,
+		delimiter: delimiter,
+
+		queue: make(chan []byte, sinkQueueSize),
+		done:  make(chan struct{}),
+	}
+	go sc.work()
+	return sc, nil
+}
+
+// Send queues a single message to be sent to the sink.
+// When the queue is full, for instance because the sink is unreachable, the message is dropped.
+func (sc *sinkConn) Send(message []byte) {
+	sc.m.Lock()
+	defer sc.m.Unlock()
+
+	if sc.closed {
+		return
+	}
+
+	select {
+	case sc.queue <- bytes.Clone(message):
+	default:
+	}
+}
+
+// work sends queued messages until the queue is closed.
+func (sc *sinkConn) work() {
+	defer close(sc.done)
+
+	for message := range sc.queue {
+		// there is nowhere to report the error to, as the sink is part of the logger.
+		_ = sc.send(message)
+	}
+
+	if sc.conn == nil {
+		return
+	}
+	if err := sc.conn.Close(); err != nil {
+		sc.closeErr = fmt.Errorf("failed to close log sink: %w", err)
+	}
+	sc.conn = nil
+}
+
+// send sends a single message to the sink.
+func (sc *sinkConn) send(message []byte) error {
+	if sc.conn == nil {
+		conn, err := net.DialTimeout(sc.network, sc.address, sinkTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to connect to log sink: %w", err)
+		}
+		sc.conn = conn
+	}
+
+	if sc.network == "tcp" {
+		message = append(message, sc.delimiter)
+	}
+
+	_ = sc.conn.SetWriteDeadline(time.Now().Add(sinkTimeout))
+	if _, err := sc.conn.Write(message); err != nil {
+		_ = sc.conn.Close()
+		sc.conn = nil
+		return fmt.Errorf("failed to write to log sink: %w", err)
+	}
+	return nil
+}
+
+// Close stops accepting new messages, waits for queued messages to be sent and closes the connection to the sink.
+// Waiting gives up after [sinkTimeout], dropping messages that have not been sent yet.
+func (sc *sinkConn) Close() error {
+	sc.m.Lock()
+	if !sc.closed {
+		sc.closed = true
+		close(sc.queue)
+	}
+	sc.m.Unlock()
+
+	timer := time.NewTimer(sinkTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-sc.done:
+		return sc.closeErr
+	case <-timer.C:
+		return errSinkTimeout
+	}
+}
+
+// severity returns the syslog severity corresponding to level.
+func severity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3 // error
+	case level >= slog.LevelWarn:
+		return 4 // warning
+	case level >= slog.LevelInfo:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
+
+// recordHandler is a handler that encodes records using an inner handler, and then passes them to send.
+// The inner handler must write exactly once per record into buffer.
+type recordHandler struct {
+	inner  slog.Handler
+	state  *recordState
+	format func(record slog.Record, encoded []byte) []byte
+}
+
+// recordState is shared between a recordHandler and handlers derived from it.
+type recordState struct {
+	m      sync.Mutex
+	buffer bytes.Buffer
+	sink   *sinkConn
+}
+
+func (rh *recordHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return rh.inner.Enabled(ctx, level)
+}
+
+func (rh *recordHandler) Handle(ctx context.Context, record slog.Record) error {
+	rh.state.m.Lock()
+	defer rh.state.m.Unlock()
+
+	rh.state.buffer.Reset()
+	if err := rh.inner.Handle(ctx, record); err != nil {
+		return fmt.Errorf("failed to encode log record: %w", err)
+	}
+	rh.state.sink.Send(rh.format(record, bytes.TrimRight(rh.state.buffer.Bytes(), "\n")))
+	return nil
+}
+
+func (rh *recordHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &recordHandler{inner: rh.inner.WithAttrs(attrs), state: rh.state, format: rh.format}
+}
+
+func (rh *recordHandler) WithGroup(name string) slog.Handler {
+	return &recordHandler{inner: rh.inner.WithGroup(name), state: rh.state, format: rh.format}
+}
+
+// hostname returns the hostname to report to sinks.
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "-"
+	}
+	return name
+}
+
+// newSyslogHandler creates a handler that sends messages to a syslog server in the format described by RFC 5424.
+// The message itself is encoded as key=value pairs.
+func newSyslogHandler(sink string, appName string, level slog.Leveler) (*recordHandler, *sinkConn, error) {
+	conn, err := newSinkConn(sink, '\n')
+	if err != nil {
+		return nil, nil, err
+	}
+
+	state := &recordState{sink: conn}
+	host := hostname()
+	pid := strconv.Itoa(os.Getpid())
+
+	return &recordHandler{
+		inner: slog.NewTextHandler(&state.buffer, &slog.HandlerOptions{
+			Level: level,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				// the syslog header already contains the time and severity
+				if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}),
+		state: state,
+		format: func(record slog.Record, encoded []byte) []byte {
+			const facility = 1 // user-level messages
+			header := fmt.Sprintf(
+				"<%d>1 %s %s %s %s - - ",
+				facility*8+severity(record.Level),
+				record.Time.Format(time.RFC3339Nano),
+				host, appName, pid,
+			)
+			return append([]byte(header), encoded...)
+		},
+	}, conn, nil
+}
+
+// newGELFHandler creates a handler that sends messages to a Graylog server using the GELF format.
+// Attributes are sent as additional fields, an attribute "id" is sent as "_id_".
+//
+// Messages are sent uncompressed and unchunked.
+// Over udp, messages exceeding a single datagram are dropped by the network, so tcp should be preferred.
+func newGELFHandler(sink string, level slog.Leveler) (*recordHandler, *sinkConn, error) {
+	conn, err := newSinkConn(sink, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	state := &recordState{sink: conn}
+	prefix := []byte(fmt.Sprintf(`{"version":"1.1","host":%q,`, hostname()))
+
+	return &recordHandler{
+		inner: slog.NewJSONHandler(&state.buffer, &slog.HandlerOptions{
+			Level: level,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) > 0 {
+					return a
+				}
+				switch a.Key {
+				case slog.TimeKey:
+					t := a.Value.Time()
+					return slog.Float64("timestamp", float64(t.UnixNano())/float64(time.Second))
+				case slog.LevelKey:
+					lvl, _ := a.Value.Any().(slog.Level)
+					return slog.Int("level", severity(lvl))
+				case slog.MessageKey:
+					return slog.String("short_message", a.Value.String())
+				case "id":
+					// graylog reserves the "_id" field, and drops messages containing it
+					return slog.Attr{Key: "_id_", Value: a.Value}
+				default:
+					return slog.Attr{Key: "_" + a.Key, Value: a.Value}
+				}
+			},
+		}),
+		state: state,
+		format: func(record slog.Record, encoded []byte) []byte {
+			// insert the version and host fields into the encoded object
+			return append(append([]byte(nil), prefix...), bytes.TrimPrefix(encoded, []byte("{"))...)
+		},
+	}, conn, nil
+}
@@ -3,11 +3,13 @@
 //spellchecker:words wdlog
 package wdlog
 
-//spellchecker:words context slog
+//spellchecker:words context slog pkglib errorsx
 import (
 	"context"
 	"io"
 	"log/slog"
+
+	"go.tkw01536.de/pkglib/errorsx"
 )
 
 // New creates a new logger logging into the given output.
@@ -17,6 +19,70 @@ func New(out io.Writer, level slog.Level) *slog.Logger {
 	}))
 }
 
+// Options describe where and how [Open] logs.
+type Options struct {
+	Level  slog.Level
+	Format Format // format of messages written to the output and File
+
+	// File is an optional file to additionally log into.
+	// See [RotatingFile] for MaxSize and MaxFiles.
+	File     string
+	MaxSize  int64
+	MaxFiles int
+
+	// Syslog and GELF are optional urls of remote sinks to additionally send messages to.
+	// See [ParseSink] for supported urls.
+	Syslog string
+	GELF   string
+
+	// AppName identifies the program in syslog messages.
+	AppName string
+}
+
+// Open creates a new logger logging into the given output, as well as all sinks configured in options.
+// The returned cleanup function closes all sinks, and should be called once the logger is no longer needed.
+func Open(out io.Writer, options Options) (logger *slog.Logger, cleanup func() error, err error) {
+	handlerOptions := &slog.HandlerOptions{Level: options.Level}
+
+	handlers := []slog.Handler{options.Format.Handler(out, handlerOptions)}
+	var closers []io.Closer
+	cleanup = func() (err error) {
+		for _, closer := range closers {
+			err = errorsx.Combine(err, closer.Close())
+		}
+		return err
+	}
+
+	if options.File != "" {
+		file := &RotatingFile{Path: options.File, MaxSize: options.MaxSize, MaxFiles: options.MaxFiles}
+		handlers = append(handlers, options.Format.Handler(file, handlerOptions))
+		closers = append(closers, file)
+	}
+
+	if options.Syslog != "" {
+		handler, conn, err := newSyslogHandler(options.Syslog, options.AppName, options.Level)
+		if err != nil {
+			return nil, nil, errorsx.Combine(err, cleanup())
+		}
+		handlers = append(handlers, handler)
+		closers = append(closers, conn)
+	}
+
+	if options.GELF != "" {
+		handler, conn, err := newGELFHandler(options.GELF, options.Level)
+		if err != nil {
+			return nil, nil, errorsx.Combine(err, cleanup())
+		}
+		handlers = append(handlers, handler)
+		closers = append(closers, conn)
+	}
+
+	if len(handlers) == 1 {
+		return slog.New(handlers[0]), cleanup, nil
+	}
+	return slog.New(slog.NewMultiHandler(handlers...)), cleanup, nil
+}
+
 // context.
 type loggerKeyTyp struct{}
 
@@ -19,6 +19,10 @@ type Barrel struct {
 	}
 }
 
+var (
+	_ locker.Runner = (*Barrel)(nil)
+)
+
 const (
 	BaseDirectory     = "/var/www/data"
 	ComposerDirectory = BaseDirectory + "/project"
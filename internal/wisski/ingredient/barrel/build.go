@@ -17,11 +17,14 @@ import (
 	"go.tkw01536.de/pkglib/errorsx"
 )
 
+// rebuildEstimate is the estimated time a rebuild takes.
+const rebuildEstimate = 10 * time.Minute
+
 // Build builds or rebuilds the barrel connected to this instance.
 //
 // It also logs the current time into the metadata belonging to this instance.
 func (barrel *Barrel) Build(ctx context.Context, progress io.Writer, start bool) (e error) {
-	if err := barrel.dependencies.Locker.TryLock(ctx); err != nil {
+	if err := barrel.dependencies.Locker.TryLock(ctx, "The instance is being rebuilt.", rebuildEstimate); err != nil {
 		return fmt.Errorf("unable to lock instance: %w", err)
 	}
 	defer barrel.dependencies.Locker.Unlock(ctx)
@@ -0,0 +1,201 @@
+//spellchecker:words composer
+package composer
+
+//spellchecker:words bytes context encoding json errors regexp slices strings time github wisski distillery internal component meta models status ingredient barrel mstore logging pkglib stream
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/meta"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/status"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/barrel"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/mstore"
+	"github.com/FAU-CDI/wisski-distillery/pkg/logging"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+var pendingUpdates = mstore.For[models.PendingUpdates]("pending_updates")
+
+// CheckUpdates determines the updates that [Composer.Update] would apply, without applying them.
+// The result is stored, and can be retrieved later using [Composer.PendingUpdates].
+//
+// Package updates are determined using 'composer update --dry-run', and flagged using 'composer audit'.
+// Database updates are determined using 'drush updatedb:status'.
+func (composer *Composer) CheckUpdates(ctx context.Context, progress io.Writer) (pending models.PendingUpdates, err error) {
+	pending.Checked = time.Now()
+
+	if err := logging.LogOperation(func() (err error) {
+		pending.Packages, err = composer.dryRun(ctx, progress)
+		return err
+	}, progress, "Determining pending package updates"); err != nil {
+		return pending, fmt.Errorf("failed to determine pending package updates: %w", err)
+	}
+
+	if err := logging.LogOperation(func() (err error) {
+		pending.Advisories, err = composer.audit(ctx, progress)
+		return err
+	}, progress, "Auditing installed packages"); err != nil {
+		return pending, fmt.Errorf("failed to audit packages: %w", err)
+	}
+	for i, pkg := range pending.Packages {
+		pending.Packages[i].Security = slices.ContainsFunc(pending.Advisories, func(advisory models.SecurityAdvisory) bool {
+			return advisory.Package == pkg.Name
+		})
+	}
+
+	if err := logging.LogOperation(func() (err error) {
+		pending.Database, err = composer.dependencies.Drush.PendingUpdates(ctx, progress)
+		return err
+	}, progress, "Determining pending database updates"); err != nil {
+		return pending, fmt.Errorf("failed to determine pending database updates: %w", err)
+	}
+
+	if err := pendingUpdates.Set(ctx, composer.dependencies.MStore, pending); err != nil {
+		return pending, fmt.Errorf("failed to store pending updates: %w", err)
+	}
+	return pending, nil
+}
+
+// PendingUpdates returns the pending updates determined by the last call to [Composer.CheckUpdates].
+// When updates have never been checked, returns the zero value.
+func (composer *Composer) PendingUpdates(ctx context.Context) (models.PendingUpdates, error) {
+	pending, err := pendingUpdates.Get(ctx, composer.dependencies.MStore)
+	if errors.Is(err, meta.ErrMetadatumNotSet) {
+		return models.PendingUpdates{}, nil
+	}
+	if err != nil {
+		return models.PendingUpdates{}, fmt.Errorf("failed to get pending updates: %w", err)
+	}
+	return pending, nil
+}
+
+// operationLine matches a package operation printed by composer.
+var operationLine = regexp.MustCompile(`^\s*- (Upgrading|Downgrading|Updating|Installing|Removing) (\S+) \(([^)]*)\)`)
+
+var composerOperations = map[string]string{
+	"Upgrading":   "upgrade",
+	"Updating":    "upgrade",
+	"Downgrading": "downgrade",
+	"Installing":  "install",
+	"Removing":    "remove",
+}
+
+// dryRun runs 'composer update --dry-run' and parses the package operations it would perform.
+func (composer *Composer) dryRun(ctx context.Context, progress io.Writer) ([]models.PackageUpdate, error) {
+	var output bytes.Buffer
+	if err := composer.dependencies.Barrel.BashScript(
+		ctx,
+		stream.NonInteractive(io.MultiWriter(&output, progress)),
+		"composer", "--no-interaction", "--no-ansi", "--working-dir", barrel.ComposerDirectory, "update", "--dry-run",
+	); err != nil {
+		return nil, fmt.Errorf("composer command returned error: %w", err)
+	}
+
+	var updates []models.PackageUpdate
+	for _, line := range strings.Split(output.String(), "\n") {
+		match := operationLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		// composer may list the same operation for both the lock file and the installed packages
+		if slices.ContainsFunc(updates, func(update models.PackageUpdate) bool { return update.Name == match[2] }) {
+			continue
+		}
+
+		update := models.PackageUpdate{
+			Name:      match[2],
+			Operation: composerOperations[match[1]],
+		}
+		from, to, ok := strings.Cut(match[3], " => ")
+		switch {
+		case ok:
+			update.From, update.To = from, to
+		case update.Operation == "install":
+			update.To = from
+		default:
+			update.From = from
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
+
+// audit runs 'composer audit' and returns the reported security advisories.
+func (composer *Composer) audit(ctx context.Context, progress io.Writer) ([]models.SecurityAdvisory, error) {
+	var stdout bytes.Buffer
+
+	// composer audit exits non-zero when advisories are found, so only fail when the output can not be decoded.
+	execErr := composer.dependencies.Barrel.BashScript(
+		ctx,
+		stream.NewIOStream(&stdout, progress, nil),
+		"composer", "--no-interaction", "--no-ansi", "--working-dir", barrel.ComposerDirectory, "audit", "--locked", "--format=json",
+	)
+
+	var report struct {
+		Advisories json.RawMessage `json:"advisories"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		if execErr != nil {
+			return nil, fmt.Errorf("composer command returned error: %w", execErr)
+		}
+		return nil, fmt.Errorf("failed to decode composer output: %w", err)
+	}
+
+	// without advisories, composer encodes an empty list instead of an object
+	var byPackage map[string][]struct {
+		Title string `json:"title"`
+		CVE   string `json:"cve"`
+		Link  string `json:"link"`
+	}
+	if trimmed := bytes.TrimSpace(report.Advisories); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &byPackage); err != nil {
+			return nil, fmt.Errorf("failed to decode composer advisories: %w", err)
+		}
+	}
+
+	var advisories []models.SecurityAdvisory
+	for pkg, list := range byPackage {
+		for _, advisory := range list {
+			advisories = append(advisories, models.SecurityAdvisory{
+				Package: pkg,
+				Title:   advisory.Title,
+				CVE:     advisory.CVE,
+				Link:    advisory.Link,
+			})
+		}
+	}
+	slices.SortFunc(advisories, func(a, b models.SecurityAdvisory) int {
+		if c := strings.Compare(a.Package, b.Package); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Title, b.Title)
+	})
+	return advisories, nil
+}
+
+type PendingUpdatesFetcher struct {
+	ingredient.Base
+	dependencies struct {
+		Composer *Composer
+	}
+}
+
+var (
+	_ ingredient.WissKIFetcher = (*PendingUpdatesFetcher)(nil)
+)
+
+func (puf *PendingUpdatesFetcher) Fetch(flags ingredient.FetcherFlags, info *status.WissKI) (err error) {
+	info.PendingUpdates, err = puf.dependencies.Composer.PendingUpdates(flags.Context)
+	return
+}
@@ -1,7 +1,7 @@
 //spellchecker:words composer
 package composer
 
-//spellchecker:words context errors time github wisski distillery internal component meta status ingredient mstore logging
+//spellchecker:words context errors time github wisski distillery internal component meta models status ingredient mstore logging
 import (
 	"context"
 	"errors"
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/meta"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
 	"github.com/FAU-CDI/wisski-distillery/internal/status"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/mstore"
@@ -74,6 +75,34 @@ func (drush *Composer) setLastUpdate(ctx context.Context) error {
 	if err := lastUpdate.Set(ctx, drush.dependencies.MStore, time.Now().Unix()); err != nil {
 		return fmt.Errorf("failed to set last update: %w", err)
 	}
+
+	// the previously pending updates have now been applied
+	if err := pendingUpdates.Delete(ctx, drush.dependencies.MStore); err != nil {
+		return fmt.Errorf("failed to clear pending updates: %w", err)
+	}
+	return nil
+}
+
+var guardedUpdate = mstore.For[models.UpdateOutcome]("guarded_update")
+
+// GuardedUpdate returns the outcome of the last guarded update.
+// When no guarded update has been made, returns the zero outcome.
+func (composer *Composer) GuardedUpdate(ctx context.Context) (models.UpdateOutcome, error) {
+	outcome, err := guardedUpdate.Get(ctx, composer.dependencies.MStore)
+	if errors.Is(err, meta.ErrMetadatumNotSet) {
+		return models.UpdateOutcome{}, nil
+	}
+	if err != nil {
+		return models.UpdateOutcome{}, fmt.Errorf("failed to get guarded update outcome: %w", err)
+	}
+	return outcome, nil
+}
+
+// SetGuardedUpdate records the outcome of a guarded update.
+func (composer *Composer) SetGuardedUpdate(ctx context.Context, outcome models.UpdateOutcome) error {
+	if err := guardedUpdate.Set(ctx, composer.dependencies.MStore, outcome); err != nil {
+		return fmt.Errorf("failed to set guarded update outcome: %w", err)
+	}
 	return nil
 }
 
@@ -90,5 +119,9 @@ var (
 
 func (lbr *LastUpdateFetcher) Fetch(flags ingredient.FetcherFlags, info *status.WissKI) (err error) {
 	info.LastUpdate, err = lbr.dependencies.Composer.LastUpdate(flags.Context)
+	if err != nil {
+		return
+	}
+	info.GuardedUpdate, err = lbr.dependencies.Composer.GuardedUpdate(flags.Context)
 	return
 }
@@ -0,0 +1,68 @@
+//spellchecker:words drush
+package drush
+
+//spellchecker:words bytes context encoding json slices strings github wisski distillery internal models pkglib stream
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+// Output is like [Drush.Exec], but returns the standard output of the command.
+// Standard error is written to progress.
+func (drush *Drush) Output(ctx context.Context, progress io.Writer, command ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	script := append([]string{"drush"}, command...)
+	if err := drush.dependencies.Barrel.BashScript(ctx, stream.NewIOStream(&stdout, progress, nil), script...); err != nil {
+		return nil, fmt.Errorf("drush returned error: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// PendingUpdates returns the pending database updates, as reported by 'drush updatedb:status'.
+func (drush *Drush) PendingUpdates(ctx context.Context, progress io.Writer) ([]models.DatabaseUpdate, error) {
+	out, err := drush.Output(ctx, progress, "updatedb:status", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	// when there are no pending updates, drush does not print anything
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	var status map[string]struct {
+		Module      string `json:"module"`
+		UpdateID    any    `json:"update_id"`
+		Type        string `json:"type"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(out, &status); err != nil {
+		return nil, fmt.Errorf("failed to decode drush output: %w", err)
+	}
+
+	updates := make([]models.DatabaseUpdate, 0, len(status))
+	for _, update := range status {
+		updates = append(updates, models.DatabaseUpdate{
+			Module:      update.Module,
+			ID:          fmt.Sprint(update.UpdateID),
+			Type:        update.Type,
+			Description: strings.TrimSpace(update.Description),
+		})
+	}
+	slices.SortFunc(updates, func(a, b models.DatabaseUpdate) int {
+		if c := strings.Compare(a.Module, b.Module); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return updates, nil
+}
@@ -0,0 +1,56 @@
+//spellchecker:words barrel
+package barrel
+
+//spellchecker:words context github wisski distillery internal ingredient pkglib errorsx
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient"
+	"go.tkw01536.de/pkglib/errorsx"
+)
+
+// Stop stops the barrel.
+// While stopped, the maintenance page is served for all domains of the instance.
+func (barrel *Barrel) Stop(ctx context.Context, progress io.Writer) (e error) {
+	stack, err := barrel.OpenStack()
+	if err != nil {
+		return fmt.Errorf("failed to open stack: %w", err)
+	}
+	defer errorsx.Close(stack, &e, "stack")
+
+	liquid := ingredient.GetLiquid(barrel)
+	if err := liquid.Web.SetMaintenance(liquid.Slug, liquid.HostRule()); err != nil {
+		return fmt.Errorf("failed to enable maintenance page: %w", err)
+	}
+
+	if err := stack.Down(ctx, progress); err != nil {
+		return fmt.Errorf("failed to shutdown barrel: %w", err)
+	}
+	return nil
+}
+
+// Start starts (or restarts) the barrel.
+// Unless the instance is locked, the maintenance page is no longer served.
+func (barrel *Barrel) Start(ctx context.Context, progress io.Writer) (e error) {
+	stack, err := barrel.OpenStack()
+	if err != nil {
+		return fmt.Errorf("failed to open stack: %w", err)
+	}
+	defer errorsx.Close(stack, &e, "stack")
+
+	if err := stack.Start(ctx, progress); err != nil {
+		return fmt.Errorf("failed to start barrel: %w", err)
+	}
+
+	if barrel.dependencies.Locker.Locked(ctx) {
+		return nil
+	}
+
+	liquid := ingredient.GetLiquid(barrel)
+	if err := liquid.Web.ClearMaintenance(liquid.Slug); err != nil {
+		return fmt.Errorf("failed to disable maintenance page: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+//spellchecker:words barrel
+package barrel
+
+//spellchecker:words strconv github wisski distillery internal ingredient pkglib yamlx gopkg yaml
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient"
+	"go.tkw01536.de/pkglib/yamlx"
+	"gopkg.in/yaml.v3"
+)
+
+// applyResources applies the resource settings of the system to the compose file in root.
+// Services that have been removed from root must not be enabled in the system.
+func (barrel *Barrel) applyResources(root *yaml.Node) error {
+	liquid := ingredient.GetLiquid(barrel)
+
+	services := make(map[string]any)
+
+	// the barrel itself
+	{
+		service := map[string]any{
+			"build": map[string]any{
+				"args": map[string]string{
+					"PHP_MEMORY_LIMIT": liquid.GetPHPMemoryLimit(),
+				},
+			},
+		}
+		if liquid.CPULimit != "" {
+			cpus, err := strconv.ParseFloat(liquid.CPULimit, 64)
+			if err != nil {
+				return fmt.Errorf("invalid cpu limit: %w", err)
+			}
+			service["cpus"] = cpus
+		}
+		if liquid.MemoryLimit != "" {
+			service["mem_limit"] = liquid.MemoryLimit
+		}
+		services["barrel"] = service
+	}
+
+	// the dedicated services
+	serviceCPUs, err := strconv.ParseFloat(liquid.GetServiceCPULimit(), 64)
+	if err != nil {
+		return fmt.Errorf("invalid service cpu limit: %w", err)
+	}
+	dedicated := func(environment map[string]string) map[string]any {
+		service := map[string]any{
+			"cpus": serviceCPUs,
+		}
+		if liquid.ServiceMemoryLimit != "" {
+			service["mem_limit"] = liquid.ServiceMemoryLimit
+		}
+		if len(environment) > 0 {
+			service["environment"] = environment
+		}
+		return service
+	}
+
+	if liquid.DedicatedSQL {
+		services["dedicatedsql"] = dedicated(nil)
+	}
+	if liquid.DedicatedTriplestore {
+		var environment map[string]string
+		if liquid.TriplestoreHeap != "" {
+			environment = map[string]string{"JAVA_OPTS": "-Xmx" + liquid.GetTriplestoreHeap()}
+		}
+		services["dedicatedtriplestore"] = dedicated(environment)
+	}
+	if liquid.SolrServer {
+		services["solr"] = dedicated(map[string]string{"SOLR_HEAP": liquid.GetSolrHeap()})
+	}
+
+	overlay, err := yamlx.Marshal(map[string]any{"services": services})
+	if err != nil {
+		return fmt.Errorf("failed to marshal resource settings: %w", err)
+	}
+	if err := yamlx.Transplant(root, overlay, true); err != nil {
+		return fmt.Errorf("failed to apply resource settings: %w", err)
+	}
+	return nil
+}
@@ -1,11 +1,12 @@
 //spellchecker:words barrel
 package barrel
 
-//spellchecker:words embed path filepath github wisski distillery internal component ingredient dockerx
+//spellchecker:words embed path filepath strconv github wisski distillery internal component ingredient dockerx
 import (
 	"embed"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component"
@@ -126,6 +127,11 @@ func (barrel *Barrel) OpenStack() (component.StackWithResources, error) {
 				}
 			}
 
+			// apply cpu, memory and heap settings
+			if err := barrel.applyResources(root); err != nil {
+				return nil, err
+			}
+
 			// if we don't have any services, remove the default network
 			if len(dependencyMap) == 0 {
 				if err := yamlx.Remove(root, "networks", "default"); err != nil {
@@ -193,6 +199,29 @@ func (barrel *Barrel) makeMidlewares() []map[string]string {
 		})
 	}
 
+	if liquid.RateLimit > 0 {
+		burst := max(liquid.RateLimitBurst, liquid.RateLimit)
+		middleswares = append(middleswares, map[string]string{
+			"ratelimit.average": strconv.Itoa(liquid.RateLimit),
+			"ratelimit.burst":   strconv.Itoa(burst),
+		})
+	}
+
+	if liquid.InFlightLimit > 0 {
+		middleswares = append(middleswares, map[string]string{
+			"inflightreq.amount": strconv.Itoa(liquid.InFlightLimit),
+		})
+	}
+
+	if users := liquid.GetBasicAuth(); len(users) != 0 {
+		middleswares = append(middleswares, map[string]string{
+			// bcrypt hashes contain '$', which needs to be escaped for docker compose.
+			"basicauth.users":        strings.ReplaceAll(strings.Join(users, ","), "$", "$$"),
+			"basicauth.realm":        liquid.Slug,
+			"basicauth.removeheader": "true",
+		})
+	}
+
 	return middleswares
 }
 
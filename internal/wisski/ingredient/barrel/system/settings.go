@@ -18,8 +18,8 @@ func (smanager *SystemManager) BuildSettings(ctx context.Context, progress io.Wr
 		return fmt.Errorf("failed to log message: %w", err)
 	}
 	{
-		if err := smanager.dependencies.Settings.SetTrustedDomain(ctx, nil, ingredient.GetLiquid(smanager).Domain()); err != nil {
-			return fmt.Errorf("failed to set trusted domain: %w", err)
+		if err := smanager.dependencies.Settings.SetTrustedDomains(ctx, nil, ingredient.GetLiquid(smanager).Domains()...); err != nil {
+			return fmt.Errorf("failed to set trusted domains: %w", err)
 		}
 	}
 
@@ -44,6 +44,16 @@ func (smanager *SystemManager) ApplyInitial(ctx context.Context, progress io.Wri
 
 // start inidicates if the image should be started afterwards.
 func (smanager *SystemManager) apply(ctx context.Context, progress io.Writer, system models.System, start bool, initial bool) error {
+	if err := system.ValidateResources(); err != nil {
+		return fmt.Errorf("invalid resource settings: %w", err)
+	}
+	if err := system.ValidateLimits(); err != nil {
+		return fmt.Errorf("invalid limits: %w", err)
+	}
+	if err := system.HashBasicAuth(); err != nil {
+		return fmt.Errorf("invalid basic auth: %w", err)
+	}
+
 	// Apply the current configuration.
 	config := ingredient.GetLiquid(smanager).System
 	if initial {
@@ -20,6 +20,17 @@ import (
 // Locker provides facitilites for locking this WissKI instance.
 type Locker struct {
 	ingredient.Base
+	dependencies struct {
+		Runners []Runner
+	}
+}
+
+// Runner reports if the barrel of this WissKI is running.
+// It is implemented by the barrel, which itself depends on the Locker.
+type Runner interface {
+	ingredient.Ingredient
+
+	Running(ctx context.Context) (bool, error)
 }
 
 // Timeout for an unlock operation when the context is already cancelled.
@@ -33,7 +44,10 @@ var (
 
 // TryLock attemps to lock this WissKI and returns nil if it succeeded.
 // If the instance is already locked, returns an error wrapping [ErrLocked].
-func (lock *Locker) TryLock(ctx context.Context) error {
+//
+// Reason is a human-readable reason for the lock, and estimate the expected duration of the lock (or 0 if unknown).
+// Both are shown on the maintenance page, which is served for all domains of the instance while it is locked.
+func (lock *Locker) TryLock(ctx context.Context, reason string, estimate time.Duration) error {
 	liquid := ingredient.GetLiquid(lock)
 
 	table, err := sql.OpenInterface[models.Lock](ctx, liquid.SQL, liquid.LockTable)
@@ -41,8 +55,19 @@ func (lock *Locker) TryLock(ctx context.Context) error {
 		return fmt.Errorf("failed to open interface: %w", err)
 	}
 
+	now := time.Now()
+	record := models.Lock{
+		Slug:    liquid.Slug,
+		Reason:  reason,
+		Created: &now,
+	}
+	if estimate > 0 {
+		expected := now.Add(estimate)
+		record.Expected = &expected
+	}
+
 	{
-		err := table.Create(ctx, &models.Lock{Slug: liquid.Slug})
+		err := table.Create(ctx, &record)
 		if isDuplicateKeyEntryError(err) {
 			return fmt.Errorf("%w: %w", ErrLocked, err)
 		}
@@ -51,6 +76,16 @@ func (lock *Locker) TryLock(ctx context.Context) error {
 		}
 	}
 
+	// serve the maintenance page while we are locked.
+	// this is not critical, so only log a failure.
+	if err := liquid.Web.SetMaintenance(liquid.Slug, liquid.HostRule()); err != nil {
+		wdlog.Of(ctx).Error(
+			"failed to enable maintenance page",
+			"error", err,
+			"slug", liquid.Slug,
+		)
+	}
+
 	return nil
 }
 
@@ -77,6 +112,8 @@ func isDuplicateKeyEntryError(err error) bool {
 // TryUnlock attempts to unlock this WissKI and returns nil if it succeeded.
 // As a special case to avoid deadlocks, an unlock is also attempted when ctx is already cancelled.
 // In such a case, the timeout for the unlock is [UnlockAnywaysTimeout].
+//
+// The maintenance page is no longer served, unless the barrel is stopped.
 func (lock *Locker) TryUnlock(ctx context.Context) error {
 	ctx, cancel := contextx.Anyways(ctx, UnlockAnywaysTimeout)
 	defer cancel()
@@ -91,6 +128,17 @@ func (lock *Locker) TryUnlock(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("unable to delete from table: %w", err)
 	}
+
+	// keep serving the maintenance page while the barrel is stopped, like starting the barrel does
+	running, err := lock.running(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if instance is running: %w", err)
+	}
+	if running {
+		if err := liquid.Web.ClearMaintenance(liquid.Slug); err != nil {
+			return fmt.Errorf("failed to disable maintenance page: %w", err)
+		}
+	}
 	if count == 0 {
 		return ErrNotLocked
 	}
@@ -98,6 +146,20 @@ func (lock *Locker) TryUnlock(ctx context.Context) error {
 	return nil
 }
 
+// running checks if the barrel of this WissKI is running.
+func (lock *Locker) running(ctx context.Context) (bool, error) {
+	for _, runner := range lock.dependencies.Runners {
+		running, err := runner.Running(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to check running: %w", err)
+		}
+		if !running {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // Unlock unlocks this WissKI, ignoring any error.
 func (lock *Locker) Unlock(ctx context.Context) {
 	err := lock.TryUnlock(ctx)
@@ -1,9 +1,10 @@
 //spellchecker:words locker
 package locker
 
-//spellchecker:words context github wisski distillery internal component models status wdlog ingredient liquid
+//spellchecker:words context errors github wisski distillery internal component models status wdlog ingredient liquid gorm
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/sql"
@@ -12,6 +13,7 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/wdlog"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/liquid"
+	"gorm.io/gorm"
 )
 
 // Locked checks if this WissKI is currently locked.
@@ -48,6 +50,41 @@ var (
 )
 
 func (locker *Locker) Fetch(flags ingredient.FetcherFlags, info *status.WissKI) (err error) {
-	info.Locked = locker.Locked(flags.Context)
+	record, ok := locker.Lock(flags.Context)
+	info.Locked = ok
+	if !ok {
+		return
+	}
+
+	info.LockReason = record.Reason
+	if record.Created != nil {
+		info.LockedSince = *record.Created
+	}
+	if record.Expected != nil {
+		info.LockExpected = *record.Expected
+	}
 	return
 }
+
+// Lock returns the current lock of this WissKI, if any.
+// If an error occurs, the instance is considered not locked.
+func (lock *Locker) Lock(ctx context.Context) (record models.Lock, ok bool) {
+	liquid := ingredient.GetLiquid(lock)
+
+	table, err := sql.OpenInterface[models.Lock](ctx, liquid.SQL, liquid.LockTable)
+	if err == nil {
+		record, err = table.Where("slug = ?", liquid.Slug).First(ctx)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return record, false
+	}
+	if err != nil {
+		wdlog.Of(ctx).Error(
+			"failed to fetch lock, returning not locked",
+			"slug", liquid.Slug,
+			"error", err,
+		)
+		return record, false
+	}
+	return record, true
+}
@@ -45,12 +45,12 @@ var (
 	errFailedToSetDefaultDBConnection  = errors.New("failed to set default database connection")
 )
 
-// SetTrustedDomain configures the trusted domain setting for the given instance.
+// SetTrustedDomains configures the trusted domains setting for the given instance.
 // Note that this removes any installed distillery settings.
-func (settings *Settings) SetTrustedDomain(ctx context.Context, server *phpx.Server, domain string) error {
+func (settings *Settings) SetTrustedDomains(ctx context.Context, server *phpx.Server, domains ...string) error {
 	var ok bool
 
-	err := settings.dependencies.PHP.ExecScript(ctx, server, &ok, settingsPHP, "set_trusted_domain", domain)
+	err := settings.dependencies.PHP.ExecScript(ctx, server, &ok, settingsPHP, "set_trusted_domains", domains)
 	if err == nil && !ok {
 		err = errFailedToSetTrustedDomain
 	}
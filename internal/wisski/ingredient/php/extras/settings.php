@@ -117,9 +117,9 @@ function do_set_setting(array $settings): bool {
     return chmod($filename, $old) && $ok;
 }
 
-/** Sets the trusted host to the specified domain */
-function set_trusted_domain(string $domain): bool {
-    return set_setting("trusted_host_patterns", [preg_quote($domain)]);
+/** Sets the trusted hosts to the specified domains */
+function set_trusted_domains(array $domains): bool {
+    return set_setting("trusted_host_patterns", array_map(fn($domain) => preg_quote($domain), $domains));
 }
 
 /** Sets up including a settings.php file from the given path */
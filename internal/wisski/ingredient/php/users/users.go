@@ -1,14 +1,16 @@
 //spellchecker:words users
 package users
 
-//spellchecker:words context embed errors github wisski distillery internal phpx status ingredient
+//spellchecker:words context embed errors slices github wisski distillery internal models phpx status ingredient
 import (
 	"context"
 	_ "embed"
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
 	"github.com/FAU-CDI/wisski-distillery/internal/phpx"
 	"github.com/FAU-CDI/wisski-distillery/internal/status"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient"
@@ -42,7 +44,6 @@ func (u *Users) Login(ctx context.Context, server *phpx.Server, username string)
 	return u.LoginWithOpt(ctx, server, username, LoginOptions{
 		Destination:     "/",
 		CreateIfMissing: false,
-		GrantAdminRole:  false,
 	})
 }
 
@@ -50,7 +51,44 @@ type LoginOptions struct {
 	Destination     string
 	Implicit        bool
 	CreateIfMissing bool
-	GrantAdminRole  bool
+
+	// Role is the role of the user on the instance.
+	// When CreateIfMissing is set, the user is given the corresponding drupal roles,
+	// and any other managed drupal roles are removed, see [ManagedDrupalRoles].
+	Role models.Role
+}
+
+// drupalRoles maps roles on an instance to the drupal roles granted to the user.
+// Roles that do not exist in a specific drupal installation are skipped.
+var drupalRoles = map[models.Role][]string{
+	models.RoleReviewer: {},
+	models.RoleEditor:   {"content_editor"},
+	models.RoleOperator: {"content_editor"},
+	models.RoleAdmin:    {"administrator"},
+}
+
+// DrupalRoles returns the drupal roles granted to users with the given role.
+func DrupalRoles(role models.Role) []string {
+	roles, ok := drupalRoles[role]
+	if !ok {
+		return []string{}
+	}
+	return roles
+}
+
+// ManagedDrupalRoles returns all drupal roles that are granted to users by any role.
+// These are kept in sync with the role of a user on the instance.
+func ManagedDrupalRoles() []string {
+	var managed []string
+	for _, roles := range drupalRoles {
+		for _, role := range roles {
+			if !slices.Contains(managed, role) {
+				managed = append(managed, role)
+			}
+		}
+	}
+	slices.Sort(managed)
+	return managed
 }
 
 // LoginOrCreate generates a login link for the user with the given username and options.
@@ -58,7 +96,7 @@ func (u *Users) LoginWithOpt(ctx context.Context, server *phpx.Server, username
 	// generate a (relative) link
 	var path string
 	if !opts.Implicit {
-		err = u.dependencies.PHP.ExecScript(ctx, server, &path, usersPHP, "get_login_link", username, opts.Destination, opts.CreateIfMissing, opts.GrantAdminRole)
+		err = u.dependencies.PHP.ExecScript(ctx, server, &path, usersPHP, "get_login_link", username, opts.Destination, opts.CreateIfMissing, DrupalRoles(opts.Role), ManagedDrupalRoles())
 	} else {
 		err = u.dependencies.PHP.ExecScript(ctx, server, &path, usersPHP, "get_root_login_link", opts.Destination)
 	}
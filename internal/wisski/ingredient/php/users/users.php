@@ -1,6 +1,7 @@
 <?php
 
 use Drupal\Core\Url;
+use Drupal\user\Entity\Role;
 use Drupal\user\Entity\User;
 
 /** lists all the users */
@@ -35,15 +36,22 @@ function check_password_hash($password, $hash): bool {
     return \Drupal::service('password')->check($password, $hash);
 }
 
-function get_login_link(string $name, string $destination = "", bool $update_user = FALSE, bool $grant_admin = FALSE): string {
+function get_login_link(string $name, string $destination = "", bool $update_user = FALSE, array $roles = [], array $managed = []): string {
     $account = user_load_by_name($name);
     if (!$account) {
         if (!$update_user) return "";
         $account = create_new_disabled_user($name);
     }
 
-    if ($update_user && $grant_admin) {
-        $account->addRole('administrator');
+    if ($update_user) {
+        foreach ($roles as $role) {
+            if (!Role::load($role)) continue;
+            $account->addRole($role);
+        }
+        foreach ($managed as $role) {
+            if (in_array($role, $roles) || !$account->hasRole($role)) continue;
+            $account->removeRole($role);
+        }
     }
 
     if ($update_user) {
@@ -0,0 +1,108 @@
+//spellchecker:words schedule
+package schedule
+
+//spellchecker:words context errors time github wisski distillery internal component meta models status ingredient mstore
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/meta"
+	"github.com/FAU-CDI/wisski-distillery/internal/models"
+	"github.com/FAU-CDI/wisski-distillery/internal/status"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/mstore"
+)
+
+// Schedule stores the snapshot schedule of this WissKI, and the outcome of scheduled snapshots.
+type Schedule struct {
+	ingredient.Base
+	dependencies struct {
+		MStore *mstore.MStore
+	}
+}
+
+var (
+	snapshotSchedule      = mstore.For[models.SnapshotSchedule]("snapshot_schedule")
+	snapshotScheduleState = mstore.For[models.SnapshotScheduleState]("snapshot_schedule_state")
+)
+
+// Get returns the snapshot schedule of this WissKI.
+// When no schedule is set, returns the zero schedule.
+func (schedule *Schedule) Get(ctx context.Context) (models.SnapshotSchedule, error) {
+	value, err := snapshotSchedule.Get(ctx, schedule.dependencies.MStore)
+	if errors.Is(err, meta.ErrMetadatumNotSet) {
+		return models.SnapshotSchedule{}, nil
+	}
+	if err != nil {
+		return models.SnapshotSchedule{}, fmt.Errorf("failed to get snapshot schedule: %w", err)
+	}
+	return value, nil
+}
+
+// Set sets the snapshot schedule of this WissKI.
+// Setting the zero schedule removes the schedule.
+//
+// When no scheduled snapshot has been taken yet, the first one is taken at the next due time, and not immediately.
+func (schedule *Schedule) Set(ctx context.Context, value models.SnapshotSchedule) error {
+	if value.IsZero() {
+		if err := snapshotSchedule.Delete(ctx, schedule.dependencies.MStore); err != nil {
+			return fmt.Errorf("failed to remove snapshot schedule: %w", err)
+		}
+		return nil
+	}
+
+	state, err := schedule.State(ctx)
+	if err != nil {
+		return err
+	}
+	if state.LastRun.IsZero() {
+		state.LastRun = time.Now()
+		if err := schedule.SetState(ctx, state); err != nil {
+			return err
+		}
+	}
+
+	if err := snapshotSchedule.Set(ctx, schedule.dependencies.MStore, value); err != nil {
+		return fmt.Errorf("failed to set snapshot schedule: %w", err)
+	}
+	return nil
+}
+
+// State returns the outcome of scheduled snapshots of this WissKI.
+func (schedule *Schedule) State(ctx context.Context) (models.SnapshotScheduleState, error) {
+	state, err := snapshotScheduleState.Get(ctx, schedule.dependencies.MStore)
+	if errors.Is(err, meta.ErrMetadatumNotSet) {
+		return models.SnapshotScheduleState{}, nil
+	}
+	if err != nil {
+		return models.SnapshotScheduleState{}, fmt.Errorf("failed to get snapshot schedule state: %w", err)
+	}
+	return state, nil
+}
+
+// SetState records the outcome of a scheduled snapshot of this WissKI.
+func (schedule *Schedule) SetState(ctx context.Context, state models.SnapshotScheduleState) error {
+	if err := snapshotScheduleState.Set(ctx, schedule.dependencies.MStore, state); err != nil {
+		return fmt.Errorf("failed to set snapshot schedule state: %w", err)
+	}
+	return nil
+}
+
+type Fetcher struct {
+	ingredient.Base
+	dependencies struct {
+		Schedule *Schedule
+	}
+}
+
+var (
+	_ ingredient.WissKIFetcher = (*Fetcher)(nil)
+)
+
+func (f *Fetcher) Fetch(flags ingredient.FetcherFlags, info *status.WissKI) (err error) {
+	info.SnapshotSchedule, _ = f.dependencies.Schedule.Get(flags.Context)
+	info.SnapshotScheduleState, _ = f.dependencies.Schedule.State(flags.Context)
+	return
+}
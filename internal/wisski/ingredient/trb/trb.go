@@ -1,6 +1,6 @@
 package trb
 
-//spellchecker:words compress gzip context errors github wisski distillery internal ingredient barrel extras logging pkglib errorsx
+//spellchecker:words compress gzip context errors github wisski distillery internal component triplestore client ingredient barrel extras logging pkglib errorsx
 import (
 	"compress/gzip"
 	"context"
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/FAU-CDI/wisski-distillery/internal/dis/component/triplestore/client"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/barrel"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/php/extras"
@@ -109,7 +110,7 @@ func (trb *TRB) RebuildTriplestore(ctx context.Context, progress io.Writer) (siz
 }
 
 func (trb *TRB) makeBackup(ctx context.Context) (path string, e error) {
-	file, err := os.CreateTemp("", "*.nq.gz")
+	file, err := os.CreateTemp("", "*"+client.NQuads.Extension+".gz")
 	if err != nil {
 		return "", fmt.Errorf("failed to create temporary file: %w", err)
 	}
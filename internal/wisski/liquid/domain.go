@@ -18,9 +18,15 @@ func (liquid *Liquid) Hostname() string {
 	return liquid.Domain() + ".wisski"
 }
 
+// Domains returns the full domain name of this WissKI, followed by any custom domains.
+func (liquid *Liquid) Domains() []string {
+	return append([]string{liquid.Domain()}, liquid.GetAliases()...)
+}
+
 // HostRule returns a host rule for this wisski.
+// It includes all custom domains of the WissKI.
 func (liquid *Liquid) HostRule() string {
-	return config.MakeHostRule(liquid.Domain())
+	return config.MakeHostRule(liquid.Domains()...)
 }
 
 // URL returns the public URL of this instance.
@@ -3,7 +3,7 @@
 //spellchecker:words wisski
 package wisski
 
-//spellchecker:words sync github wisski distillery internal ingredient barrel composer drush manager system bookkeeping info locker mstore extras users reserve liquid pkglib lifetime
+//spellchecker:words sync github wisski distillery internal ingredient barrel composer drush manager system bookkeeping info locker mstore extras users reserve schedule liquid pkglib lifetime
 import (
 	"sync"
 
@@ -22,6 +22,7 @@ import (
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/php/extras"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/php/users"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/reserve"
+	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/schedule"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/ingredient/trb"
 	"github.com/FAU-CDI/wisski-distillery/internal/wisski/liquid"
 	"go.tkw01536.de/pkglib/lifetime"
@@ -117,6 +118,10 @@ func (wisski *WissKI) Pathbuilder() *extras.Pathbuilder {
 	return export[*extras.Pathbuilder](wisski)
 }
 
+func (wisski *WissKI) Schedule() *schedule.Schedule {
+	return export[*schedule.Schedule](wisski)
+}
+
 func (wisski *WissKI) Info() *info.Info {
 	return export[*info.Info](wisski)
 }
@@ -185,8 +190,10 @@ func (wisski *WissKI) allIngredients(context *lifetime.Registry[ingredient.Ingre
 	lifetime.Place[*barrel.LastRebuildFetcher](context)
 	lifetime.Place[*barrel.RunningFetcher](context)
 	lifetime.Place[*composer.LastUpdateFetcher](context)
+	lifetime.Place[*composer.PendingUpdatesFetcher](context)
 	lifetime.Place[*drush.LastCronFetcher](context)
 	lifetime.Place[*info.SnapshotsFetcher](context)
+	lifetime.Place[*schedule.Fetcher](context)
 
 	// stacks
 	lifetime.Place[*barrel.Barrel](context)
@@ -197,6 +204,7 @@ func (wisski *WissKI) allIngredients(context *lifetime.Registry[ingredient.Ingre
 	lifetime.Place[*drush.Drush](context)
 
 	lifetime.Place[*reserve.Reserve](context)
+	lifetime.Place[*schedule.Schedule](context)
 
 	lifetime.Place[*ssh.SSH](context)
 	lifetime.Place[*trb.TRB](context)
@@ -29,8 +29,16 @@ func Package(dst, src string, onCopy func(rel string, src string)) (count int64,
 	}
 	defer errorsx.Close(archive, &e, "archive file")
 
+	return Write(archive, src, onCopy)
+}
+
+// Write packages the source directory into a 'tar.gz' stream written to dst.
+// The stream is complete once Write returns; dst itself is not closed.
+//
+// onCopy, when not nil, is called for each file being copied into the archive.
+func Write(dst io.Writer, src string, onCopy func(rel string, src string)) (count int64, e error) {
 	// create a gzip writer
-	zipHandle := gzip.NewWriter(archive)
+	zipHandle := gzip.NewWriter(dst)
 	defer errorsx.Close(zipHandle, &e, "zip handle")
 
 	// create a tar writer
@@ -61,10 +69,17 @@ func Package(dst, src string, onCopy func(rel string, src string)) (count int64,
 			return fmt.Errorf("failed to get file info for %q: %w", path, err)
 		}
 
-		// FIXME: How do we handle
+		// symlinks need to record their target
+		var link string
+		if entry.Type()&fs.ModeSymlink != 0 {
+			link, err = os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read link %q: %w", path, err)
+			}
+		}
 
 		// create a file info header!
-		tInfo, err := tar.FileInfoHeader(info, relpath)
+		tInfo, err := tar.FileInfoHeader(info, link)
 		if err != nil {
 			return fmt.Errorf("failed to create info header for %q: %w", path, err)
 		}
@@ -0,0 +1,112 @@
+//spellchecker:words targz
+package targz
+
+//spellchecker:words archive compress gzip path filepath pkglib errorsx
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"go.tkw01536.de/pkglib/errorsx"
+)
+
+var (
+	errUnsafePath       = errors.New("archive contains unsafe path")
+	errUnsupportedEntry = errors.New("archive contains unsupported entry")
+)
+
+// Unpack reads a 'tar.gz' stream from src and unpacks it into the existing directory dst.
+// The archive is processed in a streaming fashion, and never held in memory as a whole.
+//
+// Entries are confined to dst; paths escaping it (via '..', absolute paths or symlinks) result in an error.
+// Only directories, regular files and symlinks are supported.
+//
+// onCopy, when not nil, is called for each entry being unpacked.
+func Unpack(dst string, src io.Reader, onCopy func(rel string, dst string)) (count int64, e error) {
+	// open the destination as a root, to prevent escaping it
+	root, err := os.OpenRoot(dst)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open destination: %w", err)
+	}
+	defer errorsx.Close(root, &e, "destination root")
+
+	// create a gzip reader
+	zipHandle, err := gzip.NewReader(src)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer errorsx.Close(zipHandle, &e, "zip handle")
+
+	// and read the individual entries
+	tarHandle := tar.NewReader(zipHandle)
+	for {
+		header, err := tarHandle.Next()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, fmt.Errorf("failed to read tar header: %w", err)
+		}
+
+		// clean up the name, and make sure it is local
+		name := path.Clean(header.Name)
+		if name == "." {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return count, fmt.Errorf("%w: %q", errUnsafePath, header.Name)
+		}
+		relpath := filepath.FromSlash(name)
+
+		if onCopy != nil {
+			onCopy(relpath, filepath.Join(dst, relpath))
+		}
+
+		// make sure the parent exists
+		if parent := filepath.Dir(relpath); parent != "." {
+			if err := root.MkdirAll(parent, os.ModePerm); err != nil {
+				return count, fmt.Errorf("failed to create directory %q: %w", parent, err)
+			}
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err := root.Mkdir(relpath, header.FileInfo().Mode().Perm())
+			if err != nil && !errors.Is(err, os.ErrExist) {
+				return count, fmt.Errorf("failed to create directory %q: %w", relpath, err)
+			}
+		case tar.TypeSymlink:
+			if err := root.Symlink(header.Linkname, relpath); err != nil {
+				return count, fmt.Errorf("failed to create symlink %q: %w", relpath, err)
+			}
+		case tar.TypeReg:
+			ccount, err := unpackFile(root, relpath, header.FileInfo().Mode().Perm(), tarHandle)
+			count += ccount
+			if err != nil {
+				return count, err
+			}
+		default:
+			return count, fmt.Errorf("%w: %q has type %q", errUnsupportedEntry, header.Name, header.Typeflag)
+		}
+	}
+}
+
+// unpackFile copies the content of src into a new file called name within root.
+func unpackFile(root *os.Root, name string, perm os.FileMode, src io.Reader) (count int64, e error) {
+	file, err := root.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file %q: %w", name, err)
+	}
+	defer errorsx.Close(file, &e, "file")
+
+	count, err = io.Copy(file, src) // #nosec G110 -- archives are produced by the distillery
+	if err != nil {
+		return count, fmt.Errorf("failed to unpack %q: %w", name, err)
+	}
+	return count, nil
+}